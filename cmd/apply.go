@@ -0,0 +1,60 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/spf13/cobra"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Bring every resource described by a stack file to its desired state",
+	Long: `Reconcile the resources described by a YAML or JSON stack file.
+Missing resources are created and existing resources are updated when they
+differ from the stack file, so applying the same file twice is a no-op.
+Nothing is modified when the stack needs changes which are blocked in its
+plan, such as replacing a resource or deleting an instance. For example:
+
+rds_provider apply -f samples/stack.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		stack, err := provider.LoadStack(file)
+		if err != nil {
+			fail(usageErrorf("%v", err))
+		}
+
+		svc := newService()
+		results, err := provider.ReconcileStack(svc, stack, stackOptions())
+		for _, r := range results {
+			if len(r.Retries) > 0 {
+				fmt.Printf("%s %s: %s (%d retries)\n", r.Kind, r.Id, r.Action, len(r.Retries))
+				continue
+			}
+			fmt.Printf("%s %s: %s\n", r.Kind, r.Id, r.Action)
+		}
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	addStackFlags(applyCmd)
+}
@@ -0,0 +1,308 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/wait"
+	"github.com/spf13/cobra"
+)
+
+// clusterCmd represents the cluster command
+var clusterCmd = &cobra.Command{
+	Use:   "cluster",
+	Short: "Create, inspect, update and delete RDS clusters",
+	Long: `Manage a single RDS cluster described by a YAML or JSON file with the
+same fields as the cluster section of a stack file. For example:
+
+rds_provider cluster create --wait -f cluster.yaml
+rds_provider cluster get my-cluster`,
+}
+
+var clusterCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create the cluster described by a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		input := cluster.NewDBClusterInput{}
+		readResource(&input)
+
+		svc := newService()
+		if waitForStatus {
+			c, err := cluster.CreateDBClusterAndWait(svc, input, waitOptions())
+			if err != nil {
+				fail(err)
+			}
+			printResource(c)
+			return
+		}
+
+		c, err := cluster.CreateDBCluster(svc, input)
+		if err != nil {
+			fail(err)
+		}
+		printResource(c)
+	},
+}
+
+var clusterGetCmd = &cobra.Command{
+	Use:   "get [cluster-id]",
+	Short: "Show a cluster",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		clusterId := resourceId(args, clusterIdFromFile)
+
+		c, err := cluster.FindDBCluster(newService(), clusterId)
+		if err != nil {
+			fail(err)
+		}
+		printResource(c)
+	},
+}
+
+var clusterUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update an existing cluster to match a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		input := cluster.NewDBClusterInput{}
+		readResource(&input)
+
+		svc := newService()
+		_, err := cluster.FindDBCluster(svc, input.ClusterId)
+		if err != nil {
+			fail(err)
+		}
+
+		result, err := provider.ReconcileCluster(svc, input)
+		if err != nil {
+			fail(err)
+		}
+
+		if waitForStatus && result.Action != provider.ActionNone {
+			err = cluster.WaitUntilDBClusterModified(svc, input.ClusterId, waitOptions())
+			if err != nil {
+				fail(err)
+			}
+		}
+
+		c, err := cluster.FindDBCluster(svc, input.ClusterId)
+		if err != nil {
+			fail(err)
+		}
+		printResource(c)
+	},
+}
+
+var clusterDeleteCmd = &cobra.Command{
+	Use:   "delete [cluster-id]",
+	Short: "Delete a cluster",
+	Long: `Delete a cluster. The cluster must have no instances left and must not
+have deletion protection enabled, or match a --protect-name or --protect-tag
+pattern or one of the protected patterns of the config file. The deletion is
+confirmed interactively unless --yes is given. A final snapshot is taken
+unless --skip-final-snapshot and --force are given. For example:
+
+rds_provider cluster delete my-cluster --final-snapshot-id my-cluster-final
+rds_provider cluster delete my-cluster --skip-final-snapshot --force --yes`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		clusterId := resourceId(args, clusterIdFromFile)
+
+		deleteOpts := clusterDeleteOptions()
+		g := newGuard(false)
+
+		svc := newService()
+		_, err := g.CheckCluster(svc, clusterId, nil)
+		if err != nil {
+			fail(err)
+		}
+		confirmDelete(g, rds_errors.KindCluster, clusterId)
+
+		if waitForStatus {
+			err = cluster.DeleteDBClusterAndWait(svc, clusterId, deleteOpts, waitOptions())
+		} else {
+			err = cluster.DeleteDBCluster(svc, clusterId, deleteOpts)
+		}
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+var (
+	cascade      bool
+	deleteGroups bool
+)
+
+var clusterDestroyCmd = &cobra.Command{
+	Use:   "destroy [cluster-id]",
+	Short: "Delete a cluster along with its instances",
+	Long: `Delete a cluster together with its instances. With --cascade the readers
+are deleted one at a time, then the writer, then the cluster, waiting for each
+to be gone before moving on. Without --cascade a cluster which has instances is
+refused. With --delete-groups the subnet group, cluster parameter group and
+instance parameter groups used by the cluster are deleted afterwards, unless
+they are default groups or another cluster or instance still uses them.
+
+Resources are checked for deletion protection and protected patterns before
+anything is deleted, and the deletion is confirmed interactively unless --yes
+is given. A final snapshot of the cluster is taken unless
+--skip-final-snapshot and --force are given. For example:
+
+rds_provider cluster destroy my-cluster --cascade --delete-groups`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		clusterId := resourceId(args, clusterIdFromFile)
+		g := newGuard(cascade)
+
+		err := provider.DestroyCluster(newService(), clusterId, provider.DestroyOptions{
+			Cascade:                cascade,
+			DeleteGroups:           deleteGroups,
+			ClusterDeleteOptions:   clusterDeleteOptions(),
+			RetainAutomatedBackups: retainAutomatedBackups,
+			WaitOptions:            waitOptions(),
+			Guard:                  &g,
+		})
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+var (
+	restoreSnapshotId      string
+	restoreSourceClusterId string
+	restoreTime            string
+)
+
+var clusterRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a cluster from a snapshot or to a point in time",
+	Long: `Restore the cluster described by a stack file from a cluster snapshot, or
+from another cluster as it was at a point in time, then create the instances of
+the stack in it. The subnet group, security groups and parameter groups of the
+stack are used, and must already exist. The master user, storage encryption and
+engine come from the snapshot or source cluster. For example:
+
+rds_provider cluster restore --wait -f stack.yaml --from-snapshot my-cluster-20190101-120000
+rds_provider cluster restore --wait -f stack.yaml --from-cluster my-cluster --restore-time 2019-01-01T12:00:00Z`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if file == "" {
+			fail(usageErrorf("--file is required"))
+		}
+
+		stack, err := provider.LoadStack(file)
+		if err != nil {
+			fail(usageErrorf("%v", err))
+		}
+
+		source := provider.RestoreSource{
+			SnapshotId:      restoreSnapshotId,
+			SourceClusterId: restoreSourceClusterId,
+		}
+		if restoreTime != "" && restoreTime != "latest" {
+			source.RestoreTime, err = time.Parse(time.RFC3339, restoreTime)
+			if err != nil {
+				fail(usageErrorf("invalid --restore-time %q, expected RFC 3339, e.g. 2019-01-01T12:00:00Z", restoreTime))
+			}
+		}
+		if err := source.Validate(); err != nil {
+			fail(usageErrorf("%v", err))
+		}
+
+		c, err := provider.RestoreCluster(newService(), source, stack, stackOptions())
+		if err != nil {
+			fail(err)
+		}
+		printResource(c)
+	},
+}
+
+var clusterListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List clusters",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		clusters, err := cluster.ListDBClusters(newService(), cluster.ListRequest{
+			Engine:     listEngine,
+			ClusterIds: listClusterIds,
+			Tags:       listSelector(),
+		})
+		if err != nil {
+			fail(err)
+		}
+		printResource(clusters)
+	},
+}
+
+func clusterIdFromFile() string {
+	input := cluster.NewDBClusterInput{}
+	readResource(&input)
+	return input.ClusterId
+}
+
+func init() {
+	rootCmd.AddCommand(clusterCmd)
+	clusterCmd.AddCommand(clusterCreateCmd)
+	clusterCmd.AddCommand(clusterGetCmd)
+	clusterCmd.AddCommand(clusterUpdateCmd)
+	clusterCmd.AddCommand(clusterDeleteCmd)
+	clusterCmd.AddCommand(clusterDestroyCmd)
+	clusterCmd.AddCommand(clusterRestoreCmd)
+	clusterCmd.AddCommand(clusterListCmd)
+
+	for _, c := range []*cobra.Command{
+		clusterCreateCmd, clusterGetCmd, clusterUpdateCmd, clusterDeleteCmd, clusterDestroyCmd,
+	} {
+		addFileFlag(c)
+	}
+
+	for _, c := range []*cobra.Command{clusterCreateCmd, clusterUpdateCmd, clusterDeleteCmd} {
+		addWaitFlags(c)
+	}
+
+	addDeleteFlags(clusterDeleteCmd)
+	addGuardFlags(clusterDeleteCmd)
+
+	clusterDestroyCmd.Flags().BoolVar(&cascade, "cascade", false, "delete the instances of the cluster first")
+	clusterDestroyCmd.Flags().BoolVar(
+		&deleteGroups, "delete-groups", false,
+		"delete the subnet group and parameter groups used by the cluster once nothing else uses them",
+	)
+	clusterDestroyCmd.Flags().DurationVar(
+		&waitTimeout, "timeout", wait.DefaultTimeout, "maximum time to wait for each resource to be deleted",
+	)
+	addDeleteFlags(clusterDestroyCmd)
+	addGuardFlags(clusterDestroyCmd)
+
+	addStackFlags(clusterRestoreCmd)
+	clusterRestoreCmd.Flags().StringVar(
+		&restoreSnapshotId, "from-snapshot", "", "identifier or ARN of the cluster snapshot to restore",
+	)
+	clusterRestoreCmd.Flags().StringVar(
+		&restoreSourceClusterId, "from-cluster", "", "identifier of the cluster to restore to a point in time",
+	)
+	clusterRestoreCmd.Flags().StringVar(
+		&restoreTime, "restore-time", "latest",
+		"time to restore --from-cluster to in RFC 3339, or latest for its latest restorable time",
+	)
+	addListFlags(clusterListCmd, true)
+}
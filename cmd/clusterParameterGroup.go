@@ -0,0 +1,156 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster_parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/spf13/cobra"
+)
+
+// clusterParameterGroupCmd represents the clusterParameterGroup command
+var clusterParameterGroupCmd = &cobra.Command{
+	Use:   "clusterParameterGroup",
+	Short: "Create, inspect, update and delete DB cluster parameter groups",
+	Long: `Manage a single DB cluster parameter group described by a YAML or JSON
+file. For example:
+
+rds_provider clusterParameterGroup create -f cluster_parameter_group.yaml
+rds_provider clusterParameterGroup get my-cluster-parameter-group`,
+}
+
+var clusterParameterGroupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create the cluster parameter group described by a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		req := cluster_parameter_group.CreateRequest{}
+		readResource(&req)
+
+		group, err := cluster_parameter_group.CreateDBClusterParameterGroup(newService(), req)
+		if err != nil {
+			fail(err)
+		}
+		printResource(group)
+	},
+}
+
+var clusterParameterGroupGetCmd = &cobra.Command{
+	Use:   "get [name]",
+	Short: "Show a cluster parameter group",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupName := resourceId(args, clusterParameterGroupNameFromFile)
+
+		group, err := cluster_parameter_group.FindDBClusterParameterGroup(newService(), groupName)
+		if err != nil {
+			fail(err)
+		}
+		printResource(group)
+	},
+}
+
+var clusterParameterGroupUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the parameters of an existing cluster parameter group to match a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		req := cluster_parameter_group.CreateRequest{}
+		readResource(&req)
+
+		svc := newService()
+		_, err := cluster_parameter_group.FindDBClusterParameterGroup(svc, req.Name)
+		if err != nil {
+			fail(err)
+		}
+
+		_, err = provider.ReconcileClusterParameterGroup(svc, req)
+		if err != nil {
+			fail(err)
+		}
+
+		group, err := cluster_parameter_group.FindDBClusterParameterGroup(svc, req.Name)
+		if err != nil {
+			fail(err)
+		}
+		printResource(group)
+	},
+}
+
+var clusterParameterGroupDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a cluster parameter group",
+	Long: `Delete a cluster parameter group. Groups matching a --protect-name or --protect-tag pattern,
+or one of the protected patterns of the config file, are refused. The deletion
+is confirmed interactively unless --yes is given.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupName := resourceId(args, clusterParameterGroupNameFromFile)
+		g := newGuard(false)
+
+		svc := newService()
+		err := g.CheckClusterParameterGroup(svc, groupName)
+		if err != nil {
+			fail(err)
+		}
+		confirmDelete(g, rds_errors.KindClusterParameterGroup, groupName)
+
+		err = cluster_parameter_group.DeleteDBClusterParameterGroup(svc, groupName)
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+var clusterParameterGroupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cluster parameter groups",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups, err := cluster_parameter_group.ListDBClusterParameterGroups(
+			newService(), cluster_parameter_group.ListRequest{Tags: listSelector()},
+		)
+		if err != nil {
+			fail(err)
+		}
+		printResource(groups)
+	},
+}
+
+func clusterParameterGroupNameFromFile() string {
+	req := cluster_parameter_group.CreateRequest{}
+	readResource(&req)
+	return req.Name
+}
+
+func init() {
+	rootCmd.AddCommand(clusterParameterGroupCmd)
+	clusterParameterGroupCmd.AddCommand(clusterParameterGroupCreateCmd)
+	clusterParameterGroupCmd.AddCommand(clusterParameterGroupGetCmd)
+	clusterParameterGroupCmd.AddCommand(clusterParameterGroupUpdateCmd)
+	clusterParameterGroupCmd.AddCommand(clusterParameterGroupDeleteCmd)
+	clusterParameterGroupCmd.AddCommand(clusterParameterGroupListCmd)
+
+	for _, c := range []*cobra.Command{
+		clusterParameterGroupCreateCmd, clusterParameterGroupGetCmd,
+		clusterParameterGroupUpdateCmd, clusterParameterGroupDeleteCmd,
+	} {
+		addFileFlag(c)
+	}
+
+	addGuardFlags(clusterParameterGroupDeleteCmd)
+	addListFlags(clusterParameterGroupListCmd, false)
+}
@@ -15,23 +15,31 @@
 package cmd
 
 import (
-	"fmt"
-
+	"github.com/cvgw/rds_provider/pkg/provider"
 	"github.com/spf13/cobra"
 )
 
 // createAllCmd represents the createAll command
 var createAllCmd = &cobra.Command{
 	Use:   "createAll",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Short: "Create every resource described by a stack file",
+	Long: `Create the subnet group, parameter groups, cluster and instances
+described by a YAML or JSON stack file. Resources are created in dependency
+order and resources which do not depend on each other are created in parallel.
+For example:
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+rds_provider createAll -f samples/stack.yaml`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("createAll called")
+		stack, err := provider.LoadStack(file)
+		if err != nil {
+			fail(usageErrorf("%v", err))
+		}
+
+		svc := newService()
+		err = provider.CreateStack(svc, stack, stackOptions())
+		if err != nil {
+			fail(err)
+		}
 	},
 }
 
@@ -47,4 +55,5 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// createAllCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	addStackFlags(createAllCmd)
 }
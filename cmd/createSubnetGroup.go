@@ -15,44 +15,18 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"log"
-
-	"github.com/cvgw/rds_provider/pkg/provider/subnet_group"
 	"github.com/spf13/cobra"
 )
 
 // createSubnetGroupCmd represents the createSubnetGroup command
 var createSubnetGroupCmd = &cobra.Command{
 	Use:   "createSubnetGroup",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
-
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+	Short: "Create the subnet group described by a file",
+	Long: `Create the subnet group described by a YAML or JSON file. This is the
+same as subnetGroup create.`,
+	Deprecated: "use subnetGroup create",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("createSubnetGroup called")
-
-		fileData, err := ioutil.ReadFile(file)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		req := subnet_group.CreateSubnetGroupRequest{}
-		err = json.Unmarshal(fileData, &req)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		fmt.Println(req)
-
-		input := subnet_group.NewCreateDBSubnetGroupInput(req)
-
-		fmt.Println(input)
+		createSubnetGroup()
 	},
 }
 
@@ -0,0 +1,65 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/spf13/cobra"
+)
+
+// deleteAllCmd represents the deleteAll command
+var deleteAllCmd = &cobra.Command{
+	Use:   "deleteAll",
+	Short: "Delete every resource described by a stack file",
+	Long: `Delete the instances, cluster, parameter groups and subnet group
+described by a YAML or JSON stack file. Resources are deleted before the
+resources they depend on and resources which do not depend on each other are
+deleted in parallel. Each resource is only deleted once the resources depending
+on it are gone, --wait also waits for the last ones. Resources which do not
+exist are skipped. A final snapshot of the cluster is taken unless
+--skip-final-snapshot and --force are given.
+
+Every resource is checked before any is deleted. Resources with deletion
+protection enabled, or matching a --protect-name or --protect-tag pattern or
+one of the protected patterns of the config file, are refused, as is a cluster
+with instances which are not part of the stack. The deletion is confirmed
+interactively unless --yes is given. For example:
+
+rds_provider deleteAll --wait --yes -f samples/stack.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		stack, err := provider.LoadStack(file)
+		if err != nil {
+			fail(usageErrorf("%v", err))
+		}
+
+		g := newGuard(false)
+		opts := deleteStackOptions()
+		opts.Guard = &g
+
+		svc := newService()
+		err = provider.DeleteStack(svc, stack, opts)
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deleteAllCmd)
+
+	addStackFlags(deleteAllCmd)
+	addDeleteFlags(deleteAllCmd)
+	addGuardFlags(deleteAllCmd)
+}
@@ -0,0 +1,89 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+)
+
+// Exit codes returned by every command
+const (
+	exitOK                = 0
+	exitError             = 1
+	exitUsage             = 2
+	exitNotFound          = 3
+	exitAlreadyExists     = 4
+	exitInvalidState      = 5
+	exitDependencyMissing = 6
+	exitQuotaExceeded     = 7
+	exitThrottled         = 8
+	exitRefused           = 9
+)
+
+// usageError is an error in the arguments or flags given to a command
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// exitCode returns the exit code matching the class of err
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	if errors.As(err, &usageError{}) {
+		return exitUsage
+	}
+
+	if isRefusal(err) {
+		return exitRefused
+	}
+
+	switch rds_errors.ClassOf(err) {
+	case rds_errors.NotFound:
+		return exitNotFound
+	case rds_errors.AlreadyExists:
+		return exitAlreadyExists
+	case rds_errors.InvalidState:
+		return exitInvalidState
+	case rds_errors.DependencyMissing:
+		return exitDependencyMissing
+	case rds_errors.QuotaExceeded:
+		return exitQuotaExceeded
+	case rds_errors.Throttled:
+		return exitThrottled
+	default:
+		return exitError
+	}
+}
+
+// fail logs err and exits with the code matching it
+func fail(err error) {
+	log.Print(err)
+	os.Exit(exitCode(err))
+}
@@ -0,0 +1,60 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/fake_rds"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listenAddr string
+	fakeRegion string
+)
+
+// fakeServerCmd represents the fakeServer command
+var fakeServerCmd = &cobra.Command{
+	Use:   "fakeServer",
+	Short: "Serve an in-memory stand-in for the RDS API",
+	Long: `Serve the RDS Query API from memory so commands can be run end to end
+without AWS credentials. State is lost when the server exits. Point other
+commands at the server with --endpoint and --no-sign-request, or the
+` + provider.EndpointEnv + ` and RDS_PROVIDER_NO_SIGN variables. For example:
+
+rds_provider fakeServer --listen 127.0.0.1:8090 &
+rds_provider apply --endpoint http://127.0.0.1:8090 --no-sign-request --region us-west-2 -f samples/stack.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := fake_rds.New()
+		f.Region = fakeRegion
+
+		log.Printf("serving fake rds on %s", listenAddr)
+		log.Fatal(http.ListenAndServe(listenAddr, fake_rds.NewServer(f)))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fakeServerCmd)
+
+	fakeServerCmd.Flags().StringVar(
+		&listenAddr, "listen", "127.0.0.1:8090", "address to listen on",
+	)
+	fakeServerCmd.Flags().StringVar(
+		&fakeRegion, "fake-region", fake_rds.DefaultRegion, "region reported by the fake",
+	)
+}
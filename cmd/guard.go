@@ -0,0 +1,112 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/guard"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var (
+	assumeYes      bool
+	protectedNames []string
+	protectedTags  []string
+)
+
+// addGuardFlags adds the flags controlling the checks made before deleting
+// resources
+func addGuardFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "delete without asking for confirmation")
+	cmd.Flags().StringArrayVar(
+		&protectedNames, "protect-name", nil,
+		"refuse to delete resources whose identifier matches the pattern, e.g. 'prod-*' (repeatable)",
+	)
+	cmd.Flags().StringArrayVar(
+		&protectedTags, "protect-tag", nil,
+		"refuse to delete resources with a tag matching key=value or key, e.g. 'env=prod*' (repeatable)",
+	)
+}
+
+// newGuard builds the guard for a destructive command from the config file
+// and flags. Patterns given as flags add to those in the config file.
+func newGuard(cascade bool) guard.Guard {
+	cfg, err := provider.LoadGuardConfig(configFile)
+	if err != nil {
+		fail(usageErrorf("%v", err))
+	}
+
+	g := guard.Guard{
+		ProtectedNames: append(cfg.ProtectedNames, protectedNames...),
+		ProtectedTags:  append(cfg.ProtectedTags, protectedTags...),
+		Cascade:        cascade,
+	}
+	if err := g.Validate(); err != nil {
+		fail(usageErrorf("%v", err))
+	}
+
+	if !assumeYes {
+		g.Confirm = confirm
+	}
+
+	return g
+}
+
+// confirm asks on the terminal whether to delete the resources. Without a
+// terminal to ask on, --yes is required.
+func confirm(resources []string) (bool, error) {
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return false, usageErrorf("refusing to delete without confirmation, pass --yes when not running interactively")
+	}
+
+	fmt.Fprintln(os.Stderr, "The following resources will be deleted:")
+	for _, r := range resources {
+		fmt.Fprintf(os.Stderr, "  %s\n", r)
+	}
+	fmt.Fprint(os.Stderr, "Delete them? [y/N]: ")
+
+	// end of input without an answer declines
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// confirmDelete asks for confirmation of deleting a single resource, exiting
+// unless it is given
+func confirmDelete(g guard.Guard, kind, id string) {
+	err := g.ConfirmDelete([]string{fmt.Sprintf("%s %s", kind, id)})
+	if err != nil {
+		fail(err)
+	}
+}
+
+// isRefusal reports whether err is a guard refusing a deletion
+func isRefusal(err error) bool {
+	return errors.Is(err, guard.ProtectedErr) ||
+		errors.Is(err, guard.HasMembersErr) ||
+		errors.Is(err, guard.NotConfirmedErr)
+}
@@ -0,0 +1,186 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/spf13/cobra"
+)
+
+// instanceCmd represents the instance command
+var instanceCmd = &cobra.Command{
+	Use:   "instance",
+	Short: "Create, inspect, update and delete RDS cluster instances",
+	Long: `Manage a single RDS cluster instance described by a YAML or JSON file
+with the same fields as an instance in a stack file. For example:
+
+rds_provider instance create --wait -f instance.yaml
+rds_provider instance get my-instance`,
+}
+
+var instanceCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create the instance described by a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		input := instance.NewDBInstanceInput{}
+		readResource(&input)
+
+		svc := newService()
+		if waitForStatus {
+			i, err := instance.CreateDBClusterInstanceAndWait(svc, input, waitOptions())
+			if err != nil {
+				fail(err)
+			}
+			printResource(i)
+			return
+		}
+
+		i, err := instance.CreateDBClusterInstance(svc, input)
+		if err != nil {
+			fail(err)
+		}
+		printResource(i)
+	},
+}
+
+var instanceGetCmd = &cobra.Command{
+	Use:   "get [instance-id]",
+	Short: "Show an instance",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		instanceId := resourceId(args, instanceIdFromFile)
+
+		i, err := instance.FindDBClusterInstance(newService(), instanceId)
+		if err != nil {
+			fail(err)
+		}
+		printResource(i)
+	},
+}
+
+var instanceUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update an existing instance to match a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		input := instance.NewDBInstanceInput{}
+		readResource(&input)
+
+		svc := newService()
+		_, err := instance.FindDBClusterInstance(svc, input.InstanceIdentifier)
+		if err != nil {
+			fail(err)
+		}
+
+		result, err := provider.ReconcileInstance(svc, input)
+		if err != nil {
+			fail(err)
+		}
+
+		if waitForStatus && result.Action != provider.ActionNone {
+			err = instance.WaitUntilDBInstanceModified(svc, input.InstanceIdentifier, waitOptions())
+			if err != nil {
+				fail(err)
+			}
+		}
+
+		i, err := instance.FindDBClusterInstance(svc, input.InstanceIdentifier)
+		if err != nil {
+			fail(err)
+		}
+		printResource(i)
+	},
+}
+
+var instanceDeleteCmd = &cobra.Command{
+	Use:   "delete [instance-id]",
+	Short: "Delete an instance",
+	Long: `Delete an instance. Instances with deletion protection enabled, or
+matching a --protect-name or --protect-tag pattern or one of the protected
+patterns of the config file, are refused. The deletion is confirmed
+interactively unless --yes is given. A final snapshot is taken unless
+--skip-final-snapshot and --force are given. Instances of a cluster have no
+final snapshot of their own, their data is kept by the cluster.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		instanceId := resourceId(args, instanceIdFromFile)
+
+		deleteOpts := instanceDeleteOptions()
+		g := newGuard(false)
+
+		svc := newService()
+		_, err := g.CheckInstance(svc, instanceId)
+		if err != nil {
+			fail(err)
+		}
+		confirmDelete(g, rds_errors.KindInstance, instanceId)
+
+		if waitForStatus {
+			err = instance.DeleteDBClusterInstanceAndWait(svc, instanceId, deleteOpts, waitOptions())
+		} else {
+			err = instance.DeleteDBClusterInstance(svc, instanceId, deleteOpts)
+		}
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+var instanceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List instances",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		instances, err := instance.ListDBClusterInstances(newService(), instance.ListRequest{
+			Engine:     listEngine,
+			ClusterIds: listClusterIds,
+			Tags:       listSelector(),
+		})
+		if err != nil {
+			fail(err)
+		}
+		printResource(instances)
+	},
+}
+
+func instanceIdFromFile() string {
+	input := instance.NewDBInstanceInput{}
+	readResource(&input)
+	return input.InstanceIdentifier
+}
+
+func init() {
+	rootCmd.AddCommand(instanceCmd)
+	instanceCmd.AddCommand(instanceCreateCmd)
+	instanceCmd.AddCommand(instanceGetCmd)
+	instanceCmd.AddCommand(instanceUpdateCmd)
+	instanceCmd.AddCommand(instanceDeleteCmd)
+	instanceCmd.AddCommand(instanceListCmd)
+
+	for _, c := range []*cobra.Command{instanceCreateCmd, instanceGetCmd, instanceUpdateCmd, instanceDeleteCmd} {
+		addFileFlag(c)
+	}
+
+	for _, c := range []*cobra.Command{instanceCreateCmd, instanceUpdateCmd, instanceDeleteCmd} {
+		addWaitFlags(c)
+	}
+
+	addDeleteFlags(instanceDeleteCmd)
+	addGuardFlags(instanceDeleteCmd)
+	addListFlags(instanceListCmd, true)
+}
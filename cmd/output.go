@@ -0,0 +1,64 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/cvgw/rds_provider/pkg/provider/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputFormat string
+	outputQuery  string
+	printer      output.Printer
+)
+
+func init() {
+	formats := make([]string, 0, len(output.Formats))
+	for _, f := range output.Formats {
+		formats = append(formats, string(f))
+	}
+
+	flags := rootCmd.PersistentFlags()
+	flags.StringVarP(
+		&outputFormat, "output", "o", string(output.FormatTable),
+		"output format, one of "+strings.Join(formats, ", "),
+	)
+	flags.StringVar(
+		&outputQuery, "query", "",
+		"JMESPath expression selecting the fields to output, e.g. 'DBClusterMembers[].DBInstanceIdentifier'",
+	)
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		var err error
+		printer, err = output.NewPrinter(outputFormat, outputQuery)
+		if err != nil {
+			return usageErrorf("%v", err)
+		}
+		return nil
+	}
+}
+
+// printResource writes a resource, or a slice of them, in the format chosen
+// with --output
+func printResource(v interface{}) {
+	err := printer.Print(os.Stdout, v)
+	if err != nil {
+		fail(err)
+	}
+}
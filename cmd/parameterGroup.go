@@ -0,0 +1,155 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/spf13/cobra"
+)
+
+// parameterGroupCmd represents the parameterGroup command
+var parameterGroupCmd = &cobra.Command{
+	Use:   "parameterGroup",
+	Short: "Create, inspect, update and delete DB parameter groups",
+	Long: `Manage a single DB parameter group described by a YAML or JSON file.
+For example:
+
+rds_provider parameterGroup create -f parameter_group.yaml
+rds_provider parameterGroup get my-parameter-group`,
+}
+
+var parameterGroupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create the parameter group described by a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		req := parameter_group.CreateRequest{}
+		readResource(&req)
+
+		group, err := parameter_group.CreateDBParameterGroup(newService(), req)
+		if err != nil {
+			fail(err)
+		}
+		printResource(group)
+	},
+}
+
+var parameterGroupGetCmd = &cobra.Command{
+	Use:   "get [name]",
+	Short: "Show a parameter group",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupName := resourceId(args, parameterGroupNameFromFile)
+
+		group, err := parameter_group.FindDBParameterGroup(newService(), groupName)
+		if err != nil {
+			fail(err)
+		}
+		printResource(group)
+	},
+}
+
+var parameterGroupUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the parameters of an existing parameter group to match a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		req := parameter_group.CreateRequest{}
+		readResource(&req)
+
+		svc := newService()
+		_, err := parameter_group.FindDBParameterGroup(svc, req.Name)
+		if err != nil {
+			fail(err)
+		}
+
+		_, err = provider.ReconcileParameterGroup(svc, req)
+		if err != nil {
+			fail(err)
+		}
+
+		group, err := parameter_group.FindDBParameterGroup(svc, req.Name)
+		if err != nil {
+			fail(err)
+		}
+		printResource(group)
+	},
+}
+
+var parameterGroupDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a parameter group",
+	Long: `Delete a parameter group. Groups matching a --protect-name or --protect-tag pattern,
+or one of the protected patterns of the config file, are refused. The deletion
+is confirmed interactively unless --yes is given.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupName := resourceId(args, parameterGroupNameFromFile)
+		g := newGuard(false)
+
+		svc := newService()
+		err := g.CheckParameterGroup(svc, groupName)
+		if err != nil {
+			fail(err)
+		}
+		confirmDelete(g, rds_errors.KindParameterGroup, groupName)
+
+		err = parameter_group.DeleteDBParameterGroup(svc, groupName)
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+var parameterGroupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List parameter groups",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups, err := parameter_group.ListDBParameterGroups(newService(), parameter_group.ListRequest{
+			Tags: listSelector(),
+		})
+		if err != nil {
+			fail(err)
+		}
+		printResource(groups)
+	},
+}
+
+func parameterGroupNameFromFile() string {
+	req := parameter_group.CreateRequest{}
+	readResource(&req)
+	return req.Name
+}
+
+func init() {
+	rootCmd.AddCommand(parameterGroupCmd)
+	parameterGroupCmd.AddCommand(parameterGroupCreateCmd)
+	parameterGroupCmd.AddCommand(parameterGroupGetCmd)
+	parameterGroupCmd.AddCommand(parameterGroupUpdateCmd)
+	parameterGroupCmd.AddCommand(parameterGroupDeleteCmd)
+	parameterGroupCmd.AddCommand(parameterGroupListCmd)
+
+	for _, c := range []*cobra.Command{
+		parameterGroupCreateCmd, parameterGroupGetCmd, parameterGroupUpdateCmd, parameterGroupDeleteCmd,
+	} {
+		addFileFlag(c)
+	}
+
+	addGuardFlags(parameterGroupDeleteCmd)
+	addListFlags(parameterGroupListCmd, false)
+}
@@ -0,0 +1,57 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/spf13/cobra"
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show what applying a stack file would change",
+	Long: `Compare the resources described by a YAML or JSON stack file to their
+current state and print a per field diff. Nothing is modified. Each resource
+is marked as created (+) or updated in place (~). Changes apply can not make,
+such as those which require replacing a resource or deleting an instance
+missing from the stack, are marked as blocked (!). For example:
+
+rds_provider plan -f samples/stack.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		stack, err := provider.LoadStack(file)
+		if err != nil {
+			fail(usageErrorf("%v", err))
+		}
+
+		svc := newService()
+		changes, err := provider.PlanStack(svc, stack)
+		if err != nil {
+			fail(err)
+		}
+
+		provider.WritePlan(os.Stdout, changes)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	planCmd.PersistentFlags().StringVarP(
+		&file, "file", "f", "", "stack file describing the resources",
+	)
+}
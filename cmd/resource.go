@@ -0,0 +1,186 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/tags"
+	"github.com/cvgw/rds_provider/pkg/provider/wait"
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// addFileFlag adds the --file flag naming a YAML or JSON resource file
+func addFileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(
+		&file, "file", "f", "", "YAML or JSON file describing the resource",
+	)
+}
+
+// addWaitFlags adds the flags controlling waiting for a resource to reach its
+// target status
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(
+		&waitForStatus, "wait", "w", false,
+		"wait for the resource to reach its target status",
+	)
+	cmd.Flags().DurationVar(
+		&waitTimeout, "timeout", wait.DefaultTimeout,
+		"maximum time to wait for the resource",
+	)
+}
+
+func waitOptions() wait.Options {
+	return wait.Options{Timeout: waitTimeout}
+}
+
+var (
+	listEngine     string
+	listClusterIds []string
+	listTags       []string
+)
+
+// addListFlags adds the flags narrowing the resources listed. Engine and
+// cluster filters are only added when the resource supports them.
+func addListFlags(cmd *cobra.Command, filters bool) {
+	cmd.Flags().StringArrayVar(
+		&listTags, "tag", nil, "only list resources with the tag, given as key=value or key (repeatable)",
+	)
+
+	if filters {
+		cmd.Flags().StringVar(&listEngine, "engine", "", "only list resources with the engine")
+		cmd.Flags().StringSliceVar(
+			&listClusterIds, "cluster", nil, "only list resources of the clusters, given as ids or ARNs",
+		)
+	}
+}
+
+// listSelector returns the tags given with --tag
+func listSelector() tags.Selector {
+	selector, err := tags.ParseSelector(listTags)
+	if err != nil {
+		fail(usageErrorf("%v", err))
+	}
+
+	return selector
+}
+
+var (
+	finalSnapshotId        string
+	skipFinalSnapshot      bool
+	forceDelete            bool
+	retainAutomatedBackups bool
+)
+
+// addDeleteFlags adds the flags controlling the final snapshot taken when a
+// cluster or instance is deleted
+func addDeleteFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&finalSnapshotId, "final-snapshot-id", "",
+		"identifier of the final snapshot (default <id>-final-<timestamp>)",
+	)
+	cmd.Flags().BoolVar(
+		&skipFinalSnapshot, "skip-final-snapshot", false,
+		"delete without a final snapshot, requires --force",
+	)
+	cmd.Flags().BoolVar(&forceDelete, "force", false, "allow deleting without a final snapshot")
+	cmd.Flags().BoolVar(
+		&retainAutomatedBackups, "retain-automated-backups", false,
+		"keep the automated backups of deleted instances",
+	)
+}
+
+// checkDeleteFlags fails unless the delete flags are consistent
+func checkDeleteFlags() {
+	if skipFinalSnapshot && !forceDelete {
+		fail(usageErrorf("--skip-final-snapshot deletes data without a snapshot, add --force to confirm"))
+	}
+
+	if skipFinalSnapshot && finalSnapshotId != "" {
+		fail(usageErrorf("--final-snapshot-id and --skip-final-snapshot can not be used together"))
+	}
+}
+
+func clusterDeleteOptions() cluster.DeleteOptions {
+	checkDeleteFlags()
+
+	return cluster.DeleteOptions{
+		FinalSnapshotId:   finalSnapshotId,
+		SkipFinalSnapshot: skipFinalSnapshot,
+		Force:             forceDelete,
+	}
+}
+
+func instanceDeleteOptions() instance.DeleteOptions {
+	checkDeleteFlags()
+
+	return instance.DeleteOptions{
+		FinalSnapshotId:        finalSnapshotId,
+		SkipFinalSnapshot:      skipFinalSnapshot,
+		Force:                  forceDelete,
+		RetainAutomatedBackups: retainAutomatedBackups,
+	}
+}
+
+// readResource reads the resource file given with --file into v
+func readResource(v interface{}) {
+	if file == "" {
+		fail(usageErrorf("--file is required"))
+	}
+
+	fileData, err := ioutil.ReadFile(file)
+	if err != nil {
+		fail(usageErrorf("%v", err))
+	}
+
+	err = yaml.Unmarshal(fileData, v)
+	if err != nil {
+		fail(usageErrorf("%s: %v", file, err))
+	}
+
+	if p, ok := v.(parameterValidator); ok {
+		err = p.ValidateParameters()
+		if err != nil {
+			fail(usageErrorf("%s: %v", file, err))
+		}
+	}
+}
+
+// parameterValidator is a parameter group request checking its parameters
+type parameterValidator interface {
+	ValidateParameters() error
+}
+
+// resourceId returns the identifier given as the only argument, or else the
+// one fromFile reads from the resource file
+func resourceId(args []string, fromFile func() string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+
+	if file == "" {
+		fail(usageErrorf("an identifier argument or --file is required"))
+	}
+
+	id := fromFile()
+	if id == "" {
+		fail(usageErrorf("%s does not name a resource", file))
+	}
+
+	return id
+}
@@ -0,0 +1,142 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cvgw/rds_provider/pkg/provider/database_restore"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreConn database_restore.ConnectionConfig
+	restoreOpts database_restore.RestoreOptions
+)
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a SQL file to a MySQL database",
+	Long: `Execute the statements of a SQL file, such as mysqldump output or a schema
+file, along with the files it includes with source commands. The server is
+the writer endpoint of the cluster given with --cluster, or the one given with
+--dsn or --host. For example:
+
+rds_provider restore -f dump.sql --cluster my-cluster --user admin --password-env DB_PASSWORD
+rds_provider restore -f schema.sql --dsn 'admin@tcp(localhost:3306)/app' --dry-run
+
+Files compressed with gzip, bzip2 or zstd are decompressed as they are read.
+Tar archives, compressed or not, are streamed and restore each of their files
+as it comes. They must hold their files in the order of their names, as
+written by 'tar --sort=name', and fail on a file out of order. Source commands
+include files from the archive.
+
+Source commands include other files, relative to the directory of the file
+including them. Patterns such as 'source tables/*.sql' include every file they
+match in lexical order. Include cycles are refused. The files read are listed
+in the summary.
+
+The restore stops at the first failed statement unless --continue-on-error is
+given. With --state-file the restore saves how far it got after each commit
+and a rerun resumes from there. A summary of the executed, failed and skipped
+statements is printed once the restore stops. It exits with an error when any
+statement failed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if file == "" {
+			fail(usageErrorf("--file is required"))
+		}
+		if err := restoreConn.Validate(); err != nil {
+			fail(usageErrorf("%v", err))
+		}
+		if restoreOpts.BatchSize < 0 {
+			fail(usageErrorf("--batch-size can not be negative"))
+		}
+		if restoreOpts.MaxIncludeDepth < 1 {
+			fail(usageErrorf("--max-include-depth must be at least 1"))
+		}
+
+		var svc rds_api.RDSAPI
+		if restoreConn.ClusterId != "" && !restoreOpts.DryRun {
+			svc = newService()
+		}
+
+		report, err := database_restore.RestoreSQLFile(svc, file, restoreConn, restoreOpts)
+		if err == nil || report.Executed+report.Failed+report.Skipped > 0 {
+			printResource(report)
+		}
+		if err != nil {
+			fail(err)
+		}
+		if report.Failed > 0 {
+			fail(fmt.Errorf("%d statements failed", report.Failed))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	flags := restoreCmd.Flags()
+	flags.StringVarP(&file, "file", "f", "", "SQL file or tar archive to restore")
+	flags.BoolVar(&restoreOpts.DryRun, "dry-run", false, "only parse the file and count its statements")
+	flags.BoolVar(
+		&restoreOpts.ContinueOnError, "continue-on-error", false,
+		"go on with the next statement when one fails",
+	)
+	flags.DurationVar(
+		&restoreOpts.StatementTimeout, "statement-timeout", 0,
+		"maximum time each statement may run for, stops the restore when exceeded (default no limit)",
+	)
+	flags.IntVar(
+		&restoreOpts.BatchSize, "batch-size", 0,
+		"number of statements to commit together in a transaction (default each on its own)",
+	)
+	flags.StringVar(
+		&restoreOpts.StateFile, "state-file", "", "file to save progress to and resume from",
+	)
+	flags.IntVar(
+		&restoreOpts.MaxIncludeDepth, "max-include-depth", database_restore.DefaultMaxIncludeDepth,
+		"maximum number of nested source commands to follow",
+	)
+
+	flags.StringVar(
+		&restoreConn.ClusterId, "cluster", "", "cluster whose writer endpoint to restore to",
+	)
+	flags.StringVar(
+		&restoreConn.DSN, "dsn", "",
+		"data source name, e.g. 'user:password@tcp(host:3306)/database', other flags override its fields",
+	)
+	flags.StringVar(&restoreConn.Host, "host", "", "server host (default "+database_restore.DefaultHost+")")
+	flags.IntVar(&restoreConn.Port, "port", 0, "server port (default the cluster's port or 3306)")
+	flags.StringVarP(&restoreConn.User, "user", "u", "", "user to connect as")
+	flags.StringVar(
+		&restoreConn.PasswordEnv, "password-env", "", "environment variable holding the password",
+	)
+	flags.StringVar(&restoreConn.PasswordFile, "password-file", "", "file holding the password")
+	flags.StringVar(&restoreConn.Database, "database", "", "database to restore into")
+	flags.StringVar(
+		&restoreConn.TLS, "tls", "",
+		"TLS mode: "+database_restore.TLSDisabled+", "+database_restore.TLSRequired+" or "+
+			database_restore.TLSSkipVerify,
+	)
+	flags.StringVar(
+		&restoreConn.TLSCAFile, "tls-ca-file", "",
+		"PEM file of the certificate authorities to verify the server with, e.g. the RDS CA bundle",
+	)
+	flags.DurationVar(&restoreConn.Timeout, "connect-timeout", 0, "maximum time to connect")
+}
@@ -0,0 +1,100 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/retry"
+)
+
+var (
+	configFile      string
+	sessionFlags    provider.SessionConfig
+	keySession      bool
+	noSignRequest   bool
+	retryMaxElapsed = retry.DefaultPolicy.MaxElapsed
+)
+
+func init() {
+	flags := rootCmd.PersistentFlags()
+
+	flags.StringVar(
+		&configFile, "config", "",
+		"config file with session settings (default $"+provider.ConfigEnv+" or ~/"+provider.DefaultConfigFile+")",
+	)
+	flags.StringVar(&sessionFlags.Profile, "profile", "", "shared config profile (env AWS_PROFILE)")
+	flags.StringVar(&sessionFlags.Region, "region", "", "AWS region (env AWS_REGION)")
+	flags.StringVar(
+		&sessionFlags.Endpoint, "endpoint", "", "RDS endpoint URL (env "+provider.EndpointEnv+")",
+	)
+	flags.StringVar(&sessionFlags.RoleArn, "role-arn", "", "ARN of a role to assume (env AWS_ROLE_ARN)")
+	flags.StringVar(
+		&sessionFlags.ExternalId, "external-id", "",
+		"external ID used when assuming the role (env RDS_PROVIDER_EXTERNAL_ID)",
+	)
+	flags.StringVar(
+		&sessionFlags.MfaSerial, "mfa-serial", "",
+		"MFA device used when assuming the role, the code is read from stdin (env RDS_PROVIDER_MFA_SERIAL)",
+	)
+	flags.DurationVar(
+		&sessionFlags.SessionDuration, "session-duration", 0,
+		"lifetime of assumed role credentials (env RDS_PROVIDER_SESSION_DURATION)",
+	)
+	flags.BoolVar(
+		&keySession, "key-session", false,
+		"use the keys in AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY in place of the profile (env AWS_KEY_SESSION)",
+	)
+	flags.BoolVar(
+		&noSignRequest, "no-sign-request", false,
+		"send requests unsigned, e.g. to a fakeServer (env RDS_PROVIDER_NO_SIGN)",
+	)
+	flags.DurationVar(
+		&retryMaxElapsed, "retry-max-elapsed", retry.DefaultPolicy.MaxElapsed,
+		"maximum time to spend retrying throttled calls and calls on busy resources, 0 disables retries",
+	)
+}
+
+// newService builds the RDS client used by commands. Session settings are
+// taken from flags, then environment variables, then the config file. Calls
+// failing with throttling or invalid state faults are retried with backoff.
+func newService() *retry.RDS {
+	flags := rootCmd.PersistentFlags()
+	if flags.Changed("key-session") {
+		sessionFlags.KeySession = aws.Bool(keySession)
+	}
+	if flags.Changed("no-sign-request") {
+		sessionFlags.NoSign = aws.Bool(noSignRequest)
+	}
+
+	cfg, err := provider.ResolveSessionConfig(sessionFlags, configFile)
+	if err != nil {
+		fail(usageErrorf("%v", err))
+	}
+
+	sess, err := provider.NewSessionFromConfig(cfg)
+	if errors.Is(err, provider.MissingRegionErr) {
+		fail(usageErrorf("%v", err))
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	policy := retry.DefaultPolicy.WithMaxElapsed(retryMaxElapsed)
+	return retry.New(rds.New(sess, cfg.RDSConfig()), policy)
+}
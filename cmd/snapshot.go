@@ -0,0 +1,318 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotClusterId string
+	snapshotType      string
+	kmsKeyId          string
+	copyTags          bool
+	sourceRegion      string
+	addAccounts       []string
+	removeAccounts    []string
+	keepLast          int
+	keepDaily         int
+	keepWeekly        int
+	pruneDryRun       bool
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Create, copy, share and delete manual cluster snapshots",
+	Long: `Manage manual snapshots of RDS clusters. For example:
+
+rds_provider snapshot create --cluster my-cluster --wait
+rds_provider snapshot copy my-cluster-20190101-120000 my-cluster-copy --kms-key-id alias/backups
+rds_provider snapshot share my-cluster-copy --add-account 123456789012
+rds_provider snapshot prune --cluster my-cluster --keep-last 3 --keep-daily 7 --keep-weekly 4 --dry-run`,
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create [snapshot-id]",
+	Short: "Snapshot a cluster",
+	Long: `Take a manual snapshot of the cluster given with --cluster. The snapshot
+identifier defaults to <cluster-id>-<timestamp>.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if snapshotClusterId == "" {
+			fail(usageErrorf("--cluster is required"))
+		}
+
+		req := snapshot.CreateRequest{
+			SnapshotId: snapshot.SnapshotId(snapshotClusterId, time.Now()),
+			ClusterId:  snapshotClusterId,
+		}
+		if len(args) == 1 {
+			req.SnapshotId = args[0]
+		}
+
+		svc := newService()
+		if waitForStatus {
+			s, err := snapshot.CreateDBClusterSnapshotAndWait(svc, req, waitOptions())
+			if err != nil {
+				fail(err)
+			}
+			printResource(s)
+			return
+		}
+
+		s, err := snapshot.CreateDBClusterSnapshot(svc, req)
+		if err != nil {
+			fail(err)
+		}
+		printResource(s)
+	},
+}
+
+var snapshotGetCmd = &cobra.Command{
+	Use:   "get <snapshot-id>",
+	Short: "Show a cluster snapshot",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := snapshot.FindDBClusterSnapshot(newService(), args[0])
+		if err != nil {
+			fail(err)
+		}
+		printResource(s)
+	},
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cluster snapshots",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		switch snapshotType {
+		case "", snapshot.TypeManual, snapshot.TypeAutomated, snapshot.TypeShared, snapshot.TypePublic:
+		default:
+			fail(usageErrorf("unknown snapshot type %q, expected manual, automated, shared or public", snapshotType))
+		}
+
+		snapshots, err := snapshot.ListDBClusterSnapshots(newService(), snapshot.ListRequest{
+			Engine:       listEngine,
+			ClusterIds:   listClusterIds,
+			SnapshotType: snapshotType,
+			Tags:         listSelector(),
+		})
+		if err != nil {
+			fail(err)
+		}
+		printResource(snapshots)
+	},
+}
+
+var snapshotCopyCmd = &cobra.Command{
+	Use:   "copy <source-snapshot-id> <target-snapshot-id>",
+	Short: "Copy a cluster snapshot",
+	Long: `Copy a cluster snapshot. Snapshots shared from another account or in
+another region are given by ARN, along with --kms-key-id to encrypt the copy
+under a key of this account and, across regions, --source-region. Encrypted
+snapshots are re-encrypted under --kms-key-id when it is given.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		req := snapshot.CopyRequest{
+			SourceSnapshotId: args[0],
+			TargetSnapshotId: args[1],
+			KmsKeyId:         kmsKeyId,
+			CopyTags:         copyTags,
+			SourceRegion:     sourceRegion,
+		}
+
+		svc := newService()
+		if waitForStatus {
+			s, err := snapshot.CopyDBClusterSnapshotAndWait(svc, req, waitOptions())
+			if err != nil {
+				fail(err)
+			}
+			printResource(s)
+			return
+		}
+
+		s, err := snapshot.CopyDBClusterSnapshot(svc, req)
+		if err != nil {
+			fail(err)
+		}
+		printResource(s)
+	},
+}
+
+var snapshotShareCmd = &cobra.Command{
+	Use:   "share <snapshot-id>",
+	Short: "Share a cluster snapshot with other accounts",
+	Long: `Add or remove the AWS accounts allowed to copy and restore a manual
+snapshot, then print the accounts it is shared with. Without --add-account or
+--remove-account the accounts are only printed. "all" shares the snapshot
+publicly, which RDS refuses for encrypted snapshots. Accounts restoring an
+encrypted snapshot must also be allowed to use its KMS key.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		req := snapshot.ShareRequest{
+			SnapshotId:     args[0],
+			AddAccounts:    addAccounts,
+			RemoveAccounts: removeAccounts,
+		}
+		if err := req.Validate(); err != nil {
+			fail(usageErrorf("%v", err))
+		}
+
+		accounts, err := snapshot.ShareDBClusterSnapshot(newService(), req)
+		if err != nil {
+			fail(err)
+		}
+		printResource(accounts)
+	},
+}
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:   "delete <snapshot-id>",
+	Short: "Delete a manual cluster snapshot",
+	Long: `Delete a manual cluster snapshot. Snapshots matching a --protect-name or
+--protect-tag pattern, or one of the protected patterns of the config file,
+are refused. The deletion is confirmed interactively unless --yes is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshotId := args[0]
+		g := newGuard(false)
+
+		svc := newService()
+		err := g.CheckClusterSnapshot(svc, snapshotId)
+		if err != nil {
+			fail(err)
+		}
+		confirmDelete(g, rds_errors.KindClusterSnapshot, snapshotId)
+
+		if waitForStatus {
+			err = snapshot.DeleteDBClusterSnapshotAndWait(svc, snapshotId, waitOptions())
+		} else {
+			err = snapshot.DeleteDBClusterSnapshot(svc, snapshotId)
+		}
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+var snapshotPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete manual cluster snapshots outside a retention policy",
+	Long: `Delete the manual snapshots of the clusters given with --cluster, or of the
+snapshots matching --tag, which the retention policy does not keep. The policy
+applies to each cluster separately and keeps a snapshot when any of its rules
+does:
+
+--keep-last N    the N most recent snapshots
+--keep-daily D   the newest snapshot of each of the last D days
+--keep-weekly W  the newest snapshot of each of the last W ISO weeks
+
+Days and weeks are counted in UTC and include the current one. Snapshots which
+are not available yet are kept, as are snapshots matching a --protect-name or
+--protect-tag pattern or one of the protected patterns of the config file.
+With --dry-run the decisions are only reported. Otherwise the deletions are
+confirmed interactively unless --yes is given, and the decision made for every
+snapshot is printed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(listClusterIds) == 0 && len(listTags) == 0 {
+			fail(usageErrorf("--cluster or --tag is required"))
+		}
+
+		g := newGuard(false)
+		req := provider.PruneRequest{
+			Snapshots: snapshot.ListRequest{
+				Engine:     listEngine,
+				ClusterIds: listClusterIds,
+				Tags:       listSelector(),
+			},
+			Policy: snapshot.RetentionPolicy{
+				KeepLast:   keepLast,
+				KeepDaily:  keepDaily,
+				KeepWeekly: keepWeekly,
+			},
+			DryRun: pruneDryRun,
+			Guard:  &g,
+		}
+		if err := req.Policy.Validate(); err != nil {
+			fail(usageErrorf("%v", err))
+		}
+
+		decisions, err := provider.PruneSnapshots(newService(), req, time.Now())
+		if decisions != nil {
+			printResource(decisions)
+		}
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotGetCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotCopyCmd)
+	snapshotCmd.AddCommand(snapshotShareCmd)
+	snapshotCmd.AddCommand(snapshotDeleteCmd)
+	snapshotCmd.AddCommand(snapshotPruneCmd)
+
+	for _, c := range []*cobra.Command{snapshotCreateCmd, snapshotCopyCmd, snapshotDeleteCmd} {
+		addWaitFlags(c)
+	}
+
+	snapshotCreateCmd.Flags().StringVar(&snapshotClusterId, "cluster", "", "identifier of the cluster to snapshot")
+
+	addListFlags(snapshotListCmd, true)
+	snapshotListCmd.Flags().StringVar(
+		&snapshotType, "type", "", "only list snapshots of the type: manual, automated, shared or public",
+	)
+
+	snapshotCopyCmd.Flags().StringVar(&kmsKeyId, "kms-key-id", "", "KMS key id, ARN or alias to encrypt the copy with")
+	snapshotCopyCmd.Flags().BoolVar(&copyTags, "copy-tags", false, "copy the tags of the source snapshot")
+	snapshotCopyCmd.Flags().StringVar(
+		&sourceRegion, "source-region", "", "region of the source snapshot when copying across regions",
+	)
+
+	snapshotShareCmd.Flags().StringSliceVar(
+		&addAccounts, "add-account", nil, "account id to share the snapshot with, or all (repeatable)",
+	)
+	snapshotShareCmd.Flags().StringSliceVar(
+		&removeAccounts, "remove-account", nil, "account id to stop sharing the snapshot with, or all (repeatable)",
+	)
+
+	addGuardFlags(snapshotDeleteCmd)
+
+	addListFlags(snapshotPruneCmd, true)
+	snapshotPruneCmd.Flags().IntVar(&keepLast, "keep-last", 0, "number of most recent snapshots to keep")
+	snapshotPruneCmd.Flags().IntVar(
+		&keepDaily, "keep-daily", 0, "number of days for which to keep the newest snapshot of each day",
+	)
+	snapshotPruneCmd.Flags().IntVar(
+		&keepWeekly, "keep-weekly", 0, "number of weeks for which to keep the newest snapshot of each week",
+	)
+	snapshotPruneCmd.Flags().BoolVar(
+		&pruneDryRun, "dry-run", false, "only report which snapshots would be deleted",
+	)
+	addGuardFlags(snapshotPruneCmd)
+}
@@ -0,0 +1,65 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/wait"
+	"github.com/spf13/cobra"
+)
+
+var (
+	concurrency   int
+	waitForStatus bool
+	waitTimeout   time.Duration
+)
+
+// addStackFlags adds the flags shared by commands which operate on a stack file
+func addStackFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(
+		&file, "file", "f", "", "stack file describing the resources",
+	)
+	cmd.PersistentFlags().IntVarP(
+		&concurrency, "concurrency", "c", provider.DefaultConcurrency,
+		"maximum number of resources to operate on at once",
+	)
+	cmd.PersistentFlags().BoolVarP(
+		&waitForStatus, "wait", "w", false,
+		"wait for each cluster and instance to reach its target status",
+	)
+	cmd.PersistentFlags().DurationVar(
+		&waitTimeout, "timeout", wait.DefaultTimeout,
+		"maximum time to wait for each cluster and instance",
+	)
+}
+
+func stackOptions() provider.StackOptions {
+	return provider.StackOptions{
+		Concurrency: concurrency,
+		Wait:        waitForStatus,
+		WaitOptions: wait.Options{Timeout: waitTimeout},
+	}
+}
+
+// deleteStackOptions returns the stack options along with the final snapshot
+// settings given with addDeleteFlags
+func deleteStackOptions() provider.StackOptions {
+	opts := stackOptions()
+	opts.ClusterDeleteOptions = clusterDeleteOptions()
+	opts.RetainAutomatedBackups = retainAutomatedBackups
+	return opts
+}
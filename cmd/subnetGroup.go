@@ -0,0 +1,160 @@
+// Copyright © 2019 NAME HERE <EMAIL ADDRESS>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/cvgw/rds_provider/pkg/provider"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/subnet_group"
+	"github.com/spf13/cobra"
+)
+
+// subnetGroupCmd represents the subnetGroup command
+var subnetGroupCmd = &cobra.Command{
+	Use:   "subnetGroup",
+	Short: "Create, inspect, update and delete DB subnet groups",
+	Long: `Manage a single DB subnet group described by a YAML or JSON file. For
+example:
+
+rds_provider subnetGroup create -f subnet_group.yaml
+rds_provider subnetGroup get my-subnet-group`,
+}
+
+var subnetGroupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create the subnet group described by a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		createSubnetGroup()
+	},
+}
+
+var subnetGroupGetCmd = &cobra.Command{
+	Use:   "get [name]",
+	Short: "Show a subnet group",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupName := resourceId(args, subnetGroupNameFromFile)
+
+		group, err := subnet_group.FindDBSubnetGroup(newService(), groupName)
+		if err != nil {
+			fail(err)
+		}
+		printResource(group)
+	},
+}
+
+var subnetGroupUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update an existing subnet group to match a file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		req := subnet_group.CreateSubnetGroupRequest{}
+		readResource(&req)
+
+		svc := newService()
+		_, err := subnet_group.FindDBSubnetGroup(svc, req.Name)
+		if err != nil {
+			fail(err)
+		}
+
+		_, err = provider.ReconcileSubnetGroup(svc, req)
+		if err != nil {
+			fail(err)
+		}
+
+		group, err := subnet_group.FindDBSubnetGroup(svc, req.Name)
+		if err != nil {
+			fail(err)
+		}
+		printResource(group)
+	},
+}
+
+var subnetGroupDeleteCmd = &cobra.Command{
+	Use:   "delete [name]",
+	Short: "Delete a subnet group",
+	Long: `Delete a subnet group. Groups matching a --protect-name or --protect-tag pattern,
+or one of the protected patterns of the config file, are refused. The deletion
+is confirmed interactively unless --yes is given.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		groupName := resourceId(args, subnetGroupNameFromFile)
+		g := newGuard(false)
+
+		svc := newService()
+		err := g.CheckSubnetGroup(svc, groupName)
+		if err != nil {
+			fail(err)
+		}
+		confirmDelete(g, rds_errors.KindSubnetGroup, groupName)
+
+		err = subnet_group.DeleteDBSubnetGroup(svc, groupName)
+		if err != nil {
+			fail(err)
+		}
+	},
+}
+
+var subnetGroupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List subnet groups",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		groups, err := subnet_group.ListDBSubnetGroups(newService(), subnet_group.ListRequest{
+			Tags: listSelector(),
+		})
+		if err != nil {
+			fail(err)
+		}
+		printResource(groups)
+	},
+}
+
+// createSubnetGroup creates the subnet group described by --file
+func createSubnetGroup() {
+	req := subnet_group.CreateSubnetGroupRequest{}
+	readResource(&req)
+
+	group, err := subnet_group.CreateSubnetGroup(newService(), req)
+	if err != nil {
+		fail(err)
+	}
+	printResource(group)
+}
+
+func subnetGroupNameFromFile() string {
+	req := subnet_group.CreateSubnetGroupRequest{}
+	readResource(&req)
+	return req.Name
+}
+
+func init() {
+	rootCmd.AddCommand(subnetGroupCmd)
+	subnetGroupCmd.AddCommand(subnetGroupCreateCmd)
+	subnetGroupCmd.AddCommand(subnetGroupGetCmd)
+	subnetGroupCmd.AddCommand(subnetGroupUpdateCmd)
+	subnetGroupCmd.AddCommand(subnetGroupDeleteCmd)
+	subnetGroupCmd.AddCommand(subnetGroupListCmd)
+
+	for _, c := range []*cobra.Command{
+		subnetGroupCreateCmd, subnetGroupGetCmd, subnetGroupUpdateCmd, subnetGroupDeleteCmd,
+	} {
+		addFileFlag(c)
+	}
+
+	addGuardFlags(subnetGroupDeleteCmd)
+	addListFlags(subnetGroupListCmd, false)
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"errors"
 	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -11,8 +12,9 @@ import (
 )
 
 const (
-	profile       = "dev"
-	defaultRegion = "us-west-2"
+	// EndpointEnv overrides the RDS endpoint, e.g. to point the client at a
+	// local fake_rds server
+	EndpointEnv = "RDS_PROVIDER_ENDPOINT"
 )
 
 type AwsSessionEnv struct {
@@ -34,23 +36,100 @@ func NewSessionFromEnv(env AwsSessionEnv) *session.Session {
 	return newKeySession(env.Aki, env.Sak, env.Region, env.RoleArn)
 }
 
-func NewSession() *session.Session {
-	if os.Getenv("AWS_KEY_SESSION") == "true" {
+var (
+	MissingRegionErr error
+)
+
+func init() {
+	MissingRegionErr = errors.New(
+		"no region set, use --region, AWS_REGION, the config file or the region of the profile",
+	)
+}
+
+// NewSession builds a session from the config file and environment, see
+// ResolveSessionConfig
+func NewSession() (*session.Session, error) {
+	cfg, err := ResolveSessionConfig(SessionConfig{}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSessionFromConfig(cfg)
+}
+
+// NewSessionFromConfig builds a session from a resolved SessionConfig. The
+// profile and region fall back to the AWS SDK defaults, including the shared
+// config file, when they are not set. When a role is given its credentials are
+// assumed on top of the profile or key credentials. The endpoint is not set on
+// the session, clients of RDS take it from RDSConfig.
+func NewSessionFromConfig(cfg SessionConfig) (*session.Session, error) {
+	awsCfg := aws.NewConfig()
+	if cfg.Region != "" {
+		awsCfg = awsCfg.WithRegion(cfg.Region)
+	}
+
+	var sess *session.Session
+	var err error
+	switch {
+	case aws.BoolValue(cfg.NoSign):
+		log.Debug("building unsigned session")
+		sess, err = session.NewSession(awsCfg.WithCredentials(credentials.AnonymousCredentials))
+	case aws.BoolValue(cfg.KeySession):
 		log.Debug("building session from keys")
 		env := AwsSessionEnv{}.PopulateEnv()
-		return NewSessionFromEnv(env)
+		sess, err = session.NewSession(
+			awsCfg.Copy().WithCredentials(credentials.NewStaticCredentials(env.Aki, env.Sak, "")),
+		)
+	default:
+		log.Debugf("building session from profile %q", cfg.Profile)
+		sess, err = session.NewSessionWithOptions(session.Options{
+			Config:                  *awsCfg,
+			Profile:                 cfg.Profile,
+			SharedConfigState:       session.SharedConfigEnable,
+			AssumeRoleTokenProvider: stscreds.StdinTokenProvider,
+		})
 	}
-	log.Debug("building session from profile")
-	return newSessionFromProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.StringValue(sess.Config.Region) == "" {
+		return nil, MissingRegionErr
+	}
+
+	if cfg.RoleArn == "" || aws.BoolValue(cfg.NoSign) {
+		return sess, nil
+	}
+
+	log.Debugf("assuming role %s", cfg.RoleArn)
+	creds := stscreds.NewCredentials(sess, cfg.RoleArn, func(p *stscreds.AssumeRoleProvider) {
+		if cfg.ExternalId != "" {
+			p.ExternalID = aws.String(cfg.ExternalId)
+		}
+
+		if cfg.MfaSerial != "" {
+			p.SerialNumber = aws.String(cfg.MfaSerial)
+			p.TokenProvider = stscreds.StdinTokenProvider
+		}
+
+		if cfg.SessionDuration > 0 {
+			p.Duration = cfg.SessionDuration
+		}
+	})
+
+	return sess.Copy(aws.NewConfig().WithCredentials(creds)), nil
 }
 
-func newSessionFromProfile() *session.Session {
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
-		Config:  aws.Config{Region: aws.String(defaultRegion)},
-		Profile: profile,
-	}))
+// RDSConfig returns the config to build RDS clients with on top of the
+// session. It holds the endpoint override, which applies to RDS only so that
+// STS calls assuming the role still go to STS.
+func (c SessionConfig) RDSConfig() *aws.Config {
+	awsCfg := aws.NewConfig()
+	if c.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(c.Endpoint)
+	}
 
-	return sess
+	return awsCfg
 }
 
 func newKeySession(aki, sak, region, roleArn string) *session.Session {
@@ -3,39 +3,45 @@ package cluster
 import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
 // NewDBClusterInput contains the data to be used when creating the RDS cluster
 type NewDBClusterInput struct {
 	// Identifier for the RDS cluster
-	ClusterId string
+	ClusterId string `json:"cluster_id,omitempty"`
 	// Engine type
-	Engine string
+	Engine string `json:"engine,omitempty"`
 	// Engine version (optional)
-	EngineVersion string
+	EngineVersion string `json:"engine_version,omitempty"`
 	// User name for root DB user
-	MasterUsername string
+	MasterUsername string `json:"master_username,omitempty"`
 	// Password for root DB user
-	MasterUserPass string
+	MasterUserPass string `json:"master_user_pass,omitempty"`
 	// List of Security groups to attach to the RDS cluster
-	SecurityGroupIds []string
+	SecurityGroupIds []string `json:"security_group_ids,omitempty"`
 	// Name of the RDS Subnet Group to use
-	SubnetGroupName string
+	SubnetGroupName string `json:"subnet_group_name,omitempty"`
 	// Name of RDS Cluster Parameter Group to use (optional)
-	ParameterGroupName string
+	ParameterGroupName string `json:"parameter_group_name,omitempty"`
 	// List of Availability Zones in which to deploy the RDS cluster (optional)
-	AvailabilityZones []string
+	AvailabilityZones []string `json:"availability_zones,omitempty"`
 	// Length of time (in days) to retain backups (optional)
-	BackupRetentionPeriod int64
+	BackupRetentionPeriod int64 `json:"backup_retention_period,omitempty"`
 	// Whether the DB data should be encrypted at rest (optional)
-	StorageEncrypted bool
+	StorageEncrypted bool `json:"storage_encrypted,omitempty"`
+	// Whether RDS should refuse to delete the cluster. Left unchanged by
+	// updates when not set. (optional)
+	DeletionProtection *bool `json:"deletion_protection,omitempty"`
 }
 
-func CreateDBCluster(svc *rds.RDS, input NewDBClusterInput) (*rds.DBCluster, error) {
+func CreateDBCluster(svc rds_api.RDSAPI, input NewDBClusterInput) (*rds.DBCluster, error) {
 	clusterInput := NewCreateClusterInput(input)
 	clusterOutput, err := svc.CreateDBCluster(clusterInput)
 	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindCluster, input.ClusterId)
 		log.Warn(err)
 		return nil, err
 	}
@@ -80,5 +86,9 @@ func NewCreateClusterInput(input NewDBClusterInput) *rds.CreateDBClusterInput {
 		clusterInput.BackupRetentionPeriod = aws.Int64(input.BackupRetentionPeriod)
 	}
 
+	if input.DeletionProtection != nil {
+		clusterInput.DeletionProtection = aws.Bool(*input.DeletionProtection)
+	}
+
 	return clusterInput
 }
@@ -1,45 +1,87 @@
 package cluster
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
-func DeleteDBCluster(svc *rds.RDS, clusterId string) error {
+var (
+	FinalSnapshotRequiredErr error
+)
+
+func init() {
+	FinalSnapshotRequiredErr = errors.New("refusing to delete without a final snapshot unless forced")
+}
+
+// DeleteOptions controls the final snapshot taken when a cluster is deleted.
+// The zero value takes a final snapshot with a generated identifier.
+type DeleteOptions struct {
+	// Identifier of the final snapshot, generated from the cluster id when
+	// empty (optional)
+	FinalSnapshotId string
+	// Whether to delete the cluster without a final snapshot. This is refused
+	// unless Force is also set.
+	SkipFinalSnapshot bool
+	// Whether to allow deleting without a final snapshot
+	Force bool
+}
+
+// FinalSnapshotId returns a final snapshot identifier for the cluster unique
+// to the second
+func FinalSnapshotId(clusterId string, now time.Time) string {
+	return fmt.Sprintf("%s-final-%s", clusterId, now.UTC().Format("20060102-150405"))
+}
+
+// NewDeleteDBClusterInput builds the delete call for the cluster, generating
+// a final snapshot identifier when needed
+func NewDeleteDBClusterInput(clusterId string, opts DeleteOptions) (*rds.DeleteDBClusterInput, error) {
 	input := &rds.DeleteDBClusterInput{
 		DBClusterIdentifier: aws.String(clusterId),
-		SkipFinalSnapshot:   aws.Bool(true),
 	}
 
-	_, err := svc.DeleteDBCluster(input)
-	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBClusterNotFoundFault:
-				log.Warn(rds.ErrCodeDBClusterNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeInvalidDBClusterStateFault:
-				log.Warn(rds.ErrCodeInvalidDBClusterStateFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDBClusterSnapshotAlreadyExistsFault:
-				log.Warn(rds.ErrCodeDBClusterSnapshotAlreadyExistsFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeSnapshotQuotaExceededFault:
-				log.Warn(rds.ErrCodeSnapshotQuotaExceededFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeInvalidDBClusterSnapshotStateFault:
-				log.Warn(rds.ErrCodeInvalidDBClusterSnapshotStateFault, aerr.Error())
-				return aerr
-			default:
-				log.Warn(aerr.Error())
-				return aerr
-			}
-		} else {
-			log.Warn(err.Error())
-			return err
+	if opts.SkipFinalSnapshot {
+		if !opts.Force {
+			return nil, fmt.Errorf("cluster %s: %w", clusterId, FinalSnapshotRequiredErr)
 		}
+		input.SkipFinalSnapshot = aws.Bool(true)
+		return input, nil
+	}
+
+	snapshotId := opts.FinalSnapshotId
+	if snapshotId == "" {
+		snapshotId = FinalSnapshotId(clusterId, time.Now())
+	}
+	input.SkipFinalSnapshot = aws.Bool(false)
+	input.FinalDBSnapshotIdentifier = aws.String(snapshotId)
+
+	return input, nil
+}
+
+func DeleteDBCluster(svc rds_api.RDSAPI, clusterId string, opts DeleteOptions) error {
+	input, err := NewDeleteDBClusterInput(clusterId, opts)
+	if err != nil {
+		log.Warn(err)
+		return err
+	}
+
+	if !aws.BoolValue(input.SkipFinalSnapshot) {
+		log.Infof("cluster %s: taking final snapshot %s", clusterId, aws.StringValue(input.FinalDBSnapshotIdentifier))
+	} else {
+		log.Warnf("cluster %s: deleting without a final snapshot", clusterId)
+	}
+
+	_, err = svc.DeleteDBCluster(input)
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindCluster, clusterId)
+		log.Warn(err)
+		return err
 	}
 
 	return nil
@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/tags"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListRequest narrows the clusters returned by ListDBClusters. Empty fields
+// match every cluster.
+type ListRequest struct {
+	// Engine of the clusters, e.g. aurora-mysql (optional)
+	Engine string
+	// Identifiers or ARNs of the clusters (optional)
+	ClusterIds []string
+	// Tags the clusters must carry (optional)
+	Tags tags.Selector
+}
+
+// Filters returns the RDS filters matching the request
+func (r ListRequest) Filters() []*rds.Filter {
+	filters := make([]*rds.Filter, 0)
+	if len(r.ClusterIds) > 0 {
+		filters = append(filters, &rds.Filter{
+			Name:   aws.String("db-cluster-id"),
+			Values: aws.StringSlice(r.ClusterIds),
+		})
+	}
+	if r.Engine != "" {
+		filters = append(filters, &rds.Filter{
+			Name:   aws.String("engine"),
+			Values: aws.StringSlice([]string{r.Engine}),
+		})
+	}
+
+	return filters
+}
+
+// ListDBClusters returns the clusters in the region matching req, following
+// every page of results
+func ListDBClusters(svc rds_api.RDSAPI, req ListRequest) ([]*rds.DBCluster, error) {
+	input := &rds.DescribeDBClustersInput{}
+	if filters := req.Filters(); len(filters) > 0 {
+		input.Filters = filters
+	}
+
+	clusters := make([]*rds.DBCluster, 0)
+	for {
+		result, err := svc.DescribeDBClusters(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindCluster, "")
+			log.Warn(err)
+			return nil, err
+		}
+
+		for _, c := range result.DBClusters {
+			ok, err := req.Tags.Select(
+				svc, rds_errors.KindCluster, aws.StringValue(c.DBClusterIdentifier), aws.StringValue(c.DBClusterArn),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				clusters = append(clusters, c)
+			}
+		}
+
+		if aws.StringValue(result.Marker) == "" {
+			break
+		}
+		input.Marker = result.Marker
+	}
+
+	return clusters, nil
+}
@@ -4,8 +4,9 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -14,31 +15,23 @@ var (
 )
 
 func init() {
-	ClusterNotFoundErr = errors.New("cluster not found")
+	ClusterNotFoundErr = rds_errors.New(rds_errors.NotFound, rds_errors.KindCluster, "")
 }
 
-func FindDBCluster(svc *rds.RDS, clusterId string) (*rds.DBCluster, error) {
+func FindDBCluster(svc rds_api.RDSAPI, clusterId string) (*rds.DBCluster, error) {
 	descClustersInput := &rds.DescribeDBClustersInput{
 		DBClusterIdentifier: aws.String(clusterId),
 	}
 
 	descClusterOuput, err := svc.DescribeDBClusters(descClustersInput)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBClusterNotFoundFault:
-				log.Info(rds.ErrCodeDBClusterNotFoundFault, aerr.Error())
-				return nil, ClusterNotFoundErr
-			default:
-				log.Warn(aerr.Error())
-				return nil, aerr
-			}
+		err = rds_errors.Wrap(err, rds_errors.KindCluster, clusterId)
+		if errors.Is(err, ClusterNotFoundErr) {
+			log.Info(err)
 		} else {
-			// Print the error, cast err to awserr.Error to get the Code and
-			// Message from an error.
-			log.Warn(err.Error())
-			return nil, aerr
+			log.Warn(err)
 		}
+		return nil, err
 	}
 
 	return descClusterOuput.DBClusters[0], nil
@@ -0,0 +1,137 @@
+package cluster
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/snapshot"
+	log "github.com/sirupsen/logrus"
+)
+
+// A restored cluster is described by the same NewDBClusterInput as a new one,
+// but only its identifier, network, parameter group and deletion protection
+// settings are used. The master user, storage encryption and backup retention
+// period are those of the snapshot or cluster restored from, and the engine
+// and engine version default to them.
+
+// NewRestoreFromSnapshotInput builds the call restoring the snapshot into the
+// cluster described by input
+func NewRestoreFromSnapshotInput(snapshotId string, input NewDBClusterInput) *rds.RestoreDBClusterFromSnapshotInput {
+	restoreInput := &rds.RestoreDBClusterFromSnapshotInput{
+		DBClusterIdentifier: aws.String(input.ClusterId),
+		SnapshotIdentifier:  aws.String(snapshotId),
+		Engine:              aws.String(input.Engine),
+		VpcSecurityGroupIds: aws.StringSlice(input.SecurityGroupIds),
+	}
+
+	if input.EngineVersion != "" {
+		restoreInput.EngineVersion = aws.String(input.EngineVersion)
+	}
+
+	if input.SubnetGroupName != "" {
+		restoreInput.DBSubnetGroupName = aws.String(input.SubnetGroupName)
+	}
+
+	if input.ParameterGroupName != "" {
+		restoreInput.DBClusterParameterGroupName = aws.String(input.ParameterGroupName)
+	}
+
+	if len(input.AvailabilityZones) > 0 {
+		restoreInput.AvailabilityZones = aws.StringSlice(input.AvailabilityZones)
+	}
+
+	if input.DeletionProtection != nil {
+		restoreInput.DeletionProtection = aws.Bool(*input.DeletionProtection)
+	}
+
+	return restoreInput
+}
+
+// RestoreFromSnapshot creates the cluster described by input from a cluster
+// snapshot, given by id or, when shared from another account, by ARN. The
+// engine and engine version are taken from the snapshot unless set.
+func RestoreFromSnapshot(svc rds_api.RDSAPI, snapshotId string, input NewDBClusterInput) (*rds.DBCluster, error) {
+	if input.Engine == "" {
+		s, err := snapshot.FindDBClusterSnapshot(svc, snapshotId)
+		if err != nil {
+			return nil, err
+		}
+
+		input.Engine = aws.StringValue(s.Engine)
+		if input.EngineVersion == "" {
+			input.EngineVersion = aws.StringValue(s.EngineVersion)
+		}
+	}
+
+	log.Infof("cluster %s: restoring from snapshot %s", input.ClusterId, snapshotId)
+
+	result, err := svc.RestoreDBClusterFromSnapshot(NewRestoreFromSnapshotInput(snapshotId, input))
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindCluster, input.ClusterId)
+		log.Warn(err)
+		return nil, err
+	}
+
+	return result.DBCluster, nil
+}
+
+// NewRestoreToPointInTimeInput builds the call restoring the source cluster as
+// it was at restoreTime into the cluster described by input. A zero
+// restoreTime restores to the latest restorable time.
+func NewRestoreToPointInTimeInput(
+	sourceClusterId string, restoreTime time.Time, input NewDBClusterInput,
+) *rds.RestoreDBClusterToPointInTimeInput {
+	restoreInput := &rds.RestoreDBClusterToPointInTimeInput{
+		DBClusterIdentifier:       aws.String(input.ClusterId),
+		SourceDBClusterIdentifier: aws.String(sourceClusterId),
+		VpcSecurityGroupIds:       aws.StringSlice(input.SecurityGroupIds),
+	}
+
+	if restoreTime.IsZero() {
+		restoreInput.UseLatestRestorableTime = aws.Bool(true)
+	} else {
+		restoreInput.RestoreToTime = aws.Time(restoreTime.UTC())
+	}
+
+	if input.SubnetGroupName != "" {
+		restoreInput.DBSubnetGroupName = aws.String(input.SubnetGroupName)
+	}
+
+	if input.ParameterGroupName != "" {
+		restoreInput.DBClusterParameterGroupName = aws.String(input.ParameterGroupName)
+	}
+
+	if input.DeletionProtection != nil {
+		restoreInput.DeletionProtection = aws.Bool(*input.DeletionProtection)
+	}
+
+	return restoreInput
+}
+
+// RestoreToPointInTime creates the cluster described by input from the source
+// cluster as it was at restoreTime, or at its latest restorable time when
+// restoreTime is zero. The engine and engine version are always those of the
+// source cluster.
+func RestoreToPointInTime(
+	svc rds_api.RDSAPI, sourceClusterId string, restoreTime time.Time, input NewDBClusterInput,
+) (*rds.DBCluster, error) {
+	if restoreTime.IsZero() {
+		log.Infof("cluster %s: restoring %s to its latest restorable time", input.ClusterId, sourceClusterId)
+	} else {
+		log.Infof(
+			"cluster %s: restoring %s to %s", input.ClusterId, sourceClusterId, restoreTime.UTC().Format(time.RFC3339),
+		)
+	}
+
+	result, err := svc.RestoreDBClusterToPointInTime(NewRestoreToPointInTimeInput(sourceClusterId, restoreTime, input))
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindCluster, input.ClusterId)
+		log.Warn(err)
+		return nil, err
+	}
+
+	return result.DBCluster, nil
+}
@@ -2,17 +2,81 @@ package cluster
 
 import (
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
 type UpdateDBClusterRequest struct {
-	cluster            *rds.DBCluster
-	engineVersion      *string
-	masterUserPass     *string
-	securityGroupIds   []*string
-	parameterGroupName *string
+	cluster               *rds.DBCluster
+	engineVersion         *string
+	masterUserPass        *string
+	securityGroupIds      []*string
+	parameterGroupName    *string
+	backupRetentionPeriod *int64
+	deletionProtection    *bool
+}
+
+// NewUpdateDBClusterRequest builds an UpdateDBClusterRequest which moves the
+// cluster to the desired state. The returned bool is false when the cluster
+// already matches the desired state.
+func NewUpdateDBClusterRequest(c *rds.DBCluster, desired NewDBClusterInput) (*UpdateDBClusterRequest, bool) {
+	req := &UpdateDBClusterRequest{}
+	req.SetCluster(c)
+	changed := false
+
+	if desired.EngineVersion != "" && desired.EngineVersion != aws.StringValue(c.EngineVersion) {
+		req.SetEngineVersion(desired.EngineVersion)
+		changed = true
+	}
+
+	// like the other fields, no security groups leaves them unchanged
+	if len(desired.SecurityGroupIds) > 0 && !SameSecurityGroups(c, desired.SecurityGroupIds) {
+		req.SetSecurityGroupIds(desired.SecurityGroupIds)
+		changed = true
+	}
+
+	if desired.ParameterGroupName != "" &&
+		desired.ParameterGroupName != aws.StringValue(c.DBClusterParameterGroup) {
+		req.SetParameterGroupName(desired.ParameterGroupName)
+		changed = true
+	}
+
+	if desired.BackupRetentionPeriod > 0 &&
+		desired.BackupRetentionPeriod != aws.Int64Value(c.BackupRetentionPeriod) {
+		req.SetBackupRetentionPeriod(desired.BackupRetentionPeriod)
+		changed = true
+	}
+
+	if desired.DeletionProtection != nil &&
+		*desired.DeletionProtection != aws.BoolValue(c.DeletionProtection) {
+		req.SetDeletionProtection(*desired.DeletionProtection)
+		changed = true
+	}
+
+	return req, changed
+}
+
+// SameSecurityGroups reports whether the cluster is attached to exactly the given
+// security groups
+func SameSecurityGroups(c *rds.DBCluster, securityGroupIds []string) bool {
+	current := make(map[string]bool)
+	for _, g := range c.VpcSecurityGroups {
+		current[aws.StringValue(g.VpcSecurityGroupId)] = true
+	}
+
+	if len(current) != len(securityGroupIds) {
+		return false
+	}
+
+	for _, id := range securityGroupIds {
+		if !current[id] {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (u *UpdateDBClusterRequest) SetCluster(v *rds.DBCluster) *UpdateDBClusterRequest {
@@ -45,15 +109,28 @@ func (u *UpdateDBClusterRequest) SetParameterGroupName(v string) *UpdateDBCluste
 	return u
 }
 
-func UpdateDBCluster(svc *rds.RDS, req *UpdateDBClusterRequest) (*rds.DBCluster, error) {
+func (u *UpdateDBClusterRequest) SetBackupRetentionPeriod(v int64) *UpdateDBClusterRequest {
+	u.backupRetentionPeriod = aws.Int64(v)
+	return u
+}
+
+func (u *UpdateDBClusterRequest) SetDeletionProtection(v bool) *UpdateDBClusterRequest {
+	u.deletionProtection = aws.Bool(v)
+	return u
+}
+
+func UpdateDBCluster(svc rds_api.RDSAPI, req *UpdateDBClusterRequest) (*rds.DBCluster, error) {
 	input := &rds.ModifyDBClusterInput{
 		ApplyImmediately:            aws.Bool(true),
 		DBClusterIdentifier:         req.cluster.DBClusterIdentifier,
 		VpcSecurityGroupIds:         req.securityGroupIds,
 		DBClusterParameterGroupName: req.parameterGroupName,
+		BackupRetentionPeriod:       req.backupRetentionPeriod,
+		DeletionProtection:          req.deletionProtection,
 	}
 
-	if *req.cluster.EngineVersion != *req.engineVersion {
+	if req.engineVersion != nil &&
+		aws.StringValue(req.cluster.EngineVersion) != *req.engineVersion {
 		input.EngineVersion = req.engineVersion
 	}
 
@@ -63,49 +140,9 @@ func UpdateDBCluster(svc *rds.RDS, req *UpdateDBClusterRequest) (*rds.DBCluster,
 
 	result, err := svc.ModifyDBCluster(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBClusterNotFoundFault:
-				log.Warn(rds.ErrCodeDBClusterNotFoundFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeInvalidDBClusterStateFault:
-				log.Warn(rds.ErrCodeInvalidDBClusterStateFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeStorageQuotaExceededFault:
-				log.Warn(rds.ErrCodeStorageQuotaExceededFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeDBSubnetGroupNotFoundFault:
-				log.Warn(rds.ErrCodeDBSubnetGroupNotFoundFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeInvalidVPCNetworkStateFault:
-				log.Warn(rds.ErrCodeInvalidVPCNetworkStateFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeInvalidDBSubnetGroupStateFault:
-				log.Warn(rds.ErrCodeInvalidDBSubnetGroupStateFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeInvalidSubnet:
-				log.Warn(rds.ErrCodeInvalidSubnet, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeDBClusterParameterGroupNotFoundFault:
-				log.Warn(rds.ErrCodeDBClusterParameterGroupNotFoundFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeInvalidDBSecurityGroupStateFault:
-				log.Warn(rds.ErrCodeInvalidDBSecurityGroupStateFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeInvalidDBInstanceStateFault:
-				log.Warn(rds.ErrCodeInvalidDBInstanceStateFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeDBClusterAlreadyExistsFault:
-				log.Warn(rds.ErrCodeDBClusterAlreadyExistsFault, aerr.Error())
-				return nil, aerr
-			default:
-				log.Warn(aerr.Error())
-				return nil, aerr
-			}
-		} else {
-			log.Warn(err)
-			return nil, err
-		}
+		err = rds_errors.Wrap(err, rds_errors.KindCluster, aws.StringValue(req.cluster.DBClusterIdentifier))
+		log.Warn(err)
+		return nil, err
 	}
 
 	return result.DBCluster, nil
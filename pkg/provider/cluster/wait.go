@@ -0,0 +1,163 @@
+package cluster
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/wait"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	clusterStatusPath = "DBClusters[].Status"
+
+	statusAvailable = "available"
+)
+
+// WaitUntilDBClusterAvailable blocks until the cluster reports the available
+// status. It fails early if the cluster enters a status it can not become
+// available from.
+func WaitUntilDBClusterAvailable(svc rds_api.RDSAPI, clusterId string, opts wait.Options) error {
+	ctx, cancel := opts.Context()
+	defer cancel()
+
+	w := newClusterWaiter(ctx, svc, "WaitUntilDBClusterAvailable", clusterId, []request.WaiterAcceptor{
+		{
+			State:   request.SuccessWaiterState,
+			Matcher: request.PathAllWaiterMatch, Argument: clusterStatusPath,
+			Expected: "available",
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: clusterStatusPath,
+			Expected: "deleting",
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: clusterStatusPath,
+			Expected: "failed",
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: clusterStatusPath,
+			Expected: "inaccessible-encryption-credentials",
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: clusterStatusPath,
+			Expected: "incompatible-restore",
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: clusterStatusPath,
+			Expected: "incompatible-parameters",
+		},
+	})
+	w.ApplyOptions(opts.WaiterOptions(clusterId, clusterStatusPath)...)
+
+	return w.WaitWithContext(ctx)
+}
+
+// WaitUntilDBClusterModified blocks until a modification of the cluster has
+// been applied. The cluster stays available until RDS starts applying the
+// modification, so it is given wait.DefaultStartChecks checks to leave the
+// available status before the modification is taken to have been applied
+// without a status change. Otherwise the wait lasts until the cluster is
+// available again.
+func WaitUntilDBClusterModified(svc rds_api.RDSAPI, clusterId string, opts wait.Options) error {
+	started, err := opts.Poll(clusterId, wait.DefaultStartChecks, func() (string, bool, error) {
+		c, err := FindDBCluster(svc, clusterId)
+		if err != nil {
+			return "", false, err
+		}
+
+		status := aws.StringValue(c.Status)
+		return status, status != statusAvailable, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !started {
+		log.Infof("cluster %s: modification applied without a status change", clusterId)
+		return nil
+	}
+
+	return WaitUntilDBClusterAvailable(svc, clusterId, opts)
+}
+
+// WaitUntilDBClusterDeleted blocks until the cluster no longer exists
+func WaitUntilDBClusterDeleted(svc rds_api.RDSAPI, clusterId string, opts wait.Options) error {
+	ctx, cancel := opts.Context()
+	defer cancel()
+
+	w := newClusterWaiter(ctx, svc, "WaitUntilDBClusterDeleted", clusterId, []request.WaiterAcceptor{
+		{
+			State:    request.SuccessWaiterState,
+			Matcher:  request.ErrorWaiterMatch,
+			Expected: rds.ErrCodeDBClusterNotFoundFault,
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: clusterStatusPath,
+			Expected: "creating",
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: clusterStatusPath,
+			Expected: "modifying",
+		},
+	})
+	w.ApplyOptions(opts.WaiterOptions(clusterId, clusterStatusPath)...)
+
+	return w.WaitWithContext(ctx)
+}
+
+func newClusterWaiter(
+	ctx aws.Context, svc rds_api.RDSAPI, name, clusterId string, acceptors []request.WaiterAcceptor,
+) request.Waiter {
+	return request.Waiter{
+		Name:        name,
+		MaxAttempts: 60,
+		Delay:       request.ConstantWaiterDelay(30 * time.Second),
+		Acceptors:   acceptors,
+		NewRequest: func(opts []request.Option) (*request.Request, error) {
+			req, _ := svc.DescribeDBClustersRequest(&rds.DescribeDBClustersInput{
+				DBClusterIdentifier: aws.String(clusterId),
+			})
+			req.SetContext(ctx)
+			req.ApplyOptions(opts...)
+			return req, nil
+		},
+	}
+}
+
+// CreateDBClusterAndWait creates the cluster and blocks until it is available
+func CreateDBClusterAndWait(svc rds_api.RDSAPI, input NewDBClusterInput, opts wait.Options) (*rds.DBCluster, error) {
+	_, err := CreateDBCluster(svc, input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = WaitUntilDBClusterAvailable(svc, input.ClusterId, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return FindDBCluster(svc, input.ClusterId)
+}
+
+// DeleteDBClusterAndWait deletes the cluster and blocks until it no longer exists
+func DeleteDBClusterAndWait(
+	svc rds_api.RDSAPI, clusterId string, deleteOpts DeleteOptions, opts wait.Options,
+) error {
+	err := DeleteDBCluster(svc, clusterId, deleteOpts)
+	if err != nil {
+		return err
+	}
+
+	return WaitUntilDBClusterDeleted(svc, clusterId, opts)
+}
@@ -0,0 +1,53 @@
+package cluster_parameter_group
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/tags"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListRequest narrows the groups returned by ListDBClusterParameterGroups. RDS
+// does not filter parameter groups, so only tags are supported.
+type ListRequest struct {
+	// Tags the groups must carry (optional)
+	Tags tags.Selector
+}
+
+// ListDBClusterParameterGroups returns the DB cluster parameter groups in the
+// region matching req, following every page of results
+func ListDBClusterParameterGroups(svc rds_api.RDSAPI, req ListRequest) ([]*rds.DBClusterParameterGroup, error) {
+	input := &rds.DescribeDBClusterParameterGroupsInput{}
+
+	groups := make([]*rds.DBClusterParameterGroup, 0)
+	for {
+		result, err := svc.DescribeDBClusterParameterGroups(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindClusterParameterGroup, "")
+			log.Warn(err)
+			return nil, err
+		}
+
+		for _, group := range result.DBClusterParameterGroups {
+			ok, err := req.Tags.Select(
+				svc, rds_errors.KindClusterParameterGroup,
+				aws.StringValue(group.DBClusterParameterGroupName), aws.StringValue(group.DBClusterParameterGroupArn),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				groups = append(groups, group)
+			}
+		}
+
+		if aws.StringValue(result.Marker) == "" {
+			break
+		}
+		input.Marker = result.Marker
+	}
+
+	return groups, nil
+}
@@ -2,16 +2,28 @@ package cluster_parameter_group
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
 const (
-	Immediate applyMethod = "immediate"
-	String    valType     = "string"
+	Immediate     applyMethod = "immediate"
+	PendingReboot applyMethod = "pending-reboot"
+
+	String  valType = "string"
+	Integer valType = "integer"
+	Float   valType = "float"
+	Boolean valType = "boolean"
+	List    valType = "list"
+
+	// maxModifyParameters is the most parameters RDS accepts in one modify call
+	maxModifyParameters = 20
 )
 
 var (
@@ -19,79 +31,113 @@ var (
 )
 
 func init() {
-	NotFoundErr = errors.New("db parameter group not found")
+	NotFoundErr = rds_errors.New(rds_errors.NotFound, rds_errors.KindClusterParameterGroup, "")
 }
 
-func FindDBClusterParameterGroup(svc *rds.RDS, paramGroupName string) (*rds.DBClusterParameterGroup, error) {
+func FindDBClusterParameterGroup(svc rds_api.RDSAPI, paramGroupName string) (*rds.DBClusterParameterGroup, error) {
 	input := &rds.DescribeDBClusterParameterGroupsInput{
 		DBClusterParameterGroupName: aws.String(paramGroupName),
 	}
 
 	result, err := svc.DescribeDBClusterParameterGroups(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBParameterGroupNotFoundFault:
-				log.Debug(rds.ErrCodeDBParameterGroupNotFoundFault, aerr.Error())
-				return nil, NotFoundErr
-			default:
-				log.Warn(aerr.Error())
-				return nil, aerr
-			}
+		err = rds_errors.Wrap(err, rds_errors.KindClusterParameterGroup, paramGroupName)
+		if errors.Is(err, NotFoundErr) {
+			log.Info(err)
 		} else {
-			log.Warn(err.Error())
-			return nil, err
+			log.Warn(err)
 		}
+		return nil, err
 	}
 	return result.DBClusterParameterGroups[0], nil
 }
 
+// FindDBClusterParameters returns the user modified parameters of the cluster
+// parameter group
+func FindDBClusterParameters(svc rds_api.RDSAPI, paramGroupName string) ([]*rds.Parameter, error) {
+	input := &rds.DescribeDBClusterParametersInput{
+		DBClusterParameterGroupName: aws.String(paramGroupName),
+		Source:                      aws.String("user"),
+	}
+
+	params := make([]*rds.Parameter, 0)
+	for {
+		result, err := svc.DescribeDBClusterParameters(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindClusterParameterGroup, paramGroupName)
+			if errors.Is(err, NotFoundErr) {
+				log.Info(err)
+			} else {
+				log.Warn(err)
+			}
+			return nil, err
+		}
+
+		params = append(params, result.Parameters...)
+		if result.Marker == nil || *result.Marker == "" {
+			break
+		}
+		input.Marker = result.Marker
+	}
+
+	return params, nil
+}
+
 type CreateRequest struct {
-	family      string
-	name        string
-	description string
+	Family      string `json:"family,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Parameters to set once the group is created (optional)
+	Parameters []Param `json:"parameters,omitempty"`
 }
 
 func (r *CreateRequest) SetFamily(v string) *CreateRequest {
-	r.family = v
+	r.Family = v
 	return r
 }
 
 func (r *CreateRequest) SetName(v string) *CreateRequest {
-	r.name = v
+	r.Name = v
 	return r
 }
 
 func (r *CreateRequest) SetDescription(v string) *CreateRequest {
-	r.description = v
+	r.Description = v
 	return r
 }
 
-func CreateDBClusterParameterGroup(svc *rds.RDS, req CreateRequest) (
+func (r *CreateRequest) SetParameters(v []Param) *CreateRequest {
+	r.Parameters = v
+	return r
+}
+
+func CreateDBClusterParameterGroup(svc rds_api.RDSAPI, req CreateRequest) (
 	*rds.DBClusterParameterGroup, error,
 ) {
+	err := req.ValidateParameters()
+	if err != nil {
+		return nil, err
+	}
+
 	input := &rds.CreateDBClusterParameterGroupInput{
-		DBParameterGroupFamily:      aws.String(req.family),
-		DBClusterParameterGroupName: aws.String(req.name),
-		Description:                 aws.String(req.description),
+		DBParameterGroupFamily:      aws.String(req.Family),
+		DBClusterParameterGroupName: aws.String(req.Name),
+		Description:                 aws.String(req.Description),
 	}
 
 	result, err := svc.CreateDBClusterParameterGroup(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBParameterGroupQuotaExceededFault:
-				log.Warn(rds.ErrCodeDBParameterGroupQuotaExceededFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeDBParameterGroupAlreadyExistsFault:
-				log.Warn(rds.ErrCodeDBParameterGroupAlreadyExistsFault, aerr.Error())
-				return nil, aerr
-			default:
-				log.Warn(aerr.Error())
-				return nil, aerr
-			}
-		} else {
-			log.Warn(err.Error())
+		err = rds_errors.Wrap(err, rds_errors.KindClusterParameterGroup, req.Name)
+		log.Warn(err)
+		return nil, err
+	}
+
+	if len(req.Parameters) > 0 {
+		updateReq := UpdateRequest{}
+		updateReq.SetName(req.Name).SetClusterParameters(req.Parameters)
+
+		err = UpdateDBClusterParameterGroup(svc, updateReq)
+		if err != nil {
 			return nil, err
 		}
 	}
@@ -99,9 +145,21 @@ func CreateDBClusterParameterGroup(svc *rds.RDS, req CreateRequest) (
 	return result.DBClusterParameterGroup, nil
 }
 
+// ValidateParameters checks every parameter of the request
+func (r CreateRequest) ValidateParameters() error {
+	for _, p := range r.Parameters {
+		err := p.Validate()
+		if err != nil {
+			return fmt.Errorf("cluster parameter group %s: %w", r.Name, err)
+		}
+	}
+
+	return nil
+}
+
 type UpdateRequest struct {
 	name       string
-	parameters []*rds.Parameter
+	parameters []Param
 }
 
 func (r *UpdateRequest) SetName(v string) *UpdateRequest {
@@ -110,19 +168,7 @@ func (r *UpdateRequest) SetName(v string) *UpdateRequest {
 }
 
 func (r *UpdateRequest) SetClusterParameters(params []Param) *UpdateRequest {
-	awsParams := make([]*rds.Parameter, 0)
-	for _, p := range params {
-		switch p.ValueType {
-		case String:
-			awsParams = append(awsParams, &rds.Parameter{
-				ApplyMethod:    aws.String(string(p.Apply)),
-				ParameterName:  aws.String(p.Name),
-				ParameterValue: aws.String(p.Value.(string)),
-			})
-		}
-	}
-
-	r.parameters = awsParams
+	r.parameters = params
 	return r
 }
 
@@ -130,72 +176,165 @@ type applyMethod string
 type valType string
 
 type Param struct {
-	Apply     applyMethod
-	Name      string
-	Value     interface{}
-	ValueType valType
-}
-
-func UpdateDBClusterParameterGroup(svc *rds.RDS, req UpdateRequest) error {
-	input := &rds.ModifyDBClusterParameterGroupInput{
-		DBClusterParameterGroupName: aws.String(req.name),
-		Parameters:                  req.parameters,
-		//ClusterParameters: []*rds.ClusterParameter{
-		//  {
-		//    ApplyMethod:    aws.String("immediate"),
-		//    ClusterParameterName:  aws.String("time_zone"),
-		//    ClusterParameterValue: aws.String("America/Phoenix"),
-		//  },
-		//},
-	}
-
-	result, err := svc.ModifyDBClusterParameterGroup(input)
+	Apply     applyMethod `json:"apply,omitempty"`
+	Name      string      `json:"name,omitempty"`
+	Value     interface{} `json:"value,omitempty"`
+	ValueType valType     `json:"value_type,omitempty"`
+}
+
+// Validate checks that the parameter is named, is applied with a known method
+// and has a value of its value type
+func (p Param) Validate() error {
+	if p.Name == "" {
+		return errors.New("parameter name is required")
+	}
+
+	switch p.Apply {
+	case Immediate, PendingReboot:
+	default:
+		return fmt.Errorf(
+			"parameter %s: invalid apply %q, expected %s or %s", p.Name, p.Apply, Immediate, PendingReboot,
+		)
+	}
+
+	value, err := p.StringValue()
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBParameterGroupNotFoundFault:
-				log.Warn(rds.ErrCodeDBParameterGroupNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeInvalidDBParameterGroupStateFault:
-				log.Warn(rds.ErrCodeInvalidDBParameterGroupStateFault, aerr.Error())
-				return aerr
-			default:
-				log.Warn(aerr.Error())
-				return aerr
-			}
-		} else {
-			log.Warn(err.Error())
+		return err
+	}
+
+	switch p.ValueType {
+	case String, List, Boolean:
+	case Integer:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case Float:
+		_, err = strconv.ParseFloat(value, 64)
+	case "":
+		return fmt.Errorf("parameter %s: value_type is required", p.Name)
+	default:
+		return fmt.Errorf(
+			"parameter %s: unknown value_type %q, expected one of %s, %s, %s, %s or %s",
+			p.Name, p.ValueType, String, Integer, Float, Boolean, List,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("parameter %s: %v is not a valid %s", p.Name, p.Value, p.ValueType)
+	}
+
+	return nil
+}
+
+// StringValue returns the value of the parameter in the form RDS takes and
+// reports it. Booleans become 1 or 0 and numbers are written out in full.
+func (p Param) StringValue() (string, error) {
+	switch v := p.Value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case nil:
+		return "", fmt.Errorf("parameter %s: value is required", p.Name)
+	default:
+		return "", fmt.Errorf("parameter %s: unsupported value %v of type %T", p.Name, v, v)
+	}
+}
+
+// ChangedParameters returns the desired parameters whose values differ from the
+// current parameters of the group
+func ChangedParameters(current []*rds.Parameter, desired []Param) []Param {
+	currentValues := make(map[string]string)
+	for _, p := range current {
+		if p.ParameterName == nil || p.ParameterValue == nil {
+			continue
+		}
+		currentValues[*p.ParameterName] = *p.ParameterValue
+	}
+
+	changed := make([]Param, 0)
+	for _, p := range desired {
+		value, ok := currentValues[p.Name]
+		desiredValue, err := p.StringValue()
+		if !ok || err != nil || value != desiredValue {
+			changed = append(changed, p)
+		}
+	}
+
+	return changed
+}
+
+// UpdateDBClusterParameterGroup sets the parameters of the request, at most
+// maxModifyParameters per call
+func UpdateDBClusterParameterGroup(svc rds_api.RDSAPI, req UpdateRequest) error {
+	inputs, err := NewModifyDBClusterParameterGroupInputs(req)
+	if err != nil {
+		return fmt.Errorf("cluster parameter group %s: %w", req.name, err)
+	}
+
+	for _, input := range inputs {
+		result, err := svc.ModifyDBClusterParameterGroup(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindClusterParameterGroup, req.name)
+			log.Warn(err)
 			return err
 		}
+		log.Debug(result)
 	}
-	log.Debug(result)
 
 	return nil
 }
 
-func DeleteDBClusterParameterGroup(svc *rds.RDS, groupName string) error {
+// NewModifyDBClusterParameterGroupInputs splits the parameters of the request
+// into inputs of at most maxModifyParameters
+func NewModifyDBClusterParameterGroupInputs(req UpdateRequest) ([]*rds.ModifyDBClusterParameterGroupInput, error) {
+	inputs := make([]*rds.ModifyDBClusterParameterGroupInput, 0)
+	for start := 0; start < len(req.parameters); start += maxModifyParameters {
+		end := start + maxModifyParameters
+		if end > len(req.parameters) {
+			end = len(req.parameters)
+		}
+
+		awsParams := make([]*rds.Parameter, 0, end-start)
+		for _, p := range req.parameters[start:end] {
+			err := p.Validate()
+			if err != nil {
+				return nil, err
+			}
+
+			value, _ := p.StringValue()
+			awsParams = append(awsParams, &rds.Parameter{
+				ApplyMethod:    aws.String(string(p.Apply)),
+				ParameterName:  aws.String(p.Name),
+				ParameterValue: aws.String(value),
+			})
+		}
+
+		inputs = append(inputs, &rds.ModifyDBClusterParameterGroupInput{
+			DBClusterParameterGroupName: aws.String(req.name),
+			Parameters:                  awsParams,
+		})
+	}
+
+	return inputs, nil
+}
+
+func DeleteDBClusterParameterGroup(svc rds_api.RDSAPI, groupName string) error {
 	input := &rds.DeleteDBClusterParameterGroupInput{
 		DBClusterParameterGroupName: aws.String(groupName),
 	}
 
 	result, err := svc.DeleteDBClusterParameterGroup(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeInvalidDBParameterGroupStateFault:
-				log.Warn(rds.ErrCodeInvalidDBParameterGroupStateFault, aerr.Error())
-				return err
-			case rds.ErrCodeDBClusterParameterGroupNotFoundFault:
-				log.Warn(rds.ErrCodeDBClusterParameterGroupNotFoundFault, aerr.Error())
-				return NotFoundErr
-			default:
-				log.Warn(aerr.Error())
-				return err
-			}
-		} else {
-			log.Warn(err.Error())
-			return err
-		}
+		err = rds_errors.Wrap(err, rds_errors.KindClusterParameterGroup, groupName)
+		log.Warn(err)
+		return err
 	}
 	log.Debug(result)
 
@@ -0,0 +1,101 @@
+package database_restore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Position is how far a file has been restored
+type Position struct {
+	File string `json:"file"`
+	// Offset of the byte following the last restored statement
+	Offset int64 `json:"offset"`
+	// Line the parser is on at Offset, counting from 1
+	Line int `json:"line"`
+	// Number of statements of the file restored
+	Statement int `json:"statement"`
+	// Statement delimiter in effect at Offset
+	Delimiter string `json:"delimiter"`
+	// Index of the file being restored among those included by the source
+	// command at Offset, when the file is including another
+	Include int `json:"include,omitempty"`
+}
+
+// Checkpoint records how far a restore got. Restores resume from the
+// checkpoint in their state file.
+type Checkpoint struct {
+	// Name of the source restored
+	Source string `json:"source"`
+	// Position in each file being restored, starting with the file given to
+	// the restore and followed by the files it includes, down to the one being
+	// restored. The position in an including file is the one of the source
+	// command including the next file.
+	Positions []Position `json:"positions"`
+	// Number of statements gone through across every file
+	Statements int       `json:"statements"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// LoadCheckpoint reads a state file, the checkpoint is nil when the file does
+// not exist
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Checkpoint{}
+	err = json.Unmarshal(content, c)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(c.Positions) == 0 {
+		return nil, fmt.Errorf("%s: checkpoint has no positions", path)
+	}
+
+	return c, nil
+}
+
+// SaveCheckpoint writes the checkpoint to a state file. The file is replaced
+// as a whole so a restore interrupted while saving keeps the previous
+// checkpoint.
+func SaveCheckpoint(path string, c Checkpoint) error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// RemoveCheckpoint deletes a state file once its restore has completed
+func RemoveCheckpoint(path string) error {
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
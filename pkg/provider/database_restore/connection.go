@@ -0,0 +1,284 @@
+package database_restore
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/go-sql-driver/mysql"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultHost = "127.0.0.1"
+	DefaultPort = 3306
+
+	// TLS modes
+	TLSDisabled   = "false"
+	TLSRequired   = "true"
+	TLSSkipVerify = "skip-verify"
+
+	// name the TLS config built from TLSCAFile is registered under
+	customTLSConfig = "rds_provider"
+)
+
+// ConnectionConfig describes the MySQL server statements are restored to.
+// Fields left empty are taken from DSN when it is set, and otherwise default
+// to a server on localhost.
+type ConnectionConfig struct {
+	// Data source name in the form of the MySQL driver,
+	// e.g. user:password@tcp(host:3306)/database (optional)
+	DSN string
+	// Cluster whose writer endpoint and port are connected to, in place of
+	// Host and Port (optional)
+	ClusterId string
+	Host      string
+	Port      int
+	User      string
+	// The password is given directly, read from the environment variable
+	// PasswordEnv or read from the file PasswordFile. At most one is set.
+	Password     string
+	PasswordEnv  string
+	PasswordFile string
+	// Database selected once connected (optional)
+	Database string
+	// One of TLSDisabled, TLSRequired or TLSSkipVerify
+	TLS string
+	// PEM file of the certificate authorities to verify the server with, e.g.
+	// the RDS CA bundle. Implies TLSRequired. (optional)
+	TLSCAFile string
+	// Dial timeout (optional)
+	Timeout time.Duration
+	// Read and write timeouts of each statement (optional)
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// Validate checks that the config selects a single server and password source
+func (c ConnectionConfig) Validate() error {
+	if c.ClusterId != "" && c.Host != "" {
+		return errors.New("a cluster and a host can not both be given")
+	}
+
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid port %d", c.Port)
+	}
+
+	sources := 0
+	for _, source := range []string{c.Password, c.PasswordEnv, c.PasswordFile} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return errors.New("only one of a password, password environment variable or password file can be given")
+	}
+
+	switch c.TLS {
+	case "", TLSDisabled, TLSRequired, TLSSkipVerify:
+	default:
+		return fmt.Errorf(
+			"unknown TLS mode %q, expected %s, %s or %s", c.TLS, TLSDisabled, TLSRequired, TLSSkipVerify,
+		)
+	}
+
+	if c.TLSCAFile != "" && (c.TLS == TLSDisabled || c.TLS == TLSSkipVerify) {
+		return fmt.Errorf("a TLS CA file can not be used with TLS mode %s", c.TLS)
+	}
+
+	if c.Timeout < 0 || c.ReadTimeout < 0 || c.WriteTimeout < 0 {
+		return errors.New("timeouts can not be negative")
+	}
+
+	return nil
+}
+
+// ResolveCluster returns c with Host and Port set to the writer endpoint of
+// its cluster in place of the cluster. c is returned as is when it names no
+// cluster.
+func ResolveCluster(svc rds_api.RDSAPI, c ConnectionConfig) (ConnectionConfig, error) {
+	if c.ClusterId == "" {
+		return c, nil
+	}
+
+	err := c.Validate()
+	if err != nil {
+		return c, err
+	}
+
+	dbCluster, err := cluster.FindDBCluster(svc, c.ClusterId)
+	if err != nil {
+		return c, err
+	}
+
+	endpoint := aws.StringValue(dbCluster.Endpoint)
+	if endpoint == "" {
+		return c, fmt.Errorf("cluster %s has no endpoint yet", c.ClusterId)
+	}
+
+	log.Infof("cluster %s: using writer endpoint %s", c.ClusterId, endpoint)
+	c.ClusterId = ""
+	c.Host = endpoint
+	if c.Port == 0 {
+		c.Port = int(aws.Int64Value(dbCluster.Port))
+	}
+
+	return c, nil
+}
+
+// MySQLConfig builds the config of the MySQL driver, reading the password
+// from its source. Clusters must have been resolved first.
+func (c ConnectionConfig) MySQLConfig() (*mysql.Config, error) {
+	err := c.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if c.ClusterId != "" {
+		return nil, fmt.Errorf("cluster %s has not been resolved to its endpoint", c.ClusterId)
+	}
+
+	cfg := mysql.NewConfig()
+	if c.DSN != "" {
+		cfg, err = mysql.ParseDSN(c.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DSN: %v", err)
+		}
+	}
+
+	if c.Host != "" || c.Port != 0 || cfg.Addr == "" {
+		host, port := DefaultHost, strconv.Itoa(DefaultPort)
+		if h, p, err := net.SplitHostPort(cfg.Addr); err == nil {
+			host, port = h, p
+		}
+		if c.Host != "" {
+			host = c.Host
+		}
+		if c.Port != 0 {
+			port = strconv.Itoa(c.Port)
+		}
+
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(host, port)
+	}
+
+	if c.User != "" {
+		cfg.User = c.User
+	}
+	if cfg.User == "" {
+		return nil, errors.New("a user is required")
+	}
+
+	password, err := c.password()
+	if err != nil {
+		return nil, err
+	}
+	if password != "" {
+		cfg.Passwd = password
+	}
+
+	if c.Database != "" {
+		cfg.DBName = c.Database
+	}
+
+	if c.TLS != "" {
+		cfg.TLSConfig = c.TLS
+	}
+	if c.TLSCAFile != "" {
+		host, _, _ := net.SplitHostPort(cfg.Addr)
+		err = registerCA(c.TLSCAFile, host)
+		if err != nil {
+			return nil, err
+		}
+		cfg.TLSConfig = customTLSConfig
+	}
+
+	if c.Timeout != 0 {
+		cfg.Timeout = c.Timeout
+	}
+	if c.ReadTimeout != 0 {
+		cfg.ReadTimeout = c.ReadTimeout
+	}
+	if c.WriteTimeout != 0 {
+		cfg.WriteTimeout = c.WriteTimeout
+	}
+
+	return cfg, nil
+}
+
+// Open resolves the cluster of c, when it names one, and connects to the
+// server. The connection is checked before it is returned.
+func Open(svc rds_api.RDSAPI, c ConnectionConfig) (*sql.DB, error) {
+	c, err := ResolveCluster(svc, c)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := c.MySQLConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("connecting to %s as %s", cfg.Addr, cfg.User)
+	err = db.Ping()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not connect to %s: %v", cfg.Addr, err)
+	}
+
+	return db, nil
+}
+
+// password reads the password from its source, it is empty when there is none
+func (c ConnectionConfig) password() (string, error) {
+	switch {
+	case c.PasswordEnv != "":
+		password, ok := os.LookupEnv(c.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("password environment variable %s is not set", c.PasswordEnv)
+		}
+		return password, nil
+	case c.PasswordFile != "":
+		content, err := ioutil.ReadFile(c.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("could not read password file: %v", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	default:
+		return c.Password, nil
+	}
+}
+
+// registerCA registers a TLS config verifying serverName with the
+// certificate authorities of caFile
+func registerCA(caFile, serverName string) error {
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return fmt.Errorf("could not read TLS CA file: %v", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("TLS CA file %s holds no PEM certificates", caFile)
+	}
+
+	return mysql.RegisterTLSConfig(customTLSConfig, &tls.Config{
+		RootCAs:    pool,
+		ServerName: serverName,
+	})
+}
@@ -0,0 +1,84 @@
+package database_restore
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxIncludeDepth is the number of nested source commands followed
+// when RestoreOptions sets no limit
+const DefaultMaxIncludeDepth = 16
+
+var (
+	IncludeCycleErr error
+	IncludeDepthErr error
+)
+
+func init() {
+	IncludeCycleErr = errors.New("include cycle")
+	IncludeDepthErr = errors.New("too many nested includes")
+}
+
+// Include is a file read by a restore along with the files it includes
+type Include struct {
+	File string `json:"file"`
+	// Line of the source command including the file, 0 for the files of the
+	// source
+	Line int `json:"line,omitempty"`
+	// Number of statements of the file gone through by the restore
+	Statements int        `json:"statements"`
+	Includes   []*Include `json:"includes,omitempty"`
+}
+
+// resolveInclude returns the files of the source included by a source
+// command of file. Relative paths are relative to the directory of file.
+// Paths holding *, ? or [ are patterns, in filepath.Match syntax, which
+// include the files they match in lexical order and must match at least one
+// file.
+func resolveInclude(s Source, file, include string) ([]string, error) {
+	path := include
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(file), path)
+	}
+	path = filepath.Clean(path)
+
+	if !strings.ContainsAny(path, "*?[") {
+		return []string{path}, nil
+	}
+
+	matches, err := s.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern %q: %v", include, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("include pattern %q matches no files", include)
+	}
+
+	return matches, nil
+}
+
+// includeChain returns the files being restored, followed by file, when file
+// is already being restored
+func includeChain(positions []Position, file string) []string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil
+	}
+
+	for i, pos := range positions {
+		including, err := filepath.Abs(pos.File)
+		if err != nil || including != abs {
+			continue
+		}
+
+		chain := make([]string, 0, len(positions)-i+1)
+		for _, p := range positions[i:] {
+			chain = append(chain, p.File)
+		}
+		return append(chain, file)
+	}
+
+	return nil
+}
@@ -0,0 +1,336 @@
+package database_restore
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DefaultDelimiter ends statements until a DELIMITER command changes it
+const DefaultDelimiter = ";"
+
+var (
+	delimiterCommand = regexp.MustCompile(`(?i)^delimiter[ \t]+(\S+)`)
+	sourceCommand    = regexp.MustCompile(`(?i)^(?:source[ \t]+|\\\.[ \t]*)(.+)$`)
+)
+
+type parserState int
+
+const (
+	stateCode parserState = iota
+	stateQuote
+	stateLineComment
+	stateBlockComment
+	// a /*! ... */ or /*+ ... */ comment, which MySQL executes or reads as
+	// optimizer hints and is kept in the statement
+	stateKeptComment
+)
+
+// Statement is a single statement read from a SQL file
+type Statement struct {
+	// Text of the statement without its delimiter or comments
+	Text string
+	// Line the statement starts on, counting from 1
+	Line int
+	// Offset of the first byte of the statement
+	Start int64
+	// Offset of the byte following the statement and its delimiter
+	Offset int64
+	// File to include when the statement is a source command
+	Source string
+}
+
+// Parser splits a SQL file into statements as the mysql client does. It reads
+// the file as it goes and understands quoted strings and identifiers,
+// --, # and /* */ comments, DELIMITER commands changing the statement
+// delimiter and source commands including other files.
+type Parser struct {
+	r         *bufio.Reader
+	delimiter string
+	// bytes read ahead of the parser
+	pending []byte
+	line    int
+	offset  int64
+}
+
+// NewParser returns a parser reading statements from r
+func NewParser(r io.Reader) *Parser {
+	return &Parser{
+		r:         bufio.NewReader(r),
+		delimiter: DefaultDelimiter,
+		line:      1,
+	}
+}
+
+// NewParserAt returns a parser resuming at pos. r must read the file from
+// pos.Offset on.
+func NewParserAt(r io.Reader, pos Position) *Parser {
+	p := NewParser(r)
+	p.offset = pos.Offset
+	if pos.Line > 0 {
+		p.line = pos.Line
+	}
+	if pos.Delimiter != "" {
+		p.delimiter = pos.Delimiter
+	}
+
+	return p
+}
+
+// Line returns the line the parser is on, counting from 1
+func (p *Parser) Line() int {
+	return p.line
+}
+
+// Delimiter returns the current statement delimiter
+func (p *Parser) Delimiter() string {
+	return p.delimiter
+}
+
+// Next returns the next statement, or io.EOF once there are none left. A
+// final statement without a delimiter is returned as is.
+func (p *Parser) Next() (Statement, error) {
+	buf := make([]byte, 0, 256)
+	state := stateCode
+	var quote byte
+	var prev byte
+	start := 0
+	var startOffset int64
+
+	for {
+		c, err := p.readByte()
+		if err == io.EOF {
+			switch state {
+			case stateQuote:
+				return Statement{}, fmt.Errorf("line %d: unterminated quoted string", start)
+			case stateBlockComment, stateKeptComment:
+				return Statement{}, fmt.Errorf("line %d: unterminated comment", p.line)
+			}
+
+			text := strings.TrimSpace(string(buf))
+			if text == "" {
+				return Statement{}, io.EOF
+			}
+			return Statement{Text: text, Line: start, Start: startOffset, Offset: p.offset}, nil
+		}
+		if err != nil {
+			return Statement{}, err
+		}
+
+		switch state {
+		case stateQuote:
+			buf = append(buf, c)
+			if c == '\\' && quote != '`' {
+				next, err := p.readByte()
+				if err == io.EOF {
+					return Statement{}, fmt.Errorf("line %d: unterminated quoted string", start)
+				}
+				if err != nil {
+					return Statement{}, err
+				}
+				buf = append(buf, next)
+			} else if c == quote {
+				state = stateCode
+			}
+			continue
+		case stateLineComment:
+			if c == '\n' {
+				buf = append(buf, c)
+				state = stateCode
+			}
+			continue
+		case stateBlockComment:
+			if prev == '*' && c == '/' {
+				// keep tokens on either side of the comment apart
+				buf = append(buf, ' ')
+				state = stateCode
+				c = 0
+			}
+			prev = c
+			continue
+		case stateKeptComment:
+			buf = append(buf, c)
+			if prev == '*' && c == '/' {
+				state = stateCode
+				c = 0
+			}
+			prev = c
+			continue
+		}
+
+		empty := len(bytes.TrimSpace(buf)) == 0
+		if empty && isSpace(c) {
+			buf = append(buf, c)
+			continue
+		}
+
+		if empty {
+			start = p.line
+			startOffset = p.offset - 1
+
+			stmt, ok, err := p.command(c)
+			if err != nil {
+				return Statement{}, err
+			}
+			if ok {
+				if stmt.Source != "" {
+					stmt.Line = start
+					stmt.Start = startOffset
+					return stmt, nil
+				}
+				buf = buf[:0]
+				continue
+			}
+		}
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			state = stateQuote
+			quote = c
+			buf = append(buf, c)
+			continue
+		case c == '#':
+			state = stateLineComment
+			continue
+		case c == '-' && p.startsLineComment():
+			state = stateLineComment
+			continue
+		case c == '/' && bytes.HasPrefix(p.peek(1), []byte("*")):
+			p.readByte()
+			prev = 0
+			next := p.peek(1)
+			if len(next) == 1 && (next[0] == '!' || next[0] == '+') {
+				state = stateKeptComment
+				buf = append(buf, '/', '*')
+				continue
+			}
+			state = stateBlockComment
+			continue
+		}
+
+		buf = append(buf, c)
+		if !bytes.HasSuffix(buf, []byte(p.delimiter)) {
+			continue
+		}
+
+		text := strings.TrimSpace(string(buf[:len(buf)-len(p.delimiter)]))
+		if text == "" {
+			buf = buf[:0]
+			continue
+		}
+		return Statement{Text: text, Line: start, Start: startOffset, Offset: p.offset}, nil
+	}
+}
+
+// command handles the mysql client commands a statement may start with. c is
+// the first byte of the statement, the rest of its line is looked at without
+// being read unless it holds a command. DELIMITER commands change the
+// delimiter, source commands are returned as statements.
+func (p *Parser) command(c byte) (Statement, bool, error) {
+	switch c {
+	case 'd', 'D', 's', 'S', '\\':
+	default:
+		return Statement{}, false, nil
+	}
+
+	rest := p.peekLine()
+	line := strings.TrimSpace(string(c) + string(rest))
+
+	if m := delimiterCommand.FindStringSubmatch(line); m != nil {
+		p.discard(len(rest))
+		p.delimiter = m[1]
+		return Statement{}, true, nil
+	}
+
+	if m := sourceCommand.FindStringSubmatch(line); m != nil {
+		p.discard(len(rest))
+		file := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m[1]), p.delimiter))
+		if file == "" {
+			return Statement{}, false, fmt.Errorf("line %d: source command without a file", p.line)
+		}
+		return Statement{Text: line, Source: file, Offset: p.offset}, true, nil
+	}
+
+	return Statement{}, false, nil
+}
+
+// startsLineComment reports whether the - just read starts a -- comment,
+// which MySQL requires to be followed by whitespace or the end of the file
+func (p *Parser) startsLineComment() bool {
+	next := p.peek(2)
+	if len(next) == 0 || next[0] != '-' {
+		return false
+	}
+	if len(next) == 1 || isSpace(next[1]) {
+		p.readByte()
+		return true
+	}
+	return false
+}
+
+// readByte returns the next byte, read ahead or from the file
+func (p *Parser) readByte() (byte, error) {
+	var c byte
+	if len(p.pending) > 0 {
+		c = p.pending[0]
+		p.pending = p.pending[1:]
+	} else {
+		var err error
+		c, err = p.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	p.offset++
+	if c == '\n' {
+		p.line++
+	}
+	return c, nil
+}
+
+// peek returns up to n bytes without reading them
+func (p *Parser) peek(n int) []byte {
+	for len(p.pending) < n {
+		c, err := p.r.ReadByte()
+		if err != nil {
+			break
+		}
+		p.pending = append(p.pending, c)
+	}
+
+	if len(p.pending) < n {
+		return p.pending
+	}
+	return p.pending[:n]
+}
+
+// peekLine returns the rest of the current line, including its newline,
+// without reading it
+func (p *Parser) peekLine() []byte {
+	for {
+		if i := bytes.IndexByte(p.pending, '\n'); i >= 0 {
+			return p.pending[:i+1]
+		}
+
+		c, err := p.r.ReadByte()
+		if err != nil {
+			return p.pending
+		}
+		p.pending = append(p.pending, c)
+	}
+}
+
+// discard reads n bytes
+func (p *Parser) discard(n int) {
+	for i := 0; i < n; i++ {
+		p.readByte()
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
@@ -0,0 +1,232 @@
+package database_restore
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseAll(t *testing.T, sql string) ([]Statement, error) {
+	t.Helper()
+
+	statements := make([]Statement, 0)
+	p := NewParser(strings.NewReader(sql))
+	for {
+		statement, err := p.Next()
+		if err == io.EOF {
+			return statements, nil
+		}
+		if err != nil {
+			return statements, err
+		}
+		statements = append(statements, statement)
+	}
+}
+
+func texts(statements []Statement) []string {
+	t := make([]string, 0, len(statements))
+	for _, s := range statements {
+		t = append(t, s.Text)
+	}
+	return t
+}
+
+func TestParserStatements(t *testing.T) {
+	cases := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "statements",
+			sql:  "CREATE TABLE a (id int);\nINSERT INTO a VALUES (1);\n",
+			want: []string{"CREATE TABLE a (id int)", "INSERT INTO a VALUES (1)"},
+		},
+		{
+			name: "final statement without a delimiter",
+			sql:  "SELECT 1;\nSELECT 2",
+			want: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name: "several statements on a line",
+			sql:  "SELECT 1; SELECT 2;",
+			want: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name: "delimiters in quotes",
+			sql:  "INSERT INTO a VALUES ('a;b', \"c;d\");\nSELECT `e;f` FROM a;\n",
+			want: []string{"INSERT INTO a VALUES ('a;b', \"c;d\")", "SELECT `e;f` FROM a"},
+		},
+		{
+			name: "escaped and doubled quotes",
+			sql:  "SELECT 'it\\'s;', 'it''s;';\nSELECT 2;\n",
+			want: []string{"SELECT 'it\\'s;', 'it''s;'", "SELECT 2"},
+		},
+		{
+			name: "comments in quotes",
+			sql:  "SELECT '-- not a comment', '/* nor this */', '# nor this';\n",
+			want: []string{"SELECT '-- not a comment', '/* nor this */', '# nor this'"},
+		},
+		{
+			name: "line comments",
+			sql:  "-- a comment;\nSELECT 1; -- trailing;\n# another;\nSELECT 2;\n",
+			want: []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name: "block comments",
+			sql:  "/* a comment; */ SELECT 1;\nSELECT /* multi\nline; */ 2;\n",
+			want: []string{"SELECT 1", "SELECT   2"},
+		},
+		{
+			name: "executable comments are kept",
+			sql:  "/*!40101 SET NAMES utf8 */;\nSELECT /*+ MAX_EXECUTION_TIME(1) */ 1;\n",
+			want: []string{"/*!40101 SET NAMES utf8 */", "SELECT /*+ MAX_EXECUTION_TIME(1) */ 1"},
+		},
+		{
+			name: "double dash without a space is not a comment",
+			sql:  "SELECT 1--1;\n",
+			want: []string{"SELECT 1--1"},
+		},
+		{
+			name: "delimiter",
+			sql:  "DELIMITER ;;\nCREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END;;\nDELIMITER ;\nSELECT 3;\n",
+			want: []string{"CREATE PROCEDURE p() BEGIN SELECT 1; SELECT 2; END", "SELECT 3"},
+		},
+		{
+			name: "lower case delimiter",
+			sql:  "delimiter //\nCREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW SET @x = 1; //\ndelimiter ;\n",
+			want: []string{"CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW SET @x = 1;"},
+		},
+		{
+			name: "delimiter in quotes",
+			sql:  "DELIMITER $$\nSELECT '$$'$$\n",
+			want: []string{"SELECT '$$'"},
+		},
+		{
+			name: "empty statements",
+			sql:  ";\n  ;\nSELECT 1;\n\n",
+			want: []string{"SELECT 1"},
+		},
+		{
+			name: "empty file",
+			sql:  "",
+			want: []string{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			statements, err := parseAll(t, c.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := texts(statements)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParserSourceCommands(t *testing.T) {
+	cases := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{name: "source", sql: "source tables.sql\n", want: []string{"tables.sql"}},
+		{name: "upper case", sql: "SOURCE tables.sql\n", want: []string{"tables.sql"}},
+		{name: "short form", sql: "\\. tables.sql\n", want: []string{"tables.sql"}},
+		{name: "pattern", sql: "source tables/*.sql\n", want: []string{"tables/*.sql"}},
+		{name: "between statements", sql: "SELECT 1;\nsource a.sql\nSELECT 2;\n", want: []string{"", "a.sql", ""}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			statements, err := parseAll(t, c.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0)
+			for _, s := range statements {
+				got = append(got, s.Source)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParserPositions(t *testing.T) {
+	sql := "SELECT 1;\n-- comment\nSELECT\n2;\nDELIMITER //\nSELECT 3//\n"
+	statements, err := parseAll(t, sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		line   int
+		offset int64
+	}{
+		{line: 1, offset: int64(strings.Index(sql, "\n-- comment"))},
+		{line: 3, offset: int64(strings.Index(sql, "\nDELIMITER"))},
+		{line: 6, offset: int64(strings.LastIndex(sql, "\n"))},
+	}
+	if len(statements) != len(want) {
+		t.Fatalf("got %d statements, want %d", len(statements), len(want))
+	}
+	for i, w := range want {
+		if statements[i].Line != w.line || statements[i].Offset != w.offset {
+			t.Errorf(
+				"statement %d: got line %d offset %d, want line %d offset %d",
+				i, statements[i].Line, statements[i].Offset, w.line, w.offset,
+			)
+		}
+	}
+}
+
+func TestParserResume(t *testing.T) {
+	sql := "SELECT 1;\nDELIMITER //\nSELECT 2//\nSELECT 3//\n"
+	statements, err := parseAll(t, sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := statements[1]
+	p := NewParserAt(strings.NewReader(sql[first.Offset:]), Position{
+		Offset:    first.Offset,
+		Line:      3,
+		Delimiter: "//",
+	})
+	statement, err := p.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statement.Text != "SELECT 3" || statement.Line != 4 || statement.Offset != statements[2].Offset {
+		t.Errorf("got %+v, want %+v", statement, statements[2])
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		sql  string
+	}{
+		{name: "unterminated quote", sql: "SELECT 'a;\n"},
+		{name: "unterminated comment", sql: "SELECT 1 /* a;\n"},
+		{name: "source without a file", sql: "source ;\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := parseAll(t, c.sql)
+			if err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
@@ -1,62 +1,22 @@
 package database_restore
 
 import (
-	"database/sql"
-	"fmt"
-	"io/ioutil"
-	"regexp"
-	"strings"
-
-	_ "github.com/go-sql-driver/mysql"
-	log "github.com/sirupsen/logrus"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
 )
 
-func RestoreSQLFile(sqlFile string) {
-	pathSplit := strings.Split(sqlFile, "/")
-	sqlDirectory := strings.Join(pathSplit[:len(pathSplit)-1], "/")
-
-	sqlContent, err := ioutil.ReadFile(sqlFile)
-	if err != nil {
-		log.Fatalf("could not read sql file: %s", err)
+// RestoreSQLFile restores sqlFile to the server described by conn, which is
+// not connected to for a dry run. svc is only used to find the cluster conn
+// names and may be nil otherwise.
+func RestoreSQLFile(svc rds_api.RDSAPI, sqlFile string, conn ConnectionConfig, opts RestoreOptions) (Report, error) {
+	if opts.DryRun {
+		return Restore(nil, sqlFile, opts)
 	}
-	splitSql := strings.Split(string(sqlContent), ";")
-
-	db, err := sql.Open("mysql", "admin:123456@/")
-	var f func([]string)
-	f = func(sqlStatements []string) {
-		for i, statement := range sqlStatements {
-			re := regexp.MustCompile(`\r?\n`)
-			statement = re.ReplaceAllString(statement, "")
-
-			re = regexp.MustCompile(`^source.*`)
-			match := re.MatchString(statement)
-			if match {
-				file := strings.Replace(statement, "source", "", 1)
-				file = strings.TrimSpace(file)
 
-				sourceContent, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", sqlDirectory, file))
-				if err != nil {
-					log.Fatalf("could not read source file %s %s", file, err)
-				}
-
-				f(strings.Split(string(sourceContent), ";"))
-				continue
-			}
-
-			if statement == "" {
-				continue
-			}
-
-			if i%10 == 0 {
-				log.Info("sql executing")
-			}
-
-			_, err := db.Exec(statement)
-			if err != nil {
-				log.Fatalf("could not execute statement %s %s", statement, err)
-			}
-		}
+	db, err := Open(svc, conn)
+	if err != nil {
+		return Report{Source: sqlFile}, err
 	}
+	defer db.Close()
 
-	f(splitSql)
+	return Restore(db, sqlFile, opts)
 }
@@ -0,0 +1,530 @@
+package database_restore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// progressInterval is the least time between two progress reports
+const progressInterval = 2 * time.Second
+
+// RestoreOptions controls how the statements of a file are restored
+type RestoreOptions struct {
+	// Whether to only parse the file. No connection is needed and statements
+	// are counted as skipped.
+	DryRun bool
+	// Whether to go on with the next statement when one fails. The restore
+	// stops at the first failure otherwise.
+	ContinueOnError bool
+	// Time each statement may run for (optional). Statements running longer
+	// stop the restore even with ContinueOnError, the connection they ran on
+	// being closed to cancel them.
+	StatementTimeout time.Duration
+	// Number of statements committed together in a transaction. Statements
+	// are committed one at a time when 0. MySQL commits implicitly around
+	// most DDL statements, which a transaction can not roll back.
+	BatchSize int
+	// File the checkpoint of the restore is saved to after each commit
+	// (optional). The restore resumes from the checkpoint when the file
+	// exists, and removes the file once it completes.
+	StateFile string
+	// Number of nested source commands to follow, DefaultMaxIncludeDepth
+	// when 0
+	MaxIncludeDepth int
+	// Called with the progress of the restore every few seconds and once it
+	// completes (optional). Progress is logged when nil.
+	Progress func(Progress)
+}
+
+// Progress is how far a restore has got
+type Progress struct {
+	// File being restored
+	File string
+	// Bytes of File read and its size, 0 when it is not known such as for
+	// compressed files
+	Offset int64
+	Size   int64
+	// Number of statements gone through across every file
+	Statements int
+}
+
+// Report summarizes a restore
+type Report struct {
+	// Name of the source restored
+	Source string `json:"source"`
+	// Number of statements executed and committed
+	Executed int `json:"executed"`
+	// Number of statements which failed
+	Failed int `json:"failed"`
+	// Number of statements not executed, because of a dry run or because
+	// their transaction was rolled back
+	Skipped int `json:"skipped"`
+	// Number of statements gone through by previous runs of the restore
+	Resumed  int           `json:"resumed"`
+	Duration time.Duration `json:"-"`
+	// Duration in a readable form
+	Elapsed string `json:"elapsed"`
+	// Files restored, each with the files it includes
+	Files []*Include `json:"files,omitempty"`
+}
+
+// StatementError is returned when a statement fails
+type StatementError struct {
+	File      string
+	Line      int
+	Statement string
+	Err       error
+}
+
+func (e *StatementError) Error() string {
+	statement := e.Statement
+	if len(statement) > 80 {
+		statement = statement[:77] + "..."
+	}
+
+	return fmt.Sprintf("%s:%d: %v: %s", e.File, e.Line, e.Err, statement)
+}
+
+func (e *StatementError) Unwrap() error {
+	return e.Err
+}
+
+type restorer struct {
+	conn   *sql.Conn
+	source Source
+	opts   RestoreOptions
+	tx     *sql.Tx
+	// statements executed in tx
+	pending int
+	// position, size and include of each file being restored, outermost
+	// first
+	positions []Position
+	sizes     []int64
+	includes  []*Include
+	// bytes read from the files of the source
+	read int64
+	// statements gone through, including those of previous runs
+	statements int
+	report     Report
+	reported   time.Time
+}
+
+// Restore restores the SQL file or archive at sqlFile, as opened by
+// OpenSource
+func Restore(db *sql.DB, sqlFile string, opts RestoreOptions) (Report, error) {
+	source, err := OpenSource(sqlFile)
+	if err != nil {
+		return Report{Source: sqlFile}, err
+	}
+	defer source.Close()
+
+	return RestoreSource(db, source, opts)
+}
+
+// RestoreSource executes the statements of each file of the source, and of
+// the files they include with source commands, on a single connection of db.
+// db may be nil for a dry run. Unless opts.ContinueOnError is set, a failed
+// statement stops the restore. Its transaction is rolled back and the
+// checkpoint of the last commit is kept, so that running the restore again
+// with the same state file resumes from there. The report counts the
+// statements gone through either way.
+func RestoreSource(db *sql.DB, source Source, opts RestoreOptions) (Report, error) {
+	started := time.Now()
+	r := &restorer{source: source, opts: opts, report: Report{Source: source.Name()}, reported: started}
+
+	file, ok, err := nextFile(source)
+	if err != nil {
+		return r.report, err
+	}
+
+	var resume []Position
+	if opts.StateFile != "" {
+		c, err := LoadCheckpoint(opts.StateFile)
+		if err != nil {
+			return r.report, err
+		}
+
+		if c != nil {
+			// checkpoints saved before sources were recorded have none
+			if c.Source != "" && c.Source != source.Name() {
+				return r.report, fmt.Errorf(
+					"state file %s is for %s, not %s", opts.StateFile, c.Source, source.Name(),
+				)
+			}
+
+			for ok && file != c.Positions[0].File {
+				file, ok, err = nextFile(source)
+				if err != nil {
+					return r.report, err
+				}
+			}
+			if !ok {
+				return r.report, fmt.Errorf(
+					"state file %s is in %s, which %s no longer holds",
+					opts.StateFile, c.Positions[0].File, source.Name(),
+				)
+			}
+
+			resume = c.Positions
+			r.statements = c.Statements
+			r.report.Resumed = c.Statements
+			last := c.Positions[len(c.Positions)-1]
+			log.Infof(
+				"resuming %s after %d statements, from %s line %d",
+				source.Name(), c.Statements, last.File, last.Line,
+			)
+		}
+	}
+
+	if !opts.DryRun {
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			return r.report, err
+		}
+		defer conn.Close()
+		r.conn = conn
+	}
+
+	positions := resume
+	for ok {
+		include := &Include{File: file}
+		r.report.Files = append(r.report.Files, include)
+
+		err = r.restoreFile(include, positions)
+		if err != nil {
+			break
+		}
+		positions = nil
+
+		file, ok, err = nextFile(source)
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = r.commit()
+	}
+	if err != nil {
+		r.rollback()
+	}
+
+	r.report.Duration = time.Since(started)
+	r.report.Elapsed = r.report.Duration.Round(time.Millisecond).String()
+	if err != nil {
+		return r.report, err
+	}
+
+	r.progress(Progress{File: source.Name(), Offset: r.read, Size: r.read, Statements: r.statements})
+
+	if opts.StateFile != "" && !opts.DryRun {
+		err = RemoveCheckpoint(opts.StateFile)
+		if err != nil {
+			return r.report, err
+		}
+	}
+
+	return r.report, nil
+}
+
+// nextFile returns the next file of the source, and false once there are
+// none left
+func nextFile(source Source) (string, bool, error) {
+	file, err := source.Next()
+	if err == io.EOF {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return file, true, nil
+}
+
+// restoreFile restores the file of the include from the first of the
+// positions to resume from, or from its start when there are none
+func (r *restorer) restoreFile(include *Include, resume []Position) error {
+	file := include.File
+	pos := Position{File: file, Line: 1, Delimiter: DefaultDelimiter}
+	if len(resume) > 0 {
+		if resume[0].File != file {
+			return fmt.Errorf("checkpoint is in %s, not %s", resume[0].File, file)
+		}
+		pos = resume[0]
+	}
+
+	in, size, err := r.source.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	err = skip(in, pos.Offset)
+	if err != nil {
+		return fmt.Errorf("%s: could not skip to the checkpoint: %v", file, err)
+	}
+
+	if size < 0 {
+		size = 0
+	}
+	r.positions = append(r.positions, pos)
+	r.sizes = append(r.sizes, size)
+	r.includes = append(r.includes, include)
+	depth := len(r.positions) - 1
+	defer func() {
+		if depth == 0 {
+			r.read += r.positions[0].Offset
+		}
+		r.positions = r.positions[:depth]
+		r.sizes = r.sizes[:depth]
+		r.includes = r.includes[:depth]
+	}()
+
+	p := NewParserAt(in, pos)
+
+	// finish the source command the checkpoint is in before the rest of the
+	// file
+	if len(resume) > 1 {
+		statement, err := p.Next()
+		if err == nil && statement.Source == "" {
+			err = errors.New("not a source command")
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: checkpoint is not at a source command: %v", file, pos.Line, err)
+		}
+
+		err = r.include(statement, p, resume[1:])
+		if err != nil {
+			return err
+		}
+	}
+
+	for {
+		statement, err := p.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+
+		r.positions[depth].Offset = statement.Offset
+		r.positions[depth].Line = p.Line()
+		r.positions[depth].Delimiter = p.Delimiter()
+
+		if statement.Source != "" {
+			err = r.include(statement, p, nil)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = r.exec(file, statement)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// include restores the files included by a source command of the file being
+// restored, resuming from the first of the positions when there are any.
+// While they are restored the position in the including file is the one of
+// the source command, along with the index of the included file among those
+// the command includes.
+func (r *restorer) include(statement Statement, p *Parser, resume []Position) error {
+	depth := len(r.positions) - 1
+	file := r.positions[depth].File
+
+	files, err := resolveInclude(r.source, file, statement.Source)
+	if err != nil {
+		return fmt.Errorf("%s:%d: %v", file, statement.Line, err)
+	}
+
+	first := 0
+	if len(resume) > 0 {
+		first = r.positions[depth].Include
+		if first >= len(files) || files[first] != resume[0].File {
+			return fmt.Errorf(
+				"%s:%d: checkpoint is in %s, which is no longer included", file, statement.Line, resume[0].File,
+			)
+		}
+	}
+
+	maxDepth := r.opts.MaxIncludeDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultMaxIncludeDepth
+	}
+	if depth+1 > maxDepth {
+		return fmt.Errorf("%s:%d: %w, at most %d are followed", file, statement.Line, IncludeDepthErr, maxDepth)
+	}
+
+	for i := first; i < len(files); i++ {
+		if chain := includeChain(r.positions, files[i]); chain != nil {
+			return fmt.Errorf("%s:%d: %w: %s", file, statement.Line, IncludeCycleErr, strings.Join(chain, " -> "))
+		}
+
+		r.positions[depth].Offset = statement.Start
+		r.positions[depth].Line = statement.Line
+		r.positions[depth].Include = i
+
+		include := &Include{File: files[i], Line: statement.Line}
+		parent := r.includes[depth]
+		parent.Includes = append(parent.Includes, include)
+
+		var positions []Position
+		if i == first {
+			positions = resume
+		}
+		err = r.restoreFile(include, positions)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.positions[depth].Offset = statement.Offset
+	r.positions[depth].Line = p.Line()
+	r.positions[depth].Include = 0
+
+	return nil
+}
+
+// exec executes the statement and commits once the batch is full
+func (r *restorer) exec(file string, statement Statement) error {
+	depth := len(r.positions) - 1
+	r.positions[depth].Statement++
+	r.includes[depth].Statements++
+	r.statements++
+
+	if r.opts.DryRun {
+		r.report.Skipped++
+		return nil
+	}
+
+	err := r.execContext(statement.Text)
+	if err != nil {
+		r.report.Failed++
+		err = &StatementError{File: file, Line: statement.Line, Statement: statement.Text, Err: err}
+		if !r.opts.ContinueOnError || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		log.Warn(err)
+	} else {
+		r.pending++
+	}
+
+	if r.pending >= r.opts.BatchSize {
+		err = r.commit()
+		if err != nil {
+			return err
+		}
+	}
+
+	if time.Since(r.reported) >= progressInterval {
+		r.progress(Progress{
+			File:       file,
+			Offset:     r.positions[depth].Offset,
+			Size:       r.sizes[depth],
+			Statements: r.statements,
+		})
+	}
+
+	return nil
+}
+
+// execContext executes the query within the statement timeout, in the
+// current transaction when batching
+func (r *restorer) execContext(query string) error {
+	ctx := context.Background()
+	if r.opts.StatementTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.StatementTimeout)
+		defer cancel()
+	}
+
+	if r.opts.BatchSize == 0 {
+		_, err := r.conn.ExecContext(ctx, query)
+		return err
+	}
+
+	if r.tx == nil {
+		tx, err := r.conn.BeginTx(context.Background(), nil)
+		if err != nil {
+			return err
+		}
+		r.tx = tx
+	}
+
+	_, err := r.tx.ExecContext(ctx, query)
+	return err
+}
+
+// commit commits the current transaction, if any, and saves the checkpoint
+func (r *restorer) commit() error {
+	if r.tx != nil {
+		err := r.tx.Commit()
+		r.tx = nil
+		if err != nil {
+			r.report.Skipped += r.pending
+			r.pending = 0
+			return fmt.Errorf("could not commit: %v", err)
+		}
+	}
+	r.report.Executed += r.pending
+	r.pending = 0
+
+	if r.opts.StateFile == "" || r.opts.DryRun || len(r.positions) == 0 {
+		return nil
+	}
+
+	positions := make([]Position, len(r.positions))
+	copy(positions, r.positions)
+
+	return SaveCheckpoint(r.opts.StateFile, Checkpoint{
+		Source:     r.source.Name(),
+		Positions:  positions,
+		Statements: r.statements,
+		UpdatedAt:  time.Now().UTC(),
+	})
+}
+
+// rollback discards the statements of the current transaction
+func (r *restorer) rollback() {
+	if r.tx == nil {
+		return
+	}
+
+	err := r.tx.Rollback()
+	if err != nil {
+		log.Warnf("could not roll back: %s", err)
+	}
+	r.tx = nil
+
+	if r.pending > 0 {
+		log.Infof("rolled back %d statements", r.pending)
+	}
+	r.report.Skipped += r.pending
+	r.pending = 0
+}
+
+func (r *restorer) progress(p Progress) {
+	r.reported = time.Now()
+	if r.opts.Progress != nil {
+		r.opts.Progress(p)
+		return
+	}
+
+	if p.Size == 0 {
+		log.Infof("%s: %d bytes, %d statements", p.File, p.Offset, p.Statements)
+		return
+	}
+
+	percent := int(p.Offset * 100 / p.Size)
+	log.Infof("%s: %d of %d bytes (%d%%), %d statements", p.File, p.Offset, p.Size, percent, p.Statements)
+}
@@ -0,0 +1,607 @@
+package database_restore
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// stubDriver records the statements committed through it and fails the
+// statements holding fail
+type stubDriver struct {
+	mu        sync.Mutex
+	fail      string
+	committed []string
+}
+
+type stubConn struct {
+	d       *stubDriver
+	pending []string
+	inTx    bool
+}
+
+type stubTx struct {
+	c *stubConn
+}
+
+var (
+	stub     = &stubDriver{}
+	stubDB   *sql.DB
+	stubOnce sync.Once
+)
+
+// openStub returns the database on the stub driver, set to fail the statements
+// holding fail
+func openStub(t *testing.T, fail string) *sql.DB {
+	t.Helper()
+
+	stubOnce.Do(func() {
+		sql.Register("database_restore_stub", stub)
+		stubDB, _ = sql.Open("database_restore_stub", "")
+	})
+	stub.mu.Lock()
+	stub.fail = fail
+	stub.mu.Unlock()
+
+	return stubDB
+}
+
+func (d *stubDriver) Open(string) (driver.Conn, error) {
+	return &stubConn{d: d}, nil
+}
+
+func (d *stubDriver) take() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	committed := d.committed
+	d.committed = nil
+	return committed
+}
+
+func (c *stubConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *stubConn) Close() error {
+	return nil
+}
+
+func (c *stubConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	return stubTx{c: c}, nil
+}
+
+func (c *stubConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+
+	if c.d.fail != "" && strings.Contains(query, c.d.fail) {
+		return nil, errors.New("statement failed")
+	}
+
+	if c.inTx {
+		c.pending = append(c.pending, query)
+	} else {
+		c.d.committed = append(c.d.committed, query)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (tx stubTx) Commit() error {
+	tx.c.d.mu.Lock()
+	defer tx.c.d.mu.Unlock()
+
+	tx.c.d.committed = append(tx.c.d.committed, tx.c.pending...)
+	tx.c.pending = nil
+	tx.c.inTx = false
+	return nil
+}
+
+func (tx stubTx) Rollback() error {
+	tx.c.pending = nil
+	tx.c.inTx = false
+	return nil
+}
+
+// tempDir creates a temporary directory, removed by calling the returned
+// function
+func tempDir(t *testing.T) (string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "database_restore")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+// writeFiles writes files, by path relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRestoreResumesFromCheckpoint(t *testing.T) {
+	files := map[string]string{
+		"main.sql": "CREATE TABLE a (id int);\nINSERT INTO a VALUES (1);\nsource inc/part.sql\n" +
+			"DELIMITER //\nINSERT INTO a VALUES (4)//\nDELIMITER ;\nINSERT INTO a VALUES (5);\n",
+		"inc/part.sql": "INSERT INTO a VALUES (2);\n-- a comment\nINSERT INTO a VALUES (3);\n",
+	}
+	all := []string{
+		"CREATE TABLE a (id int)",
+		"INSERT INTO a VALUES (1)",
+		"INSERT INTO a VALUES (2)",
+		"INSERT INTO a VALUES (3)",
+		"INSERT INTO a VALUES (4)",
+		"INSERT INTO a VALUES (5)",
+	}
+
+	cases := []struct {
+		name      string
+		fail      string
+		batchSize int
+	}{
+		{name: "fails in the restored file", fail: "VALUES (1)"},
+		{name: "fails in an included file", fail: "VALUES (3)"},
+		{name: "fails after an include", fail: "VALUES (4)"},
+		{name: "fails in the last statement", fail: "VALUES (5)"},
+		{name: "fails in a batch", fail: "VALUES (3)", batchSize: 2},
+		{name: "fails in a batch across files", fail: "VALUES (4)", batchSize: 3},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			writeFiles(t, dir, files)
+			stateFile := filepath.Join(dir, "state.json")
+			opts := RestoreOptions{StateFile: stateFile, BatchSize: c.batchSize}
+			stub.take()
+
+			_, err := Restore(openStub(t, c.fail), filepath.Join(dir, "main.sql"), opts)
+			var statementErr *StatementError
+			if !errors.As(err, &statementErr) {
+				t.Fatalf("got %v, want a statement error", err)
+			}
+			first := stub.take()
+
+			checkpoint, err := LoadCheckpoint(stateFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(first) > 0 && checkpoint == nil {
+				t.Fatal("no checkpoint was saved")
+			}
+
+			report, err := Restore(openStub(t, ""), filepath.Join(dir, "main.sql"), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second := stub.take()
+
+			got := append(first, second...)
+			if !reflect.DeepEqual(got, all) {
+				t.Errorf("committed %q then %q, want %q", first, second, all)
+			}
+			if report.Resumed != len(first) {
+				t.Errorf("resumed after %d statements, want %d", report.Resumed, len(first))
+			}
+
+			_, err = os.Stat(stateFile)
+			if !os.IsNotExist(err) {
+				t.Errorf("state file was not removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestRestoreRefusesCheckpointOfAnotherSource(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	writeFiles(t, dir, map[string]string{
+		"a.sql": "SELECT 1;\nSELECT 2;\n",
+		"b.sql": "SELECT 1;\nSELECT 2;\n",
+	})
+	stateFile := filepath.Join(dir, "state.json")
+	opts := RestoreOptions{StateFile: stateFile}
+
+	_, err := Restore(openStub(t, "SELECT 2"), filepath.Join(dir, "a.sql"), opts)
+	if err == nil {
+		t.Fatal("restore did not fail")
+	}
+	stub.take()
+
+	_, err = Restore(openStub(t, ""), filepath.Join(dir, "b.sql"), opts)
+	if err == nil || !strings.Contains(err.Error(), "is for") {
+		t.Errorf("got %v, want the state file to be refused", err)
+	}
+	if committed := stub.take(); len(committed) > 0 {
+		t.Errorf("committed %q", committed)
+	}
+}
+
+func TestRestoreIncludes(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    map[string]string
+		maxDepth int
+		wantErr  error
+		want     int
+	}{
+		{
+			name: "nested includes",
+			files: map[string]string{
+				"main.sql":  "SELECT 1;\nsource a/a.sql\n",
+				"a/a.sql":   "SELECT 2;\nsource ../b.sql\n",
+				"b.sql":     "SELECT 3;\n",
+				"other.sql": "SELECT 4;\n",
+			},
+			want: 3,
+		},
+		{
+			name: "pattern",
+			files: map[string]string{
+				"main.sql":     "source tables/*.sql\n",
+				"tables/a.sql": "SELECT 1;\n",
+				"tables/b.sql": "SELECT 2;\n",
+			},
+			want: 2,
+		},
+		{
+			name: "same file included twice",
+			files: map[string]string{
+				"main.sql": "source a.sql\nsource a.sql\n",
+				"a.sql":    "SELECT 1;\n",
+			},
+			want: 2,
+		},
+		{
+			name: "self include",
+			files: map[string]string{
+				"main.sql": "SELECT 1;\nsource main.sql\n",
+			},
+			wantErr: IncludeCycleErr,
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"main.sql": "source a.sql\n",
+				"a.sql":    "source b.sql\n",
+				"b.sql":    "source ./a.sql\n",
+			},
+			wantErr: IncludeCycleErr,
+		},
+		{
+			name: "include cycle through a pattern",
+			files: map[string]string{
+				"main.sql":     "source tables/*.sql\n",
+				"tables/a.sql": "source ../main.sql\n",
+			},
+			wantErr: IncludeCycleErr,
+		},
+		{
+			name: "too deep",
+			files: map[string]string{
+				"main.sql": "source a.sql\n",
+				"a.sql":    "source b.sql\n",
+				"b.sql":    "SELECT 1;\n",
+			},
+			maxDepth: 1,
+			wantErr:  IncludeDepthErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			writeFiles(t, dir, c.files)
+			opts := RestoreOptions{DryRun: true, MaxIncludeDepth: c.maxDepth}
+
+			report, err := Restore(nil, filepath.Join(dir, "main.sql"), opts)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Errorf("got %v, want %v", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Skipped != c.want {
+				t.Errorf("went through %d statements, want %d", report.Skipped, c.want)
+			}
+		})
+	}
+}
+
+func tarArchive(t *testing.T, files ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     files[i],
+			Mode:     0644,
+			Size:     int64(len(files[i+1])),
+			Typeflag: tar.TypeReg,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tw.Write([]byte(files[i+1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := tw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(b)
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func zstdCompressed(t *testing.T, b []byte) []byte {
+	t.Helper()
+
+	w, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	return w.EncodeAll(b, nil)
+}
+
+func TestRestoreSources(t *testing.T) {
+	sqlFile := []byte("SELECT 1;\nSELECT 2;\n")
+	archive := tarArchive(t,
+		"a.sql", "SELECT 1;\n",
+		"inc/b.sql", "SELECT 2;\nSELECT 3;\n",
+		"z.sql", "SELECT 4;\n",
+	)
+	archiveFiles := []string{"a.sql", "inc/b.sql", "z.sql"}
+
+	cases := []struct {
+		name      string
+		content   []byte
+		wantFiles []string
+		want      []string
+	}{
+		{name: "sql", content: sqlFile, want: []string{"SELECT 1", "SELECT 2"}},
+		{name: "sql.gz", content: gzipped(t, sqlFile), want: []string{"SELECT 1", "SELECT 2"}},
+		{name: "sql.zst", content: zstdCompressed(t, sqlFile), want: []string{"SELECT 1", "SELECT 2"}},
+		{
+			name:      "tar",
+			content:   archive,
+			wantFiles: archiveFiles,
+			want:      []string{"SELECT 1", "SELECT 2", "SELECT 3", "SELECT 4"},
+		},
+		{
+			name:      "tar.gz",
+			content:   gzipped(t, archive),
+			wantFiles: archiveFiles,
+			want:      []string{"SELECT 1", "SELECT 2", "SELECT 3", "SELECT 4"},
+		},
+		{
+			name:      "tar.zst",
+			content:   zstdCompressed(t, archive),
+			wantFiles: archiveFiles,
+			want:      []string{"SELECT 1", "SELECT 2", "SELECT 3", "SELECT 4"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			path := filepath.Join(dir, "dump."+c.name)
+			err := ioutil.WriteFile(path, c.content, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			open := map[string]func() (Source, error){
+				"path": func() (Source, error) {
+					return OpenSource(path)
+				},
+				"reader": func() (Source, error) {
+					return NewReaderSource(path, bytes.NewReader(c.content))
+				},
+			}
+			for kind, open := range open {
+				source, err := open()
+				if err != nil {
+					t.Fatalf("%s: %v", kind, err)
+				}
+
+				stub.take()
+				report, err := RestoreSource(openStub(t, ""), source, RestoreOptions{})
+				if err != nil {
+					t.Fatalf("%s: %v", kind, err)
+				}
+				if got := stub.take(); !reflect.DeepEqual(got, c.want) {
+					t.Errorf("%s: committed %q, want %q", kind, got, c.want)
+				}
+
+				files := make([]string, 0)
+				for _, include := range report.Files {
+					files = append(files, include.File)
+				}
+				wantFiles := c.wantFiles
+				if wantFiles == nil {
+					wantFiles = []string{path}
+				}
+				if !reflect.DeepEqual(files, wantFiles) {
+					t.Errorf("%s: restored files %q, want %q", kind, files, wantFiles)
+				}
+
+				err = source.Close()
+				if err != nil {
+					t.Errorf("%s: %v", kind, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreArchives(t *testing.T) {
+	including := gzipped(t, tarArchive(t,
+		"a.sql", "SELECT 1;\nsource inc/*.sql\n",
+		"inc/b.sql", "SELECT 2;\n",
+		"inc/c.sql", "SELECT 3;\n",
+	))
+	unordered := gzipped(t, tarArchive(t,
+		"z.sql", "SELECT 2;\n",
+		"a.sql", "SELECT 1;\n",
+	))
+
+	cases := []struct {
+		name    string
+		content []byte
+		// read the archive from a reader rather than from its path
+		stream  bool
+		want    []string
+		fails   bool
+		wantErr error
+	}{
+		{
+			name:    "includes",
+			content: including,
+			want:    []string{"SELECT 1", "SELECT 2", "SELECT 3", "SELECT 2", "SELECT 3"},
+		},
+		{
+			name:    "includes from a stream",
+			content: including,
+			stream:  true,
+			fails:   true,
+		},
+		{
+			name:    "unordered",
+			content: unordered,
+			wantErr: UnorderedArchiveErr,
+		},
+		{
+			name:    "unordered from a stream",
+			content: unordered,
+			stream:  true,
+			wantErr: UnorderedArchiveErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			path := filepath.Join(dir, "dump.tar.gz")
+			err := ioutil.WriteFile(path, c.content, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var source Source
+			if c.stream {
+				source, err = NewReaderSource(path, bytes.NewReader(c.content))
+			} else {
+				source, err = OpenSource(path)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer source.Close()
+
+			stub.take()
+			_, err = RestoreSource(openStub(t, ""), source, RestoreOptions{})
+			if c.wantErr != nil || c.fails {
+				if err == nil || c.wantErr != nil && !errors.Is(err, c.wantErr) {
+					t.Errorf("got %v, want %v", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stub.take(); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("committed %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRestoreResumesCompressedSource(t *testing.T) {
+	archive := tarArchive(t,
+		"a.sql", "SELECT 1;\nSELECT 2;\n",
+		"b.sql", "SELECT 3;\n",
+	)
+
+	for name, content := range map[string][]byte{"tar.gz": gzipped(t, archive), "tar.zst": zstdCompressed(t, archive)} {
+		t.Run(name, func(t *testing.T) {
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			path := filepath.Join(dir, "dump."+name)
+			err := ioutil.WriteFile(path, content, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := RestoreOptions{StateFile: filepath.Join(dir, "state.json")}
+			stub.take()
+
+			_, err = Restore(openStub(t, "SELECT 2"), path, opts)
+			if err == nil {
+				t.Fatal("restore did not fail")
+			}
+			_, err = Restore(openStub(t, ""), path, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := []string{"SELECT 1", "SELECT 2", "SELECT 3"}
+			if got := stub.take(); !reflect.DeepEqual(got, want) {
+				t.Errorf("committed %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,454 @@
+package database_restore
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	UnorderedArchiveErr error
+)
+
+func init() {
+	UnorderedArchiveErr = errors.New("archive files are not in the order of their names")
+}
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	// tar headers hold the magic ustar, followed by a NUL or a space, at
+	// offset 257
+	tarMagic       = []byte("ustar")
+	tarMagicOffset = 257
+)
+
+// Source provides the files read by a restore. Files are named by paths
+// relative to the working directory or absolute, or by paths within an
+// archive.
+type Source interface {
+	// Name describes the source, e.g. the path it was opened from
+	Name() string
+	// Next returns the next file to restore, or io.EOF once there are none
+	// left
+	Next() (string, error)
+	// Open opens a file for reading, decompressed, and returns its size or -1
+	// when it is not known before reading it
+	Open(name string) (io.ReadCloser, int64, error)
+	// Glob returns the files matching a pattern, in filepath.Match syntax, in
+	// lexical order
+	Glob(pattern string) ([]string, error)
+	// Close releases what the source holds, e.g. the archive being read
+	Close() error
+}
+
+// OpenSource returns the source of the SQL file or archive at path. Files
+// compressed with gzip, bzip2 or zstd are decompressed as they are read. Tar
+// archives, compressed or not, are streamed and restore each of their files
+// as it comes, so they must hold them in the order of their names.
+func OpenSource(path string) (Source, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read sql file: %v", err)
+	}
+
+	r, _, err := decompress(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	closer := readCloser{closers: []io.Closer{r, f}}
+	br := bufio.NewReaderSize(r, tarMagicOffset+len(tarMagic))
+	archive, err := isTar(br)
+	if err != nil {
+		closer.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if archive {
+		return newTarSource(path, path, br, closer), nil
+	}
+	closer.Close()
+
+	return &fileSource{path: path}, nil
+}
+
+// NewReaderSource returns the source of the SQL file or archive read from r,
+// named name, the way OpenSource does for a path. A file can only be read once
+// from r, resuming a restore reads it up to the checkpoint. The files it
+// includes are read from the filesystem, except for those of an archive,
+// which can not be included.
+func NewReaderSource(name string, r io.Reader) (Source, error) {
+	d, _, err := decompress(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	br := bufio.NewReaderSize(d, tarMagicOffset+len(tarMagic))
+	archive, err := isTar(br)
+	if err != nil {
+		d.Close()
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if archive {
+		return newTarSource(name, "", br, d), nil
+	}
+
+	return &streamSource{name: name, r: readCloser{Reader: br, closers: []io.Closer{d}}}, nil
+}
+
+// isTar reports whether r starts with a tar header, without consuming it
+func isTar(r *bufio.Reader) (bool, error) {
+	header, err := r.Peek(tarMagicOffset + len(tarMagic))
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	return len(header) == tarMagicOffset+len(tarMagic) && bytes.Equal(header[tarMagicOffset:], tarMagic), nil
+}
+
+// fileSource reads a single file and the files it includes from the
+// filesystem
+type fileSource struct {
+	path string
+	// whether Next returned the file
+	listed bool
+}
+
+func (s *fileSource) Name() string {
+	return s.path
+}
+
+func (s *fileSource) Next() (string, error) {
+	if s.listed {
+		return "", io.EOF
+	}
+	s.listed = true
+
+	return s.path, nil
+}
+
+func (s *fileSource) Open(name string) (io.ReadCloser, int64, error) {
+	return openFile(name)
+}
+
+func (s *fileSource) Glob(pattern string) ([]string, error) {
+	return globFiles(pattern)
+}
+
+func (s *fileSource) Close() error {
+	return nil
+}
+
+// openFile opens the file at name the way Source.Open does
+func openFile(name string) (io.ReadCloser, int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read sql file: %v", err)
+	}
+
+	r, compressed, err := decompress(f)
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if !compressed {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		return f, info.Size(), nil
+	}
+
+	return readCloser{Reader: r, closers: []io.Closer{r, f}}, -1, nil
+}
+
+// globFiles returns the files of the filesystem matching pattern the way
+// Source.Glob does
+func globFiles(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+// streamSource reads a single file from a reader, and the files it includes
+// from the filesystem
+type streamSource struct {
+	name string
+	// nil once the file was opened
+	r io.ReadCloser
+	// whether Next returned the file
+	listed bool
+}
+
+func (s *streamSource) Name() string {
+	return s.name
+}
+
+func (s *streamSource) Next() (string, error) {
+	if s.listed {
+		return "", io.EOF
+	}
+	s.listed = true
+
+	return s.name, nil
+}
+
+func (s *streamSource) Open(name string) (io.ReadCloser, int64, error) {
+	if name != s.name {
+		return openFile(name)
+	}
+
+	if s.r == nil {
+		return nil, 0, fmt.Errorf("%s: can only be read once", s.name)
+	}
+	r := s.r
+	s.r = nil
+
+	return r, -1, nil
+}
+
+func (s *streamSource) Glob(pattern string) ([]string, error) {
+	return globFiles(pattern)
+}
+
+func (s *streamSource) Close() error {
+	if s.r == nil {
+		return nil
+	}
+
+	err := s.r.Close()
+	s.r = nil
+	return err
+}
+
+// tarSource streams the files of a tar archive. They are restored as they
+// come, which is the order of their names as the archive must hold them in
+// that order. Files opened out of turn, such as included ones, are read by
+// going through the archive again from its path, which an archive read from
+// a stream does not have.
+type tarSource struct {
+	name string
+	// path of the archive, empty when it is read from a stream
+	path   string
+	r      *tar.Reader
+	closer io.Closer
+	// the file last returned by Next, its size and whether it was opened
+	current string
+	size    int64
+	opened  bool
+}
+
+func newTarSource(name, path string, r io.Reader, closer io.Closer) *tarSource {
+	return &tarSource{name: name, path: path, r: tar.NewReader(r), closer: closer}
+}
+
+func (s *tarSource) Name() string {
+	return s.name
+}
+
+func (s *tarSource) Next() (string, error) {
+	for {
+		header, err := s.r.Next()
+		if err == io.EOF {
+			return "", io.EOF
+		}
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", s.name, err)
+		}
+
+		member := path.Clean(header.Name)
+		if !isTarFile(header) || member == s.current {
+			continue
+		}
+		if member < s.current {
+			return "", fmt.Errorf("%s: %s comes after %s: %w", s.name, member, s.current, UnorderedArchiveErr)
+		}
+
+		s.current, s.size, s.opened = member, header.Size, false
+		return member, nil
+	}
+}
+
+func (s *tarSource) Open(name string) (io.ReadCloser, int64, error) {
+	if name == s.current && !s.opened {
+		s.opened = true
+		return ioutil.NopCloser(s.r), s.size, nil
+	}
+
+	if s.path == "" {
+		return nil, 0, fmt.Errorf("%s: %s: the files of an archive read from a stream can only be read in turn", s.name, name)
+	}
+
+	tr, closer, err := openTar(s.path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			closer.Close()
+			return nil, 0, fmt.Errorf("%s: %s not found in archive", s.name, name)
+		}
+		if err != nil {
+			closer.Close()
+			return nil, 0, fmt.Errorf("%s: %v", s.name, err)
+		}
+
+		if isTarFile(header) && path.Clean(header.Name) == name {
+			return readCloser{Reader: tr, closers: []io.Closer{closer}}, header.Size, nil
+		}
+	}
+}
+
+func (s *tarSource) Glob(pattern string) ([]string, error) {
+	if s.path == "" {
+		return nil, fmt.Errorf("%s: the files of an archive read from a stream can not be matched", s.name)
+	}
+
+	tr, closer, err := openTar(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	matches := make([]string, 0)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", s.name, err)
+		}
+
+		member := path.Clean(header.Name)
+		if !isTarFile(header) || len(matches) > 0 && matches[len(matches)-1] == member {
+			continue
+		}
+
+		ok, err := path.Match(pattern, member)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, member)
+		}
+	}
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+func (s *tarSource) Close() error {
+	return s.closer.Close()
+}
+
+// openTar opens the archive at name to read it from its start
+func openTar(name string) (*tar.Reader, io.Closer, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read archive: %v", err)
+	}
+
+	r, _, err := decompress(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return tar.NewReader(r), readCloser{closers: []io.Closer{r, f}}, nil
+}
+
+func isTarFile(header *tar.Header) bool {
+	return header.Typeflag == tar.TypeReg || header.Typeflag == tar.TypeRegA
+}
+
+// decompress returns a reader decompressing r when it starts with the magic
+// bytes of gzip, bzip2 or zstd data, and whether it does. Closing the reader
+// releases the decompression but does not close r. Uncompressed files at their
+// start are returned as is, so that restores can resume by seeking.
+func decompress(r io.Reader) (io.ReadCloser, bool, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, false, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gr, err := gzip.NewReader(br)
+		return gr, true, err
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return ioutil.NopCloser(bzip2.NewReader(br)), true, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, false, err
+		}
+		return zr.IOReadCloser(), true, nil
+	}
+
+	if f, ok := r.(*os.File); ok {
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			return nil, false, err
+		}
+		return ioutil.NopCloser(f), false, nil
+	}
+
+	return ioutil.NopCloser(br), false, nil
+}
+
+// skip moves r forward by n bytes, by seeking when it can
+func skip(r io.Reader, n int64) error {
+	if n == 0 {
+		return nil
+	}
+
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(n, io.SeekStart)
+		return err
+	}
+
+	_, err := io.CopyN(ioutil.Discard, r, n)
+	return err
+}
+
+// readCloser reads from a reader and closes each of its closers in order
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r readCloser) Close() error {
+	var closeErr error
+	for _, c := range r.closers {
+		err := c.Close()
+		if err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+
+	return closeErr
+}
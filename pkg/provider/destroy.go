@@ -0,0 +1,283 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster_parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/guard"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/subnet_group"
+	"github.com/cvgw/rds_provider/pkg/provider/wait"
+	log "github.com/sirupsen/logrus"
+)
+
+// DestroyOptions controls how DestroyCluster tears a cluster down
+type DestroyOptions struct {
+	// Whether to delete the instances of the cluster first. Clusters with
+	// instances are refused otherwise.
+	Cascade bool
+	// Whether to also delete the subnet group and parameter groups used by
+	// the cluster and its instances. Default groups and groups still used by
+	// other clusters or instances are kept.
+	DeleteGroups bool
+	// Final snapshot settings of the cluster. Its instances have no final
+	// snapshot of their own.
+	ClusterDeleteOptions cluster.DeleteOptions
+	// Whether to keep the automated backups of the deleted instances
+	RetainAutomatedBackups bool
+	// How long and how often to check that each resource is gone
+	WaitOptions wait.Options
+	// Checks made before anything is deleted (optional). Its Cascade setting
+	// is replaced by the one of these options.
+	Guard *guard.Guard
+}
+
+// clusterTeardown lists the resources making up a cluster in the order they
+// are deleted
+type clusterTeardown struct {
+	clusterId string
+	// Readers followed by the writer
+	instanceIds           []string
+	subnetGroup           string
+	clusterParameterGroup string
+	parameterGroups       []string
+}
+
+// DestroyCluster deletes the cluster along with its instances and, optionally,
+// the groups it used. Readers are deleted one at a time before the writer and
+// each deletion is waited on before the next one starts.
+func DestroyCluster(svc rds_api.RDSAPI, clusterId string, opts DestroyOptions) error {
+	// refuse bad final snapshot settings before any instance is deleted, and
+	// keep the generated snapshot id for the cluster deletion
+	deleteInput, err := cluster.NewDeleteDBClusterInput(clusterId, opts.ClusterDeleteOptions)
+	if err != nil {
+		return err
+	}
+	if deleteInput.FinalDBSnapshotIdentifier != nil {
+		opts.ClusterDeleteOptions.FinalSnapshotId = *deleteInput.FinalDBSnapshotIdentifier
+	}
+
+	c, err := cluster.FindDBCluster(svc, clusterId)
+	if err != nil {
+		return err
+	}
+
+	t, err := newClusterTeardown(svc, c, opts.DeleteGroups)
+	if err != nil {
+		return err
+	}
+
+	if len(t.instanceIds) > 0 && !opts.Cascade {
+		return fmt.Errorf(
+			"cluster %s: %w (%s), delete them first or cascade",
+			clusterId, guard.HasMembersErr, strings.Join(t.instanceIds, ", "),
+		)
+	}
+
+	if opts.Guard != nil {
+		g := *opts.Guard
+		g.Cascade = opts.Cascade
+		err = checkClusterTeardown(svc, t, g)
+		if err != nil {
+			return err
+		}
+	}
+
+	instanceOpts := instance.DeleteOptions{RetainAutomatedBackups: opts.RetainAutomatedBackups}
+	for _, instanceId := range t.instanceIds {
+		log.Infof("cluster %s: deleting instance %s", clusterId, instanceId)
+		err = instance.DeleteDBClusterInstanceAndWait(svc, instanceId, instanceOpts, opts.WaitOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Infof("cluster %s: deleting cluster", clusterId)
+	err = cluster.DeleteDBClusterAndWait(svc, clusterId, opts.ClusterDeleteOptions, opts.WaitOptions)
+	if err != nil {
+		return err
+	}
+
+	if !opts.DeleteGroups {
+		return nil
+	}
+
+	return deleteUnusedGroups(svc, t)
+}
+
+// newClusterTeardown collects the members of the cluster, readers first, and
+// the groups used by the cluster and its instances when withGroups is set
+func newClusterTeardown(svc rds_api.RDSAPI, c *rds.DBCluster, withGroups bool) (clusterTeardown, error) {
+	t := clusterTeardown{clusterId: aws.StringValue(c.DBClusterIdentifier)}
+
+	writers := make([]string, 0)
+	for _, m := range c.DBClusterMembers {
+		if aws.BoolValue(m.IsClusterWriter) {
+			writers = append(writers, aws.StringValue(m.DBInstanceIdentifier))
+			continue
+		}
+		t.instanceIds = append(t.instanceIds, aws.StringValue(m.DBInstanceIdentifier))
+	}
+	t.instanceIds = append(t.instanceIds, writers...)
+
+	if !withGroups {
+		return t, nil
+	}
+
+	t.subnetGroup = aws.StringValue(c.DBSubnetGroup)
+	t.clusterParameterGroup = aws.StringValue(c.DBClusterParameterGroup)
+
+	seen := make(map[string]bool)
+	for _, instanceId := range t.instanceIds {
+		i, err := instance.FindDBClusterInstance(svc, instanceId)
+		if err != nil {
+			return t, err
+		}
+
+		for _, g := range i.DBParameterGroups {
+			name := aws.StringValue(g.DBParameterGroupName)
+			if name != "" && !seen[name] {
+				seen[name] = true
+				t.parameterGroups = append(t.parameterGroups, name)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// checkClusterTeardown runs the guard's checks on every resource of the
+// teardown and asks for confirmation of their deletion
+func checkClusterTeardown(svc rds_api.RDSAPI, t clusterTeardown, g guard.Guard) error {
+	resources := make([]string, 0)
+
+	for _, instanceId := range t.instanceIds {
+		_, err := g.CheckInstance(svc, instanceId)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, fmt.Sprintf("%s %s", KindInstance, instanceId))
+	}
+
+	_, err := g.CheckCluster(svc, t.clusterId, t.instanceIds)
+	if err != nil {
+		return err
+	}
+	resources = append(resources, fmt.Sprintf("%s %s", KindCluster, t.clusterId))
+
+	for _, name := range t.parameterGroups {
+		if isDefaultGroup(name) {
+			continue
+		}
+		err = g.CheckParameterGroup(svc, name)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, fmt.Sprintf("%s %s (if unused)", KindParameterGroup, name))
+	}
+
+	if name := t.clusterParameterGroup; name != "" && !isDefaultGroup(name) {
+		err = g.CheckClusterParameterGroup(svc, name)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, fmt.Sprintf("%s %s (if unused)", KindClusterParameterGroup, name))
+	}
+
+	if name := t.subnetGroup; name != "" && !isDefaultGroup(name) {
+		err = g.CheckSubnetGroup(svc, name)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, fmt.Sprintf("%s %s (if unused)", KindSubnetGroup, name))
+	}
+
+	return g.ConfirmDelete(resources)
+}
+
+// deleteUnusedGroups deletes the groups of the teardown which no remaining
+// cluster or instance uses. Default groups can not be deleted and are skipped.
+func deleteUnusedGroups(svc rds_api.RDSAPI, t clusterTeardown) error {
+	clusters, err := cluster.ListDBClusters(svc, cluster.ListRequest{})
+	if err != nil {
+		return err
+	}
+
+	instances, err := instance.ListDBClusterInstances(svc, instance.ListRequest{})
+	if err != nil {
+		return err
+	}
+
+	used := make(map[string]map[string]bool)
+	use := func(kind, name string) {
+		if used[kind] == nil {
+			used[kind] = make(map[string]bool)
+		}
+		used[kind][name] = true
+	}
+	for _, c := range clusters {
+		use(KindSubnetGroup, aws.StringValue(c.DBSubnetGroup))
+		use(KindClusterParameterGroup, aws.StringValue(c.DBClusterParameterGroup))
+	}
+	for _, i := range instances {
+		if i.DBSubnetGroup != nil {
+			use(KindSubnetGroup, aws.StringValue(i.DBSubnetGroup.DBSubnetGroupName))
+		}
+		for _, g := range i.DBParameterGroups {
+			use(KindParameterGroup, aws.StringValue(g.DBParameterGroupName))
+		}
+	}
+
+	skip := func(kind, name string) bool {
+		switch {
+		case name == "":
+			return true
+		case isDefaultGroup(name):
+			log.Infof("%s %s: default group, keeping", kind, name)
+			return true
+		case used[kind][name]:
+			log.Infof("%s %s: still in use, keeping", kind, name)
+			return true
+		default:
+			return false
+		}
+	}
+
+	for _, name := range t.parameterGroups {
+		if skip(KindParameterGroup, name) {
+			continue
+		}
+		err = parameter_group.DeleteDBParameterGroup(svc, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !skip(KindClusterParameterGroup, t.clusterParameterGroup) {
+		err = cluster_parameter_group.DeleteDBClusterParameterGroup(svc, t.clusterParameterGroup)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !skip(KindSubnetGroup, t.subnetGroup) {
+		err = subnet_group.DeleteDBSubnetGroup(svc, t.subnetGroup)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isDefaultGroup reports whether name is one of the groups RDS provides, such
+// as default.aurora-mysql5.7 or the default subnet group
+func isDefaultGroup(name string) bool {
+	return name == "default" || strings.HasPrefix(name, "default.")
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/fake_rds"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+)
+
+func TestDestroyCluster(t *testing.T) {
+	cases := []struct {
+		name    string
+		opts    cluster.DeleteOptions
+		wantErr error
+	}{
+		{name: "final snapshot", opts: cluster.DeleteOptions{FinalSnapshotId: "test-cluster-final"}},
+		{name: "generated final snapshot"},
+		{name: "forced skip of the final snapshot", opts: cluster.DeleteOptions{SkipFinalSnapshot: true, Force: true}},
+		{
+			name:    "skip of the final snapshot without force",
+			opts:    cluster.DeleteOptions{SkipFinalSnapshot: true},
+			wantErr: cluster.FinalSnapshotRequiredErr,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := fake_rds.New()
+			stack := loadSampleStack(t)
+			_, err := ReconcileStack(svc, stack, testStackOptions(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := DestroyOptions{
+				Cascade:              true,
+				ClusterDeleteOptions: c.opts,
+				WaitOptions:          testStackOptions(true).WaitOptions,
+			}
+			err = DestroyCluster(svc, stack.Cluster.ClusterId, opts)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("got %v, want %v", err, c.wantErr)
+				}
+				// nothing was deleted
+				for _, i := range stack.Instances {
+					_, err = instance.FindDBClusterInstance(svc, i.InstanceIdentifier)
+					if err != nil {
+						t.Errorf("instance %s: %v", i.InstanceIdentifier, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = cluster.FindDBCluster(svc, stack.Cluster.ClusterId)
+			if !errors.Is(err, rds_errors.ErrNotFound) {
+				t.Errorf("cluster: got %v, want not found", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package fake_rds
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+func enginePort(engine string) int64 {
+	if strings.Contains(engine, "postgres") {
+		return 5432
+	}
+	return 3306
+}
+
+func defaultEngineVersion(engine string) string {
+	switch engine {
+	case "aurora-postgresql":
+		return "10.5"
+	case "aurora-mysql":
+		return "5.7.12"
+	default:
+		return "5.6.10a"
+	}
+}
+
+func securityGroups(ids []*string) []*rds.VpcSecurityGroupMembership {
+	groups := make([]*rds.VpcSecurityGroupMembership, 0)
+	for _, id := range ids {
+		groups = append(groups, &rds.VpcSecurityGroupMembership{
+			Status:             aws.String("active"),
+			VpcSecurityGroupId: aws.String(aws.StringValue(id)),
+		})
+	}
+
+	return groups
+}
+
+// advanceClusters moves every cluster one step through its lifecycle
+func (f *RDS) advanceClusters() {
+	for id, c := range f.clusters {
+		if f.modified[aws.StringValue(c.DBClusterArn)] {
+			continue
+		}
+
+		switch aws.StringValue(c.Status) {
+		case statusCreating, statusModifying:
+			c.Status = aws.String(statusAvailable)
+			c.LatestRestorableTime = aws.Time(time.Now().UTC())
+		case statusAvailable:
+			c.LatestRestorableTime = aws.Time(time.Now().UTC())
+		case statusDeleting:
+			delete(f.clusters, id)
+			delete(f.tags, aws.StringValue(c.DBClusterArn))
+		}
+	}
+}
+
+func (f *RDS) CreateDBCluster(input *rds.CreateDBClusterInput) (*rds.CreateDBClusterOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if aws.StringValue(input.MasterUsername) == "" || aws.StringValue(input.MasterUserPassword) == "" {
+		return nil, f.badRequest(
+			errCodeInvalidParameterValue, "The parameter MasterUsername and MasterUserPassword must be provided",
+		)
+	}
+
+	c, err := f.createCluster(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rds.CreateDBClusterOutput{DBCluster: copyOf(c).(*rds.DBCluster)}, nil
+}
+
+// createCluster adds a cluster created from input. Restored clusters are
+// created the same way and then take the settings of their source.
+func (f *RDS) createCluster(input *rds.CreateDBClusterInput) (*rds.DBCluster, error) {
+	id := aws.StringValue(input.DBClusterIdentifier)
+	if _, ok := f.clusters[id]; ok {
+		return nil, f.badRequest(rds.ErrCodeDBClusterAlreadyExistsFault, "DB Cluster already exists")
+	}
+
+	subnetGroup := aws.StringValue(input.DBSubnetGroupName)
+	if subnetGroup == "" {
+		subnetGroup = "default"
+	} else if _, ok := f.subnetGroups[subnetGroup]; !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBSubnetGroupNotFoundFault, "DB Subnet group %s not found", subnetGroup,
+		)
+	}
+
+	engine := aws.StringValue(input.Engine)
+	paramGroup := aws.StringValue(input.DBClusterParameterGroupName)
+	if paramGroup == "" {
+		paramGroup = "default." + engine
+	} else if _, ok := f.clusterParameterGroups[paramGroup]; !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBClusterParameterGroupNotFoundFault,
+			"DBClusterParameterGroup not found: %s", paramGroup,
+		)
+	}
+
+	engineVersion := aws.StringValue(input.EngineVersion)
+	if engineVersion == "" {
+		engineVersion = defaultEngineVersion(engine)
+	}
+
+	azs := input.AvailabilityZones
+	if len(azs) == 0 {
+		azs = aws.StringSlice(f.availabilityZones())
+	}
+
+	backupRetention := aws.Int64Value(input.BackupRetentionPeriod)
+	if backupRetention == 0 {
+		backupRetention = 1
+	}
+
+	port := aws.Int64Value(input.Port)
+	if port == 0 {
+		port = enginePort(engine)
+	}
+
+	now := time.Now().UTC()
+	c := &rds.DBCluster{
+		AllocatedStorage:        aws.Int64(1),
+		AvailabilityZones:       aws.StringSlice(stringValues(azs)),
+		BackupRetentionPeriod:   aws.Int64(backupRetention),
+		ClusterCreateTime:       aws.Time(now),
+		DBClusterArn:            aws.String(f.arn("cluster", id)),
+		DBClusterIdentifier:     aws.String(id),
+		DBClusterMembers:        make([]*rds.DBClusterMember, 0),
+		DBClusterParameterGroup: aws.String(paramGroup),
+		DBSubnetGroup:           aws.String(subnetGroup),
+		DatabaseName:            input.DatabaseName,
+		DbClusterResourceId:     aws.String(fmt.Sprintf("cluster-%s", strings.ToUpper(id))),
+		DeletionProtection:      aws.Bool(aws.BoolValue(input.DeletionProtection)),
+		EarliestRestorableTime:  aws.Time(now),
+		Endpoint:                aws.String(fmt.Sprintf("%s.cluster-fake.%s.rds.amazonaws.com", id, f.Region)),
+		Engine:                  aws.String(engine),
+		EngineMode:              aws.String("provisioned"),
+		EngineVersion:           aws.String(engineVersion),
+		KmsKeyId:                input.KmsKeyId,
+		MasterUsername:          aws.String(aws.StringValue(input.MasterUsername)),
+		MultiAZ:                 aws.Bool(false),
+		Port:                    aws.Int64(port),
+		ReaderEndpoint:          aws.String(fmt.Sprintf("%s.cluster-ro-fake.%s.rds.amazonaws.com", id, f.Region)),
+		Status:                  aws.String(statusCreating),
+		StorageEncrypted:        aws.Bool(aws.BoolValue(input.StorageEncrypted)),
+		VpcSecurityGroups:       securityGroups(input.VpcSecurityGroupIds),
+	}
+	f.clusters[id] = c
+	f.addTags(aws.StringValue(c.DBClusterArn), input.Tags)
+
+	return c, nil
+}
+
+func (f *RDS) DescribeDBClusters(input *rds.DescribeDBClustersInput) (*rds.DescribeDBClustersOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.advanceClusters()
+
+	filters, err := f.filterValues(input.Filters, "db-cluster-id", "engine")
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make([]*rds.DBCluster, 0)
+	if input.DBClusterIdentifier != nil {
+		c, ok := f.clusters[*input.DBClusterIdentifier]
+		if !ok {
+			return nil, f.notFound(
+				rds.ErrCodeDBClusterNotFoundFault, "DBCluster %s not found.", *input.DBClusterIdentifier,
+			)
+		}
+		clusters = append(clusters, c)
+	} else {
+		for _, id := range sortedKeys(f.clusters) {
+			c := f.clusters[id]
+			if matchesFilter(filters, "db-cluster-id", id, aws.StringValue(c.DBClusterArn)) &&
+				matchesFilter(filters, "engine", aws.StringValue(c.Engine)) {
+				clusters = append(clusters, c)
+			}
+		}
+	}
+
+	start, end, marker, err := f.page(len(clusters), input.Marker, input.MaxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &rds.DescribeDBClustersOutput{Marker: marker}
+	for _, c := range clusters[start:end] {
+		delete(f.modified, aws.StringValue(c.DBClusterArn))
+		output.DBClusters = append(output.DBClusters, copyOf(c).(*rds.DBCluster))
+	}
+
+	return output, nil
+}
+
+func (f *RDS) DescribeDBClustersRequest(input *rds.DescribeDBClustersInput) (
+	*request.Request, *rds.DescribeDBClustersOutput,
+) {
+	output := &rds.DescribeDBClustersOutput{}
+	req := f.newRequest("DescribeDBClusters", input, output, func() error {
+		result, err := f.DescribeDBClusters(input)
+		if err != nil {
+			return err
+		}
+		*output = *result
+		return nil
+	})
+
+	return req, output
+}
+
+func (f *RDS) ModifyDBCluster(input *rds.ModifyDBClusterInput) (*rds.ModifyDBClusterOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := aws.StringValue(input.DBClusterIdentifier)
+	c, ok := f.clusters[id]
+	if !ok {
+		return nil, f.notFound(rds.ErrCodeDBClusterNotFoundFault, "DBCluster %s not found.", id)
+	}
+
+	if aws.StringValue(c.Status) != statusAvailable {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterStateFault,
+			"DBCluster %s is not in available state (%s)", id, aws.StringValue(c.Status),
+		)
+	}
+
+	if input.DBClusterParameterGroupName != nil {
+		name := *input.DBClusterParameterGroupName
+		if _, ok := f.clusterParameterGroups[name]; !ok {
+			return nil, f.notFound(
+				rds.ErrCodeDBClusterParameterGroupNotFoundFault,
+				"DBClusterParameterGroup not found: %s", name,
+			)
+		}
+		c.DBClusterParameterGroup = aws.String(name)
+	}
+
+	if input.EngineVersion != nil {
+		c.EngineVersion = aws.String(*input.EngineVersion)
+	}
+
+	if input.BackupRetentionPeriod != nil {
+		c.BackupRetentionPeriod = aws.Int64(*input.BackupRetentionPeriod)
+	}
+
+	if input.DeletionProtection != nil {
+		c.DeletionProtection = aws.Bool(*input.DeletionProtection)
+	}
+
+	if input.VpcSecurityGroupIds != nil {
+		c.VpcSecurityGroups = securityGroups(input.VpcSecurityGroupIds)
+	}
+
+	c.Status = aws.String(statusModifying)
+	f.modified[aws.StringValue(c.DBClusterArn)] = true
+
+	return &rds.ModifyDBClusterOutput{DBCluster: copyOf(c).(*rds.DBCluster)}, nil
+}
+
+func (f *RDS) DeleteDBCluster(input *rds.DeleteDBClusterInput) (*rds.DeleteDBClusterOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := aws.StringValue(input.DBClusterIdentifier)
+	c, ok := f.clusters[id]
+	if !ok {
+		return nil, f.notFound(rds.ErrCodeDBClusterNotFoundFault, "DBCluster %s not found.", id)
+	}
+
+	if aws.StringValue(c.Status) == statusDeleting {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterStateFault, "DBCluster %s is already being deleted.", id,
+		)
+	}
+
+	if aws.BoolValue(c.DeletionProtection) {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterStateFault,
+			"Cannot delete protected Cluster, please disable deletion protection and try again.",
+		)
+	}
+
+	for _, i := range f.instances {
+		if aws.StringValue(i.DBClusterIdentifier) == id &&
+			aws.StringValue(i.DBInstanceStatus) != statusDeleting {
+			return nil, f.badRequest(
+				rds.ErrCodeInvalidDBClusterStateFault,
+				"Cluster cannot be deleted, it still contains DB instances in non-deleting state.",
+			)
+		}
+	}
+
+	if !aws.BoolValue(input.SkipFinalSnapshot) && aws.StringValue(input.FinalDBSnapshotIdentifier) == "" {
+		return nil, f.badRequest(
+			errCodeInvalidParameterCombination,
+			"FinalDBSnapshotIdentifier is required unless SkipFinalSnapshot is specified.",
+		)
+	}
+
+	if !aws.BoolValue(input.SkipFinalSnapshot) {
+		_, err := f.newClusterSnapshot(c, aws.StringValue(input.FinalDBSnapshotIdentifier), snapshotTypeManual)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.Status = aws.String(statusDeleting)
+
+	return &rds.DeleteDBClusterOutput{DBCluster: copyOf(c).(*rds.DBCluster)}, nil
+}
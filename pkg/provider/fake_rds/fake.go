@@ -0,0 +1,271 @@
+package fake_rds
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+)
+
+const (
+	DefaultRegion    = "us-west-2"
+	DefaultAccountId = "123456789012"
+
+	defaultMaxRecords = 100
+
+	statusAvailable = "available"
+	statusCreating  = "creating"
+	statusModifying = "modifying"
+	statusDeleting  = "deleting"
+
+	errCodeInvalidParameterValue       = "InvalidParameterValue"
+	errCodeInvalidParameterCombination = "InvalidParameterCombination"
+)
+
+var _ rds_api.RDSAPI = (*RDS)(nil)
+
+// RDS is an in-memory implementation of rds_api.RDSAPI. Clusters and instances
+// move through the same statuses they do in RDS, advancing one step each time
+// they are described, and failed calls return the fault codes RDS returns.
+// Modified clusters and instances are described as modifying once before they
+// become available again.
+type RDS struct {
+	Region    string
+	AccountId string
+
+	mu                     sync.Mutex
+	requestCount           int
+	clusters               map[string]*rds.DBCluster
+	instances              map[string]*rds.DBInstance
+	subnetGroups           map[string]*rds.DBSubnetGroup
+	parameterGroups        map[string]*parameterGroup
+	clusterParameterGroups map[string]*parameterGroup
+	clusterSnapshots       map[string]*rds.DBClusterSnapshot
+	// clusterSnapshotShares holds the accounts each snapshot is shared with
+	clusterSnapshotShares map[string][]string
+	// tags holds the tags of every resource by ARN
+	tags map[string][]*rds.Tag
+	// modified holds the ARNs of the clusters and instances which have not
+	// been described since they were modified
+	modified map[string]bool
+}
+
+// parameterGroup holds either kind of parameter group along with its user set
+// parameters
+type parameterGroup struct {
+	arn         string
+	family      string
+	name        string
+	description string
+	parameters  map[string]*rds.Parameter
+}
+
+func New() *RDS {
+	return &RDS{
+		Region:                 DefaultRegion,
+		AccountId:              DefaultAccountId,
+		clusters:               make(map[string]*rds.DBCluster),
+		instances:              make(map[string]*rds.DBInstance),
+		subnetGroups:           make(map[string]*rds.DBSubnetGroup),
+		parameterGroups:        make(map[string]*parameterGroup),
+		clusterParameterGroups: make(map[string]*parameterGroup),
+		clusterSnapshots:       make(map[string]*rds.DBClusterSnapshot),
+		clusterSnapshotShares:  make(map[string][]string),
+		tags:                   make(map[string][]*rds.Tag),
+		modified:               make(map[string]bool),
+	}
+}
+
+func (f *RDS) arn(resourceType, id string) string {
+	return fmt.Sprintf("arn:aws:rds:%s:%s:%s:%s", f.Region, f.AccountId, resourceType, id)
+}
+
+// availabilityZones returns the availability zones of the fake region
+func (f *RDS) availabilityZones() []string {
+	return []string{f.Region + "a", f.Region + "b", f.Region + "c"}
+}
+
+// newFault builds an error shaped like the errors returned by the RDS client
+func (f *RDS) newFault(statusCode int, code, format string, args ...interface{}) error {
+	f.requestCount++
+	return awserr.NewRequestFailure(
+		awserr.New(code, fmt.Sprintf(format, args...), nil),
+		statusCode,
+		fmt.Sprintf("fake-request-%d", f.requestCount),
+	)
+}
+
+func (f *RDS) notFound(code, format string, args ...interface{}) error {
+	return f.newFault(http.StatusNotFound, code, format, args...)
+}
+
+func (f *RDS) badRequest(code, format string, args ...interface{}) error {
+	return f.newFault(http.StatusBadRequest, code, format, args...)
+}
+
+// newRequest builds a request.Request which runs fn in place of sending an
+// HTTP request. fn is expected to fill in data.
+func (f *RDS) newRequest(operation string, params, data interface{}, fn func() error) *request.Request {
+	handlers := request.Handlers{}
+	handlers.Send.PushBack(func(r *request.Request) {
+		r.Error = fn()
+	})
+
+	return request.New(
+		aws.Config{},
+		metadata.ClientInfo{ServiceName: rds.ServiceName, SigningName: rds.ServiceName},
+		handlers,
+		nil,
+		&request.Operation{Name: operation},
+		params,
+		data,
+	)
+}
+
+// newWaiter builds a request.Waiter polling with newRequest
+func newWaiter(
+	ctx aws.Context, name string, acceptors []request.WaiterAcceptor,
+	newRequest func() *request.Request,
+) request.Waiter {
+	return request.Waiter{
+		Name:        name,
+		MaxAttempts: 60,
+		Delay:       request.ConstantWaiterDelay(time.Second),
+		Acceptors:   acceptors,
+		NewRequest: func(opts []request.Option) (*request.Request, error) {
+			req := newRequest()
+			req.SetContext(ctx)
+			req.ApplyOptions(opts...)
+			return req, nil
+		},
+	}
+}
+
+// copyOf returns a deep copy of v so callers can not modify the fake's state
+func copyOf(v interface{}) interface{} {
+	return awsutil.CopyOf(v)
+}
+
+// page returns the bounds of the page of n records starting at marker and the
+// marker of the following page
+func (f *RDS) page(n int, marker *string, maxRecords *int64) (int, int, *string, error) {
+	start := 0
+	if aws.StringValue(marker) != "" {
+		v, err := strconv.Atoi(*marker)
+		if err != nil || v < 0 || v > n {
+			return 0, 0, nil, f.badRequest(errCodeInvalidParameterValue, "invalid marker %s", *marker)
+		}
+		start = v
+	}
+
+	size := defaultMaxRecords
+	if maxRecords != nil {
+		if *maxRecords < 20 || *maxRecords > 100 {
+			return 0, 0, nil, f.badRequest(
+				errCodeInvalidParameterValue, "MaxRecords must be between 20 and 100",
+			)
+		}
+		size = int(*maxRecords)
+	}
+
+	end := start + size
+	if end >= n {
+		return start, n, nil, nil
+	}
+
+	return start, end, aws.String(strconv.Itoa(end)), nil
+}
+
+// filterValues returns the values of each filter by name. Filters other than
+// the supported ones are rejected as RDS does.
+func (f *RDS) filterValues(filters []*rds.Filter, supported ...string) (map[string][]string, error) {
+	values := make(map[string][]string)
+	for _, filter := range filters {
+		name := aws.StringValue(filter.Name)
+
+		known := false
+		for _, s := range supported {
+			if s == name {
+				known = true
+			}
+		}
+		if !known {
+			return nil, f.badRequest(errCodeInvalidParameterValue, "Unrecognized filter name: %s", name)
+		}
+
+		values[name] = append(values[name], stringValues(filter.Values)...)
+	}
+
+	return values, nil
+}
+
+// matchesFilter reports whether one of values is a value of the named filter,
+// or the filter is not set
+func matchesFilter(filters map[string][]string, name string, values ...string) bool {
+	want, ok := filters[name]
+	if !ok {
+		return true
+	}
+
+	for _, w := range want {
+		for _, v := range values {
+			if w == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := make([]string, 0)
+	switch v := m.(type) {
+	case map[string]*rds.DBCluster:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*rds.DBInstance:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*rds.DBSubnetGroup:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*rds.DBClusterSnapshot:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*parameterGroup:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*rds.Parameter:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func stringValues(v []*string) []string {
+	values := make([]string, 0)
+	for _, s := range v {
+		values = append(values, aws.StringValue(s))
+	}
+
+	return values
+}
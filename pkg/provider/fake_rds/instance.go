@@ -0,0 +1,349 @@
+package fake_rds
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+// advanceInstances moves every instance one step through its lifecycle
+func (f *RDS) advanceInstances() {
+	for id, i := range f.instances {
+		if f.modified[aws.StringValue(i.DBInstanceArn)] {
+			continue
+		}
+
+		switch aws.StringValue(i.DBInstanceStatus) {
+		case statusCreating, statusModifying:
+			i.DBInstanceStatus = aws.String(statusAvailable)
+		case statusDeleting:
+			delete(f.instances, id)
+			delete(f.tags, aws.StringValue(i.DBInstanceArn))
+			f.removeClusterMember(aws.StringValue(i.DBClusterIdentifier), id)
+		}
+	}
+}
+
+func (f *RDS) removeClusterMember(clusterId, instanceId string) {
+	c, ok := f.clusters[clusterId]
+	if !ok {
+		return
+	}
+
+	members := make([]*rds.DBClusterMember, 0)
+	for _, m := range c.DBClusterMembers {
+		if aws.StringValue(m.DBInstanceIdentifier) != instanceId {
+			members = append(members, m)
+		}
+	}
+
+	if len(members) > 0 && len(members) < len(c.DBClusterMembers) {
+		hasWriter := false
+		for _, m := range members {
+			hasWriter = hasWriter || aws.BoolValue(m.IsClusterWriter)
+		}
+		if !hasWriter {
+			members[0].IsClusterWriter = aws.Bool(true)
+		}
+	}
+
+	c.DBClusterMembers = members
+}
+
+func (f *RDS) CreateDBInstance(input *rds.CreateDBInstanceInput) (*rds.CreateDBInstanceOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := aws.StringValue(input.DBInstanceIdentifier)
+	if _, ok := f.instances[id]; ok {
+		return nil, f.badRequest(rds.ErrCodeDBInstanceAlreadyExistsFault, "DB instance already exists")
+	}
+
+	clusterId := aws.StringValue(input.DBClusterIdentifier)
+	c, ok := f.clusters[clusterId]
+	if !ok {
+		return nil, f.notFound(rds.ErrCodeDBClusterNotFoundFault, "DBCluster %s not found.", clusterId)
+	}
+
+	if aws.StringValue(c.Status) == statusDeleting {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterStateFault, "DBCluster %s is being deleted.", clusterId,
+		)
+	}
+
+	engine := aws.StringValue(input.Engine)
+	if engine != aws.StringValue(c.Engine) {
+		return nil, f.badRequest(
+			errCodeInvalidParameterCombination,
+			"The engine name requested for your DB instance (%s) doesn't match the engine name of your DB cluster (%s).",
+			engine, aws.StringValue(c.Engine),
+		)
+	}
+
+	paramGroup := aws.StringValue(input.DBParameterGroupName)
+	if paramGroup == "" {
+		paramGroup = "default." + engine
+	} else if _, ok := f.parameterGroups[paramGroup]; !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBParameterGroupNotFoundFault, "DBParameterGroup not found: %s", paramGroup,
+		)
+	}
+
+	azs := f.availabilityZones()
+	port := aws.Int64Value(c.Port)
+
+	securityGroupIds := make([]*string, 0)
+	for _, g := range c.VpcSecurityGroups {
+		securityGroupIds = append(securityGroupIds, g.VpcSecurityGroupId)
+	}
+
+	i := &rds.DBInstance{
+		AutoMinorVersionUpgrade: aws.Bool(aws.BoolValue(input.AutoMinorVersionUpgrade)),
+		AvailabilityZone:        aws.String(azs[len(c.DBClusterMembers)%len(azs)]),
+		CopyTagsToSnapshot:      aws.Bool(aws.BoolValue(input.CopyTagsToSnapshot)),
+		DBClusterIdentifier:     aws.String(clusterId),
+		DBInstanceArn:           aws.String(f.arn("db", id)),
+		DBInstanceClass:         aws.String(aws.StringValue(input.DBInstanceClass)),
+		DBInstanceIdentifier:    aws.String(id),
+		DBInstanceStatus:        aws.String(statusCreating),
+		DBParameterGroups: []*rds.DBParameterGroupStatus{
+			{
+				DBParameterGroupName: aws.String(paramGroup),
+				ParameterApplyStatus: aws.String("in-sync"),
+			},
+		},
+		DBSubnetGroup:      &rds.DBSubnetGroup{DBSubnetGroupName: aws.String(aws.StringValue(c.DBSubnetGroup))},
+		DbInstancePort:     aws.Int64(0),
+		DbiResourceId:      aws.String(fmt.Sprintf("db-%s", strings.ToUpper(id))),
+		DeletionProtection: aws.Bool(false),
+		Endpoint: &rds.Endpoint{
+			Address: aws.String(fmt.Sprintf("%s.fake.%s.rds.amazonaws.com", id, f.Region)),
+			Port:    aws.Int64(port),
+		},
+		Engine:             aws.String(engine),
+		EngineVersion:      aws.String(aws.StringValue(c.EngineVersion)),
+		InstanceCreateTime: aws.Time(time.Now().UTC()),
+		MasterUsername:     aws.String(aws.StringValue(c.MasterUsername)),
+		MonitoringInterval: aws.Int64(aws.Int64Value(input.MonitoringInterval)),
+		MonitoringRoleArn:  input.MonitoringRoleArn,
+		PromotionTier:      aws.Int64(1),
+		PubliclyAccessible: aws.Bool(aws.BoolValue(input.PubliclyAccessible)),
+		StorageEncrypted:   aws.Bool(aws.BoolValue(c.StorageEncrypted)),
+		VpcSecurityGroups:  securityGroups(securityGroupIds),
+	}
+	f.instances[id] = i
+	f.addTags(aws.StringValue(i.DBInstanceArn), input.Tags)
+
+	c.DBClusterMembers = append(c.DBClusterMembers, &rds.DBClusterMember{
+		DBClusterParameterGroupStatus: aws.String("in-sync"),
+		DBInstanceIdentifier:          aws.String(id),
+		IsClusterWriter:               aws.Bool(len(c.DBClusterMembers) == 0),
+		PromotionTier:                 aws.Int64(1),
+	})
+
+	return &rds.CreateDBInstanceOutput{DBInstance: copyOf(i).(*rds.DBInstance)}, nil
+}
+
+func (f *RDS) DescribeDBInstances(input *rds.DescribeDBInstancesInput) (*rds.DescribeDBInstancesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.advanceInstances()
+
+	filters, err := f.filterValues(input.Filters, "db-cluster-id", "db-instance-id", "engine")
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]*rds.DBInstance, 0)
+	if input.DBInstanceIdentifier != nil {
+		i, ok := f.instances[*input.DBInstanceIdentifier]
+		if !ok {
+			return nil, f.notFound(
+				rds.ErrCodeDBInstanceNotFoundFault, "DBInstance %s not found.", *input.DBInstanceIdentifier,
+			)
+		}
+		instances = append(instances, i)
+	} else {
+		for _, id := range sortedKeys(f.instances) {
+			i := f.instances[id]
+			clusterId := aws.StringValue(i.DBClusterIdentifier)
+			if matchesFilter(filters, "db-cluster-id", clusterId, f.arn("cluster", clusterId)) &&
+				matchesFilter(filters, "db-instance-id", id, aws.StringValue(i.DBInstanceArn)) &&
+				matchesFilter(filters, "engine", aws.StringValue(i.Engine)) {
+				instances = append(instances, i)
+			}
+		}
+	}
+
+	start, end, marker, err := f.page(len(instances), input.Marker, input.MaxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &rds.DescribeDBInstancesOutput{Marker: marker}
+	for _, i := range instances[start:end] {
+		delete(f.modified, aws.StringValue(i.DBInstanceArn))
+		output.DBInstances = append(output.DBInstances, copyOf(i).(*rds.DBInstance))
+	}
+
+	return output, nil
+}
+
+func (f *RDS) describeDBInstancesRequest(input *rds.DescribeDBInstancesInput) *request.Request {
+	output := &rds.DescribeDBInstancesOutput{}
+	return f.newRequest("DescribeDBInstances", input, output, func() error {
+		result, err := f.DescribeDBInstances(input)
+		if err != nil {
+			return err
+		}
+		*output = *result
+		return nil
+	})
+}
+
+func (f *RDS) WaitUntilDBInstanceAvailableWithContext(
+	ctx aws.Context, input *rds.DescribeDBInstancesInput, opts ...request.WaiterOption,
+) error {
+	w := newWaiter(ctx, "WaitUntilDBInstanceAvailable", []request.WaiterAcceptor{
+		{
+			State:   request.SuccessWaiterState,
+			Matcher: request.PathAllWaiterMatch, Argument: "DBInstances[].DBInstanceStatus",
+			Expected: statusAvailable,
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: "DBInstances[].DBInstanceStatus",
+			Expected: statusDeleting,
+		},
+	}, func() *request.Request {
+		return f.describeDBInstancesRequest(input)
+	})
+	w.ApplyOptions(opts...)
+
+	return w.WaitWithContext(ctx)
+}
+
+func (f *RDS) WaitUntilDBInstanceDeletedWithContext(
+	ctx aws.Context, input *rds.DescribeDBInstancesInput, opts ...request.WaiterOption,
+) error {
+	w := newWaiter(ctx, "WaitUntilDBInstanceDeleted", []request.WaiterAcceptor{
+		{
+			State:    request.SuccessWaiterState,
+			Matcher:  request.ErrorWaiterMatch,
+			Expected: rds.ErrCodeDBInstanceNotFoundFault,
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: "DBInstances[].DBInstanceStatus",
+			Expected: statusCreating,
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: "DBInstances[].DBInstanceStatus",
+			Expected: statusModifying,
+		},
+	}, func() *request.Request {
+		return f.describeDBInstancesRequest(input)
+	})
+	w.ApplyOptions(opts...)
+
+	return w.WaitWithContext(ctx)
+}
+
+func (f *RDS) ModifyDBInstance(input *rds.ModifyDBInstanceInput) (*rds.ModifyDBInstanceOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := aws.StringValue(input.DBInstanceIdentifier)
+	i, ok := f.instances[id]
+	if !ok {
+		return nil, f.notFound(rds.ErrCodeDBInstanceNotFoundFault, "DBInstance %s not found.", id)
+	}
+
+	if aws.StringValue(i.DBInstanceStatus) != statusAvailable {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBInstanceStateFault,
+			"Database instance is not in available state.",
+		)
+	}
+
+	if input.DBParameterGroupName != nil {
+		name := *input.DBParameterGroupName
+		if _, ok := f.parameterGroups[name]; !ok {
+			return nil, f.notFound(
+				rds.ErrCodeDBParameterGroupNotFoundFault, "DBParameterGroup not found: %s", name,
+			)
+		}
+		i.DBParameterGroups = []*rds.DBParameterGroupStatus{
+			{
+				DBParameterGroupName: aws.String(name),
+				ParameterApplyStatus: aws.String("applying"),
+			},
+		}
+	}
+
+	if input.DBInstanceClass != nil {
+		i.DBInstanceClass = aws.String(*input.DBInstanceClass)
+	}
+
+	if input.PubliclyAccessible != nil {
+		i.PubliclyAccessible = aws.Bool(*input.PubliclyAccessible)
+	}
+
+	i.DBInstanceStatus = aws.String(statusModifying)
+	f.modified[aws.StringValue(i.DBInstanceArn)] = true
+
+	return &rds.ModifyDBInstanceOutput{DBInstance: copyOf(i).(*rds.DBInstance)}, nil
+}
+
+func (f *RDS) DeleteDBInstance(input *rds.DeleteDBInstanceInput) (*rds.DeleteDBInstanceOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := aws.StringValue(input.DBInstanceIdentifier)
+	i, ok := f.instances[id]
+	if !ok {
+		return nil, f.notFound(rds.ErrCodeDBInstanceNotFoundFault, "DBInstance %s not found.", id)
+	}
+
+	if aws.StringValue(i.DBInstanceStatus) == statusDeleting {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBInstanceStateFault, "Instance %s is already being deleted.", id,
+		)
+	}
+
+	if aws.BoolValue(i.DeletionProtection) {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBInstanceStateFault,
+			"Cannot delete protected DB Instance, please disable deletion protection and try again.",
+		)
+	}
+
+	if aws.StringValue(i.DBClusterIdentifier) != "" && input.FinalDBSnapshotIdentifier != nil {
+		return nil, f.badRequest(
+			errCodeInvalidParameterCombination,
+			"FinalDBSnapshotIdentifier can not be specified when deleting a clustered instance",
+		)
+	}
+
+	i.DBInstanceStatus = aws.String(statusDeleting)
+
+	return &rds.DeleteDBInstanceOutput{DBInstance: copyOf(i).(*rds.DBInstance)}, nil
+}
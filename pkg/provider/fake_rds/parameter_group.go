@@ -0,0 +1,420 @@
+package fake_rds
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+func (g *parameterGroup) dbParameterGroup() *rds.DBParameterGroup {
+	return &rds.DBParameterGroup{
+		DBParameterGroupArn:    aws.String(g.arn),
+		DBParameterGroupFamily: aws.String(g.family),
+		DBParameterGroupName:   aws.String(g.name),
+		Description:            aws.String(g.description),
+	}
+}
+
+func (g *parameterGroup) dbClusterParameterGroup() *rds.DBClusterParameterGroup {
+	return &rds.DBClusterParameterGroup{
+		DBClusterParameterGroupArn:  aws.String(g.arn),
+		DBClusterParameterGroupName: aws.String(g.name),
+		DBParameterGroupFamily:      aws.String(g.family),
+		Description:                 aws.String(g.description),
+	}
+}
+
+// createParameterGroup adds a parameter group to groups
+func (f *RDS) createParameterGroup(
+	groups map[string]*parameterGroup, arn, family, name, description string,
+) (*parameterGroup, error) {
+	if _, ok := groups[name]; ok {
+		return nil, f.badRequest(
+			rds.ErrCodeDBParameterGroupAlreadyExistsFault, "Parameter group %s already exists", name,
+		)
+	}
+
+	group := &parameterGroup{
+		arn:         arn,
+		family:      family,
+		name:        name,
+		description: description,
+		parameters:  make(map[string]*rds.Parameter),
+	}
+	groups[name] = group
+
+	return group, nil
+}
+
+// modifyParameters sets the parameters of group
+func (f *RDS) modifyParameters(group *parameterGroup, params []*rds.Parameter) error {
+	if len(params) == 0 || len(params) > 20 {
+		return f.badRequest(
+			errCodeInvalidParameterValue, "Between 1 and 20 parameters may be modified at once",
+		)
+	}
+
+	for _, p := range params {
+		method := aws.StringValue(p.ApplyMethod)
+		if method != rds.ApplyMethodImmediate && method != rds.ApplyMethodPendingReboot {
+			return f.badRequest(
+				errCodeInvalidParameterValue,
+				"Invalid apply method %s for parameter %s", method, aws.StringValue(p.ParameterName),
+			)
+		}
+
+		if aws.StringValue(p.ParameterName) == "" {
+			return f.badRequest(errCodeInvalidParameterValue, "Parameter name must be provided")
+		}
+	}
+
+	for _, p := range params {
+		name := aws.StringValue(p.ParameterName)
+		group.parameters[name] = &rds.Parameter{
+			ApplyMethod:    aws.String(aws.StringValue(p.ApplyMethod)),
+			IsModifiable:   aws.Bool(true),
+			ParameterName:  aws.String(name),
+			ParameterValue: aws.String(aws.StringValue(p.ParameterValue)),
+			Source:         aws.String("user"),
+		}
+	}
+
+	return nil
+}
+
+// describeParameters returns a page of the parameters of group
+func (f *RDS) describeParameters(group *parameterGroup, marker *string, maxRecords *int64) (
+	[]*rds.Parameter, *string, error,
+) {
+	keys := sortedKeys(group.parameters)
+	start, end, next, err := f.page(len(keys), marker, maxRecords)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := make([]*rds.Parameter, 0)
+	for _, k := range keys[start:end] {
+		params = append(params, copyOf(group.parameters[k]).(*rds.Parameter))
+	}
+
+	return params, next, nil
+}
+
+func defaultParameterGroup(name string) bool {
+	return strings.HasPrefix(name, "default.")
+}
+
+func (f *RDS) CreateDBParameterGroup(input *rds.CreateDBParameterGroupInput) (*rds.CreateDBParameterGroupOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBParameterGroupName)
+	group, err := f.createParameterGroup(
+		f.parameterGroups,
+		f.arn("pg", name),
+		aws.StringValue(input.DBParameterGroupFamily),
+		name,
+		aws.StringValue(input.Description),
+	)
+	if err != nil {
+		return nil, err
+	}
+	f.addTags(group.arn, input.Tags)
+
+	return &rds.CreateDBParameterGroupOutput{DBParameterGroup: group.dbParameterGroup()}, nil
+}
+
+func (f *RDS) DescribeDBParameterGroups(input *rds.DescribeDBParameterGroupsInput) (
+	*rds.DescribeDBParameterGroupsOutput, error,
+) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	groups := make([]*parameterGroup, 0)
+	if input.DBParameterGroupName != nil {
+		group, ok := f.parameterGroups[*input.DBParameterGroupName]
+		if !ok {
+			return nil, f.notFound(
+				rds.ErrCodeDBParameterGroupNotFoundFault,
+				"DBParameterGroup not found: %s", *input.DBParameterGroupName,
+			)
+		}
+		groups = append(groups, group)
+	} else {
+		for _, name := range sortedKeys(f.parameterGroups) {
+			groups = append(groups, f.parameterGroups[name])
+		}
+	}
+
+	start, end, marker, err := f.page(len(groups), input.Marker, input.MaxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &rds.DescribeDBParameterGroupsOutput{Marker: marker}
+	for _, group := range groups[start:end] {
+		output.DBParameterGroups = append(output.DBParameterGroups, group.dbParameterGroup())
+	}
+
+	return output, nil
+}
+
+func (f *RDS) DescribeDBParameters(input *rds.DescribeDBParametersInput) (*rds.DescribeDBParametersOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBParameterGroupName)
+	group, ok := f.parameterGroups[name]
+	if !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBParameterGroupNotFoundFault, "DBParameterGroup not found: %s", name,
+		)
+	}
+
+	params, marker, err := f.describeParameters(group, input.Marker, input.MaxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rds.DescribeDBParametersOutput{Parameters: params, Marker: marker}, nil
+}
+
+func (f *RDS) ModifyDBParameterGroup(input *rds.ModifyDBParameterGroupInput) (
+	*rds.DBParameterGroupNameMessage, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBParameterGroupName)
+	group, ok := f.parameterGroups[name]
+	if !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBParameterGroupNotFoundFault, "DBParameterGroup not found: %s", name,
+		)
+	}
+
+	if defaultParameterGroup(name) {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBParameterGroupStateFault, "Default parameter groups cannot be modified",
+		)
+	}
+
+	err := f.modifyParameters(group, input.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rds.DBParameterGroupNameMessage{DBParameterGroupName: aws.String(name)}, nil
+}
+
+func (f *RDS) DeleteDBParameterGroup(input *rds.DeleteDBParameterGroupInput) (*rds.DeleteDBParameterGroupOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBParameterGroupName)
+	if _, ok := f.parameterGroups[name]; !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBParameterGroupNotFoundFault, "DBParameterGroup not found: %s", name,
+		)
+	}
+
+	if defaultParameterGroup(name) {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBParameterGroupStateFault, "Default parameter groups cannot be deleted",
+		)
+	}
+
+	for _, i := range f.instances {
+		for _, g := range i.DBParameterGroups {
+			if aws.StringValue(g.DBParameterGroupName) == name {
+				return nil, f.badRequest(
+					rds.ErrCodeInvalidDBParameterGroupStateFault,
+					"One or more database instances are still members of this parameter group %s",
+					name,
+				)
+			}
+		}
+	}
+
+	delete(f.tags, f.parameterGroups[name].arn)
+	delete(f.parameterGroups, name)
+	return &rds.DeleteDBParameterGroupOutput{}, nil
+}
+
+func (f *RDS) CreateDBClusterParameterGroup(input *rds.CreateDBClusterParameterGroupInput) (
+	*rds.CreateDBClusterParameterGroupOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBClusterParameterGroupName)
+	group, err := f.createParameterGroup(
+		f.clusterParameterGroups,
+		f.arn("cluster-pg", name),
+		aws.StringValue(input.DBParameterGroupFamily),
+		name,
+		aws.StringValue(input.Description),
+	)
+	if err != nil {
+		return nil, err
+	}
+	f.addTags(group.arn, input.Tags)
+
+	return &rds.CreateDBClusterParameterGroupOutput{
+		DBClusterParameterGroup: group.dbClusterParameterGroup(),
+	}, nil
+}
+
+func (f *RDS) DescribeDBClusterParameterGroups(input *rds.DescribeDBClusterParameterGroupsInput) (
+	*rds.DescribeDBClusterParameterGroupsOutput, error,
+) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	groups := make([]*parameterGroup, 0)
+	if input.DBClusterParameterGroupName != nil {
+		group, ok := f.clusterParameterGroups[*input.DBClusterParameterGroupName]
+		if !ok {
+			return nil, f.notFound(
+				rds.ErrCodeDBParameterGroupNotFoundFault,
+				"DBClusterParameterGroup not found: %s", *input.DBClusterParameterGroupName,
+			)
+		}
+		groups = append(groups, group)
+	} else {
+		for _, name := range sortedKeys(f.clusterParameterGroups) {
+			groups = append(groups, f.clusterParameterGroups[name])
+		}
+	}
+
+	start, end, marker, err := f.page(len(groups), input.Marker, input.MaxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &rds.DescribeDBClusterParameterGroupsOutput{Marker: marker}
+	for _, group := range groups[start:end] {
+		output.DBClusterParameterGroups = append(
+			output.DBClusterParameterGroups, group.dbClusterParameterGroup(),
+		)
+	}
+
+	return output, nil
+}
+
+func (f *RDS) DescribeDBClusterParameters(input *rds.DescribeDBClusterParametersInput) (
+	*rds.DescribeDBClusterParametersOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBClusterParameterGroupName)
+	group, ok := f.clusterParameterGroups[name]
+	if !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBParameterGroupNotFoundFault, "DBClusterParameterGroup not found: %s", name,
+		)
+	}
+
+	params, marker, err := f.describeParameters(group, input.Marker, input.MaxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rds.DescribeDBClusterParametersOutput{Parameters: params, Marker: marker}, nil
+}
+
+func (f *RDS) ModifyDBClusterParameterGroup(input *rds.ModifyDBClusterParameterGroupInput) (
+	*rds.DBClusterParameterGroupNameMessage, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBClusterParameterGroupName)
+	group, ok := f.clusterParameterGroups[name]
+	if !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBParameterGroupNotFoundFault, "DBClusterParameterGroup not found: %s", name,
+		)
+	}
+
+	if defaultParameterGroup(name) {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBParameterGroupStateFault, "Default parameter groups cannot be modified",
+		)
+	}
+
+	err := f.modifyParameters(group, input.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rds.DBClusterParameterGroupNameMessage{DBClusterParameterGroupName: aws.String(name)}, nil
+}
+
+func (f *RDS) DeleteDBClusterParameterGroup(input *rds.DeleteDBClusterParameterGroupInput) (
+	*rds.DeleteDBClusterParameterGroupOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBClusterParameterGroupName)
+	if _, ok := f.clusterParameterGroups[name]; !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBClusterParameterGroupNotFoundFault, "DBClusterParameterGroup not found: %s", name,
+		)
+	}
+
+	if defaultParameterGroup(name) {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBParameterGroupStateFault, "Default parameter groups cannot be deleted",
+		)
+	}
+
+	for _, c := range f.clusters {
+		if aws.StringValue(c.DBClusterParameterGroup) == name {
+			return nil, f.badRequest(
+				rds.ErrCodeInvalidDBParameterGroupStateFault,
+				"One or more database clusters are still members of this parameter group %s",
+				name,
+			)
+		}
+	}
+
+	delete(f.tags, f.clusterParameterGroups[name].arn)
+	delete(f.clusterParameterGroups, name)
+	return &rds.DeleteDBClusterParameterGroupOutput{}, nil
+}
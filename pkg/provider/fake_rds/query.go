@@ -0,0 +1,157 @@
+package fake_rds
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// decodeQuery fills the input struct pointed to by v from Query API form
+// values. Names follow the same rules the SDK uses to encode them.
+func decodeQuery(values url.Values, v reflect.Value) error {
+	return decodeStruct(values, v.Elem(), "")
+}
+
+func decodeStruct(values url.Values, v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("ignore") != "" {
+			continue
+		}
+
+		name := queryName(field)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if err := decodeValue(values, v.Field(i), name, field.Tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func queryName(field reflect.StructField) string {
+	if field.Tag.Get("flattened") != "" && field.Tag.Get("locationNameList") != "" {
+		return field.Tag.Get("locationNameList")
+	}
+	if name := field.Tag.Get("locationName"); name != "" {
+		return name
+	}
+
+	return field.Name
+}
+
+func decodeValue(values url.Values, v reflect.Value, name string, tag reflect.StructTag) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		return decodeList(values, v, name, tag)
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() == reflect.Struct && v.Type().Elem() != reflect.TypeOf(time.Time{}) {
+			if !hasPrefix(values, name+".") {
+				return nil
+			}
+			s := reflect.New(v.Type().Elem())
+			if err := decodeStruct(values, s.Elem(), name); err != nil {
+				return err
+			}
+			v.Set(s)
+			return nil
+		}
+
+		if _, ok := values[name]; !ok {
+			return nil
+		}
+		return decodeScalar(v, name, values.Get(name))
+	}
+
+	return nil
+}
+
+func decodeList(values url.Values, v reflect.Value, name string, tag reflect.StructTag) error {
+	if s, ok := values[name]; ok && len(s) == 1 && s[0] == "" {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		return nil
+	}
+
+	prefix := name
+	if tag.Get("flattened") == "" {
+		member := tag.Get("locationNameList")
+		if member == "" {
+			member = "member"
+		}
+		prefix += "." + member
+	}
+
+	list := reflect.MakeSlice(v.Type(), 0, 0)
+	for i := 1; ; i++ {
+		itemName := prefix + "." + strconv.Itoa(i)
+		if _, ok := values[itemName]; !ok && !hasPrefix(values, itemName+".") {
+			break
+		}
+
+		item := reflect.New(v.Type().Elem()).Elem()
+		if err := decodeValue(values, item, itemName, ""); err != nil {
+			return err
+		}
+		list = reflect.Append(list, item)
+	}
+
+	if list.Len() > 0 {
+		v.Set(list)
+	}
+
+	return nil
+}
+
+func decodeScalar(v reflect.Value, name, value string) error {
+	switch v.Interface().(type) {
+	case *string:
+		v.Set(reflect.ValueOf(aws.String(value)))
+	case *int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %s", name, value)
+		}
+		v.Set(reflect.ValueOf(aws.Int64(i)))
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %s", name, value)
+		}
+		v.Set(reflect.ValueOf(aws.Bool(b)))
+	case *float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %s", name, value)
+		}
+		v.Set(reflect.ValueOf(aws.Float64(f)))
+	case *time.Time:
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %s", name, value)
+		}
+		v.Set(reflect.ValueOf(aws.Time(t)))
+	default:
+		return fmt.Errorf("unsupported parameter %s", name)
+	}
+
+	return nil
+}
+
+func hasPrefix(values url.Values, prefix string) bool {
+	for k := range values {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package fake_rds
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+// restoreCluster creates a cluster from input which then takes the master
+// user and encryption settings of the snapshot or cluster it is restored from
+func (f *RDS) restoreCluster(input *rds.CreateDBClusterInput, masterUsername string, encrypted bool) (
+	*rds.DBCluster, error,
+) {
+	c, err := f.createCluster(input)
+	if err != nil {
+		return nil, err
+	}
+
+	c.MasterUsername = aws.String(masterUsername)
+	c.StorageEncrypted = aws.Bool(encrypted || aws.StringValue(input.KmsKeyId) != "")
+
+	return c, nil
+}
+
+func (f *RDS) RestoreDBClusterFromSnapshot(input *rds.RestoreDBClusterFromSnapshotInput) (
+	*rds.RestoreDBClusterFromSnapshotOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.findClusterSnapshot(aws.StringValue(input.SnapshotIdentifier))
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.StringValue(s.Status) != statusAvailable {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterSnapshotStateFault,
+			"DBClusterSnapshot %s is not in available state (%s)",
+			aws.StringValue(s.DBClusterSnapshotIdentifier), aws.StringValue(s.Status),
+		)
+	}
+
+	engineVersion := input.EngineVersion
+	if engineVersion == nil {
+		engineVersion = s.EngineVersion
+	}
+
+	kmsKeyId := input.KmsKeyId
+	if kmsKeyId == nil {
+		kmsKeyId = s.KmsKeyId
+	}
+
+	c, err := f.restoreCluster(&rds.CreateDBClusterInput{
+		AvailabilityZones:           input.AvailabilityZones,
+		DBClusterIdentifier:         input.DBClusterIdentifier,
+		DBClusterParameterGroupName: input.DBClusterParameterGroupName,
+		DBSubnetGroupName:           input.DBSubnetGroupName,
+		DatabaseName:                input.DatabaseName,
+		DeletionProtection:          input.DeletionProtection,
+		Engine:                      input.Engine,
+		EngineVersion:               engineVersion,
+		KmsKeyId:                    kmsKeyId,
+		Port:                        input.Port,
+		Tags:                        input.Tags,
+		VpcSecurityGroupIds:         input.VpcSecurityGroupIds,
+	}, aws.StringValue(s.MasterUsername), aws.BoolValue(s.StorageEncrypted))
+	if err != nil {
+		return nil, err
+	}
+
+	return &rds.RestoreDBClusterFromSnapshotOutput{DBCluster: copyOf(c).(*rds.DBCluster)}, nil
+}
+
+func (f *RDS) RestoreDBClusterToPointInTime(input *rds.RestoreDBClusterToPointInTimeInput) (
+	*rds.RestoreDBClusterToPointInTimeOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	sourceId := aws.StringValue(input.SourceDBClusterIdentifier)
+	source, ok := f.clusters[sourceId]
+	if !ok {
+		return nil, f.notFound(rds.ErrCodeDBClusterNotFoundFault, "DBCluster %s not found.", sourceId)
+	}
+
+	latest := aws.BoolValue(input.UseLatestRestorableTime)
+	if latest == (input.RestoreToTime != nil) {
+		return nil, f.badRequest(
+			errCodeInvalidParameterCombination,
+			"Exactly one of RestoreToTime or UseLatestRestorableTime must be specified.",
+		)
+	}
+
+	if !latest {
+		restoreTime := aws.TimeValue(input.RestoreToTime)
+		if restoreTime.Before(aws.TimeValue(source.EarliestRestorableTime)) || restoreTime.After(time.Now()) {
+			return nil, f.badRequest(
+				rds.ErrCodeInvalidRestoreFault,
+				"The restore time %s is outside the restorable window of DBCluster %s.",
+				restoreTime.UTC().Format(time.RFC3339), sourceId,
+			)
+		}
+	}
+
+	kmsKeyId := input.KmsKeyId
+	if kmsKeyId == nil {
+		kmsKeyId = source.KmsKeyId
+	}
+
+	c, err := f.restoreCluster(&rds.CreateDBClusterInput{
+		DBClusterIdentifier:         input.DBClusterIdentifier,
+		DBClusterParameterGroupName: input.DBClusterParameterGroupName,
+		DBSubnetGroupName:           input.DBSubnetGroupName,
+		DeletionProtection:          input.DeletionProtection,
+		Engine:                      source.Engine,
+		EngineVersion:               source.EngineVersion,
+		KmsKeyId:                    kmsKeyId,
+		Port:                        input.Port,
+		Tags:                        input.Tags,
+		VpcSecurityGroupIds:         input.VpcSecurityGroupIds,
+	}, aws.StringValue(source.MasterUsername), aws.BoolValue(source.StorageEncrypted))
+	if err != nil {
+		return nil, err
+	}
+
+	return &rds.RestoreDBClusterToPointInTimeOutput{DBCluster: copyOf(c).(*rds.DBCluster)}, nil
+}
@@ -0,0 +1,189 @@
+package fake_rds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	log "github.com/sirupsen/logrus"
+)
+
+const xmlNamespace = "http://rds.amazonaws.com/doc/2014-10-31/"
+
+var (
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Server serves the RDS Query API backed by an in-memory RDS so the SDK
+// client can be pointed at it with an endpoint override
+type Server struct {
+	RDS *RDS
+}
+
+func NewServer(f *RDS) *Server {
+	return &Server{RDS: f}
+}
+
+type errorResponse struct {
+	XMLName   xml.Name `xml:"ErrorResponse"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	Type      string   `xml:"Error>Type"`
+	Code      string   `xml:"Error>Code"`
+	Message   string   `xml:"Error>Message"`
+	RequestId string   `xml:"RequestId"`
+}
+
+type responseMetadata struct {
+	RequestId string
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.writeError(w, http.StatusBadRequest, "MalformedQueryString", err.Error())
+		return
+	}
+
+	action := r.Form.Get("Action")
+	method, ok := s.operation(action)
+	if !ok {
+		s.writeError(
+			w, http.StatusBadRequest, "InvalidAction",
+			fmt.Sprintf("The action %s is not valid for this web service.", action),
+		)
+		return
+	}
+
+	input := reflect.New(method.Type().In(0).Elem())
+	if err := decodeQuery(r.Form, input); err != nil {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidParameterValue, err.Error())
+		return
+	}
+
+	log.Debugf("fake rds %s", action)
+	results := method.Call([]reflect.Value{input})
+	if err, ok := results[1].Interface().(error); ok && err != nil {
+		s.writeFault(w, err)
+		return
+	}
+
+	requestId := s.requestId()
+
+	buf := &bytes.Buffer{}
+	e := xml.NewEncoder(buf)
+	response := xml.StartElement{
+		Name: xml.Name{Local: action + "Response"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: xmlNamespace}},
+	}
+	result := xml.StartElement{Name: xml.Name{Local: action + "Result"}}
+
+	err := e.EncodeToken(response)
+	if err == nil {
+		err = e.EncodeToken(result)
+	}
+	if err == nil {
+		err = encodeXML(e, results[0])
+	}
+	if err == nil {
+		err = e.EncodeToken(result.End())
+	}
+	if err == nil {
+		err = e.EncodeElement(responseMetadata{RequestId: requestId}, xml.StartElement{
+			Name: xml.Name{Local: "ResponseMetadata"},
+		})
+	}
+	if err == nil {
+		err = e.EncodeToken(response.End())
+	}
+	if err == nil {
+		err = e.Flush()
+	}
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "InternalFailure", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.Header().Set("X-Amzn-Requestid", requestId)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// operation finds the method of the fake implementing action. Only methods
+// shaped like the SDK operations, func(*XInput) (*XOutput, error), are served.
+func (s *Server) operation(action string) (reflect.Value, bool) {
+	if action == "" {
+		return reflect.Value{}, false
+	}
+
+	method := reflect.ValueOf(s.RDS).MethodByName(action)
+	if !method.IsValid() {
+		return reflect.Value{}, false
+	}
+
+	t := method.Type()
+	if t.NumIn() != 1 || t.NumOut() != 2 || t.Out(1) != errorType {
+		return reflect.Value{}, false
+	}
+
+	in := t.In(0)
+	if in.Kind() != reflect.Ptr || in.Elem().Name() != action+"Input" {
+		return reflect.Value{}, false
+	}
+
+	return method, true
+}
+
+func (s *Server) requestId() string {
+	s.RDS.mu.Lock()
+	defer s.RDS.mu.Unlock()
+
+	s.RDS.requestCount++
+	return fmt.Sprintf("fake-request-%d", s.RDS.requestCount)
+}
+
+// writeFault writes err the way RDS reports faults
+func (s *Server) writeFault(w http.ResponseWriter, err error) {
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		s.writeErrorWithId(w, reqErr.StatusCode(), reqErr.Code(), reqErr.Message(), reqErr.RequestID())
+		return
+	}
+
+	if aerr, ok := err.(awserr.Error); ok {
+		s.writeError(w, http.StatusBadRequest, errCodeInvalidParameterValue, aerr.Error())
+		return
+	}
+
+	s.writeError(w, http.StatusInternalServerError, "InternalFailure", err.Error())
+}
+
+func (s *Server) writeError(w http.ResponseWriter, statusCode int, code, message string) {
+	s.writeErrorWithId(w, statusCode, code, message, s.requestId())
+}
+
+func (s *Server) writeErrorWithId(w http.ResponseWriter, statusCode int, code, message, requestId string) {
+	errType := "Sender"
+	if statusCode >= http.StatusInternalServerError {
+		errType = "Receiver"
+	}
+
+	body, err := xml.Marshal(errorResponse{
+		Xmlns:     xmlNamespace,
+		Type:      errType,
+		Code:      code,
+		Message:   strings.TrimSpace(message),
+		RequestId: requestId,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.Header().Set("X-Amzn-Requestid", requestId)
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
@@ -0,0 +1,372 @@
+package fake_rds
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+const (
+	snapshotTypeManual = "manual"
+
+	statusCopying = "copying"
+
+	// attributeRestore lists the accounts a snapshot is shared with
+	attributeRestore = "restore"
+)
+
+// newClusterSnapshot records a snapshot of the cluster's current state
+func (f *RDS) newClusterSnapshot(c *rds.DBCluster, snapshotId, snapshotType string) (*rds.DBClusterSnapshot, error) {
+	if _, ok := f.clusterSnapshots[snapshotId]; ok {
+		return nil, f.badRequest(
+			rds.ErrCodeDBClusterSnapshotAlreadyExistsFault,
+			"Cannot create the cluster snapshot because one with the identifier %s already exists.", snapshotId,
+		)
+	}
+
+	s := &rds.DBClusterSnapshot{
+		AllocatedStorage:            aws.Int64(aws.Int64Value(c.AllocatedStorage)),
+		AvailabilityZones:           aws.StringSlice(stringValues(c.AvailabilityZones)),
+		ClusterCreateTime:           aws.Time(aws.TimeValue(c.ClusterCreateTime)),
+		DBClusterIdentifier:         aws.String(aws.StringValue(c.DBClusterIdentifier)),
+		DBClusterSnapshotArn:        aws.String(f.arn("cluster-snapshot", snapshotId)),
+		DBClusterSnapshotIdentifier: aws.String(snapshotId),
+		Engine:                      aws.String(aws.StringValue(c.Engine)),
+		EngineVersion:               aws.String(aws.StringValue(c.EngineVersion)),
+		KmsKeyId:                    c.KmsKeyId,
+		MasterUsername:              aws.String(aws.StringValue(c.MasterUsername)),
+		PercentProgress:             aws.Int64(100),
+		Port:                        aws.Int64(aws.Int64Value(c.Port)),
+		SnapshotCreateTime:          aws.Time(time.Now().UTC()),
+		SnapshotType:                aws.String(snapshotType),
+		Status:                      aws.String(statusAvailable),
+		StorageEncrypted:            aws.Bool(aws.BoolValue(c.StorageEncrypted)),
+		VpcId:                       aws.String("vpc-fake"),
+	}
+	f.clusterSnapshots[snapshotId] = s
+
+	return s, nil
+}
+
+// advanceClusterSnapshots moves snapshots one step along their lifecycle
+func (f *RDS) advanceClusterSnapshots() {
+	for id, s := range f.clusterSnapshots {
+		switch aws.StringValue(s.Status) {
+		case statusCreating, statusCopying:
+			s.Status = aws.String(statusAvailable)
+		case statusDeleting:
+			delete(f.clusterSnapshots, id)
+			delete(f.clusterSnapshotShares, id)
+			delete(f.tags, aws.StringValue(s.DBClusterSnapshotArn))
+		}
+	}
+}
+
+// findClusterSnapshot returns the snapshot identified by an id or an ARN of
+// the fake's region and account
+func (f *RDS) findClusterSnapshot(idOrArn string) (*rds.DBClusterSnapshot, error) {
+	id := strings.TrimPrefix(idOrArn, f.arn("cluster-snapshot", ""))
+
+	s, ok := f.clusterSnapshots[id]
+	if !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBClusterSnapshotNotFoundFault, "DBClusterSnapshot %s not found.", idOrArn,
+		)
+	}
+
+	return s, nil
+}
+
+// clusterSnapshotAttributes returns the attributes of the snapshot as RDS
+// reports them
+func (f *RDS) clusterSnapshotAttributes(id string) *rds.DBClusterSnapshotAttributesResult {
+	return &rds.DBClusterSnapshotAttributesResult{
+		DBClusterSnapshotIdentifier: aws.String(id),
+		DBClusterSnapshotAttributes: []*rds.DBClusterSnapshotAttribute{
+			{
+				AttributeName:   aws.String(attributeRestore),
+				AttributeValues: aws.StringSlice(append([]string{}, f.clusterSnapshotShares[id]...)),
+			},
+		},
+	}
+}
+
+func (f *RDS) CreateDBClusterSnapshot(input *rds.CreateDBClusterSnapshotInput) (
+	*rds.CreateDBClusterSnapshotOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	clusterId := aws.StringValue(input.DBClusterIdentifier)
+	c, ok := f.clusters[clusterId]
+	if !ok {
+		return nil, f.notFound(rds.ErrCodeDBClusterNotFoundFault, "DBCluster %s not found.", clusterId)
+	}
+
+	if aws.StringValue(c.Status) != statusAvailable {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterStateFault,
+			"DBCluster %s is not in available state (%s)", clusterId, aws.StringValue(c.Status),
+		)
+	}
+
+	s, err := f.newClusterSnapshot(c, aws.StringValue(input.DBClusterSnapshotIdentifier), snapshotTypeManual)
+	if err != nil {
+		return nil, err
+	}
+	s.Status = aws.String(statusCreating)
+	s.PercentProgress = aws.Int64(0)
+	f.addTags(aws.StringValue(s.DBClusterSnapshotArn), input.Tags)
+
+	return &rds.CreateDBClusterSnapshotOutput{DBClusterSnapshot: copyOf(s).(*rds.DBClusterSnapshot)}, nil
+}
+
+func (f *RDS) DescribeDBClusterSnapshots(input *rds.DescribeDBClusterSnapshotsInput) (
+	*rds.DescribeDBClusterSnapshotsOutput, error,
+) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.advanceClusterSnapshots()
+
+	filters, err := f.filterValues(
+		input.Filters, "db-cluster-id", "db-cluster-snapshot-id", "snapshot-type", "engine",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]*rds.DBClusterSnapshot, 0)
+	if input.DBClusterSnapshotIdentifier != nil {
+		s, err := f.findClusterSnapshot(*input.DBClusterSnapshotIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	} else {
+		for _, id := range sortedKeys(f.clusterSnapshots) {
+			s := f.clusterSnapshots[id]
+			clusterId := aws.StringValue(s.DBClusterIdentifier)
+			if input.DBClusterIdentifier != nil && aws.StringValue(input.DBClusterIdentifier) != clusterId {
+				continue
+			}
+			// every snapshot of the fake is owned by its account, none are
+			// shared with it or public
+			if input.SnapshotType != nil && aws.StringValue(input.SnapshotType) != aws.StringValue(s.SnapshotType) {
+				continue
+			}
+			if matchesFilter(filters, "db-cluster-id", clusterId, f.arn("cluster", clusterId)) &&
+				matchesFilter(filters, "db-cluster-snapshot-id", id, aws.StringValue(s.DBClusterSnapshotArn)) &&
+				matchesFilter(filters, "snapshot-type", aws.StringValue(s.SnapshotType)) &&
+				matchesFilter(filters, "engine", aws.StringValue(s.Engine)) {
+				snapshots = append(snapshots, s)
+			}
+		}
+	}
+
+	start, end, marker, err := f.page(len(snapshots), input.Marker, input.MaxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &rds.DescribeDBClusterSnapshotsOutput{Marker: marker}
+	for _, s := range snapshots[start:end] {
+		output.DBClusterSnapshots = append(output.DBClusterSnapshots, copyOf(s).(*rds.DBClusterSnapshot))
+	}
+
+	return output, nil
+}
+
+func (f *RDS) DescribeDBClusterSnapshotsRequest(input *rds.DescribeDBClusterSnapshotsInput) (
+	*request.Request, *rds.DescribeDBClusterSnapshotsOutput,
+) {
+	output := &rds.DescribeDBClusterSnapshotsOutput{}
+	req := f.newRequest("DescribeDBClusterSnapshots", input, output, func() error {
+		result, err := f.DescribeDBClusterSnapshots(input)
+		if err != nil {
+			return err
+		}
+		*output = *result
+		return nil
+	})
+
+	return req, output
+}
+
+func (f *RDS) CopyDBClusterSnapshot(input *rds.CopyDBClusterSnapshotInput) (*rds.CopyDBClusterSnapshotOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	source, err := f.findClusterSnapshot(aws.StringValue(input.SourceDBClusterSnapshotIdentifier))
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.StringValue(source.Status) != statusAvailable {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterSnapshotStateFault,
+			"DBClusterSnapshot %s is not in available state (%s)",
+			aws.StringValue(source.DBClusterSnapshotIdentifier), aws.StringValue(source.Status),
+		)
+	}
+
+	kmsKeyId := aws.StringValue(input.KmsKeyId)
+	if kmsKeyId != "" && !aws.BoolValue(source.StorageEncrypted) {
+		return nil, f.badRequest(
+			errCodeInvalidParameterCombination,
+			"KmsKeyId can not be specified when copying an unencrypted DB cluster snapshot.",
+		)
+	}
+
+	targetId := aws.StringValue(input.TargetDBClusterSnapshotIdentifier)
+	if _, ok := f.clusterSnapshots[targetId]; ok {
+		return nil, f.badRequest(
+			rds.ErrCodeDBClusterSnapshotAlreadyExistsFault,
+			"Cannot create the cluster snapshot because one with the identifier %s already exists.", targetId,
+		)
+	}
+
+	s := copyOf(source).(*rds.DBClusterSnapshot)
+	s.DBClusterSnapshotArn = aws.String(f.arn("cluster-snapshot", targetId))
+	s.DBClusterSnapshotIdentifier = aws.String(targetId)
+	s.SnapshotCreateTime = aws.Time(time.Now().UTC())
+	s.SnapshotType = aws.String(snapshotTypeManual)
+	s.SourceDBClusterSnapshotArn = aws.String(aws.StringValue(source.DBClusterSnapshotArn))
+	s.Status = aws.String(statusCopying)
+	if kmsKeyId != "" {
+		s.KmsKeyId = aws.String(kmsKeyId)
+	}
+	f.clusterSnapshots[targetId] = s
+
+	arn := aws.StringValue(s.DBClusterSnapshotArn)
+	if aws.BoolValue(input.CopyTags) {
+		f.addTags(arn, f.tags[aws.StringValue(source.DBClusterSnapshotArn)])
+	}
+	f.addTags(arn, input.Tags)
+
+	return &rds.CopyDBClusterSnapshotOutput{DBClusterSnapshot: copyOf(s).(*rds.DBClusterSnapshot)}, nil
+}
+
+func (f *RDS) DescribeDBClusterSnapshotAttributes(input *rds.DescribeDBClusterSnapshotAttributesInput) (
+	*rds.DescribeDBClusterSnapshotAttributesOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.findClusterSnapshot(aws.StringValue(input.DBClusterSnapshotIdentifier))
+	if err != nil {
+		return nil, err
+	}
+
+	return &rds.DescribeDBClusterSnapshotAttributesOutput{
+		DBClusterSnapshotAttributesResult: f.clusterSnapshotAttributes(aws.StringValue(s.DBClusterSnapshotIdentifier)),
+	}, nil
+}
+
+func (f *RDS) ModifyDBClusterSnapshotAttribute(input *rds.ModifyDBClusterSnapshotAttributeInput) (
+	*rds.ModifyDBClusterSnapshotAttributeOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, err := f.findClusterSnapshot(aws.StringValue(input.DBClusterSnapshotIdentifier))
+	if err != nil {
+		return nil, err
+	}
+	id := aws.StringValue(s.DBClusterSnapshotIdentifier)
+
+	if name := aws.StringValue(input.AttributeName); name != attributeRestore {
+		return nil, f.badRequest(errCodeInvalidParameterValue, "Invalid attribute name: %s", name)
+	}
+
+	if aws.StringValue(s.SnapshotType) != snapshotTypeManual {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterSnapshotStateFault, "Only manual snapshots can be shared: %s", id,
+		)
+	}
+
+	add := stringValues(input.ValuesToAdd)
+	for _, account := range add {
+		if account == "all" && aws.BoolValue(s.StorageEncrypted) {
+			return nil, f.badRequest(
+				errCodeInvalidParameterValue, "Encrypted DB cluster snapshots can not be made public.",
+			)
+		}
+	}
+
+	shared := make(map[string]bool)
+	for _, account := range f.clusterSnapshotShares[id] {
+		shared[account] = true
+	}
+	for _, account := range add {
+		shared[account] = true
+	}
+	for _, account := range stringValues(input.ValuesToRemove) {
+		delete(shared, account)
+	}
+
+	accounts := make([]string, 0, len(shared))
+	for account := range shared {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	f.clusterSnapshotShares[id] = accounts
+
+	return &rds.ModifyDBClusterSnapshotAttributeOutput{
+		DBClusterSnapshotAttributesResult: f.clusterSnapshotAttributes(id),
+	}, nil
+}
+
+func (f *RDS) DeleteDBClusterSnapshot(input *rds.DeleteDBClusterSnapshotInput) (
+	*rds.DeleteDBClusterSnapshotOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := aws.StringValue(input.DBClusterSnapshotIdentifier)
+	s, ok := f.clusterSnapshots[id]
+	if !ok {
+		return nil, f.notFound(rds.ErrCodeDBClusterSnapshotNotFoundFault, "DBClusterSnapshot %s not found.", id)
+	}
+
+	if aws.StringValue(s.SnapshotType) != snapshotTypeManual {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterSnapshotStateFault, "Only manual snapshots can be deleted: %s", id,
+		)
+	}
+
+	if aws.StringValue(s.Status) != statusAvailable {
+		return nil, f.badRequest(
+			rds.ErrCodeInvalidDBClusterSnapshotStateFault,
+			"DBClusterSnapshot %s is not in available state (%s)", id, aws.StringValue(s.Status),
+		)
+	}
+
+	s.Status = aws.String(statusDeleting)
+
+	return &rds.DeleteDBClusterSnapshotOutput{DBClusterSnapshot: copyOf(s).(*rds.DBClusterSnapshot)}, nil
+}
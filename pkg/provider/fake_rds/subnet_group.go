@@ -0,0 +1,164 @@
+package fake_rds
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+func (f *RDS) CreateDBSubnetGroup(input *rds.CreateDBSubnetGroupInput) (*rds.CreateDBSubnetGroupOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBSubnetGroupName)
+	if _, ok := f.subnetGroups[name]; ok {
+		return nil, f.badRequest(
+			rds.ErrCodeDBSubnetGroupAlreadyExistsFault, "DB Subnet Group %s already exists", name,
+		)
+	}
+
+	subnets, err := f.subnets(input.SubnetIds)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &rds.DBSubnetGroup{
+		DBSubnetGroupArn:         aws.String(f.arn("subgrp", name)),
+		DBSubnetGroupDescription: input.DBSubnetGroupDescription,
+		DBSubnetGroupName:        aws.String(name),
+		SubnetGroupStatus:        aws.String("Complete"),
+		Subnets:                  subnets,
+		VpcId:                    aws.String("vpc-fake"),
+	}
+	f.subnetGroups[name] = group
+	f.addTags(aws.StringValue(group.DBSubnetGroupArn), input.Tags)
+
+	return &rds.CreateDBSubnetGroupOutput{
+		DBSubnetGroup: copyOf(group).(*rds.DBSubnetGroup),
+	}, nil
+}
+
+// subnets places each subnet in an availability zone, spreading them across
+// the zones of the region in order
+func (f *RDS) subnets(subnetIds []*string) ([]*rds.Subnet, error) {
+	azs := f.availabilityZones()
+	subnets := make([]*rds.Subnet, 0)
+	covered := make(map[string]bool)
+
+	for i, id := range subnetIds {
+		az := azs[i%len(azs)]
+		covered[az] = true
+		subnets = append(subnets, &rds.Subnet{
+			SubnetAvailabilityZone: &rds.AvailabilityZone{Name: aws.String(az)},
+			SubnetIdentifier:       aws.String(aws.StringValue(id)),
+			SubnetStatus:           aws.String("Active"),
+		})
+	}
+
+	if len(covered) < 2 {
+		return nil, f.badRequest(
+			rds.ErrCodeDBSubnetGroupDoesNotCoverEnoughAZs,
+			"The DB subnet group doesn't meet availability zone coverage requirement. "+
+				"Please add subnets to cover at least 2 availability zones.",
+		)
+	}
+
+	return subnets, nil
+}
+
+func (f *RDS) DescribeDBSubnetGroups(input *rds.DescribeDBSubnetGroupsInput) (*rds.DescribeDBSubnetGroupsOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	groups := make([]*rds.DBSubnetGroup, 0)
+	if input.DBSubnetGroupName != nil {
+		group, ok := f.subnetGroups[*input.DBSubnetGroupName]
+		if !ok {
+			return nil, f.notFound(
+				rds.ErrCodeDBSubnetGroupNotFoundFault,
+				"DB Subnet Group %s not found", *input.DBSubnetGroupName,
+			)
+		}
+		groups = append(groups, group)
+	} else {
+		for _, name := range sortedKeys(f.subnetGroups) {
+			groups = append(groups, f.subnetGroups[name])
+		}
+	}
+
+	start, end, marker, err := f.page(len(groups), input.Marker, input.MaxRecords)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &rds.DescribeDBSubnetGroupsOutput{Marker: marker}
+	for _, group := range groups[start:end] {
+		output.DBSubnetGroups = append(output.DBSubnetGroups, copyOf(group).(*rds.DBSubnetGroup))
+	}
+
+	return output, nil
+}
+
+func (f *RDS) ModifyDBSubnetGroup(input *rds.ModifyDBSubnetGroupInput) (*rds.ModifyDBSubnetGroupOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBSubnetGroupName)
+	group, ok := f.subnetGroups[name]
+	if !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBSubnetGroupNotFoundFault, "DB Subnet Group %s not found", name,
+		)
+	}
+
+	subnets, err := f.subnets(input.SubnetIds)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Subnets = subnets
+	if input.DBSubnetGroupDescription != nil {
+		group.DBSubnetGroupDescription = aws.String(*input.DBSubnetGroupDescription)
+	}
+
+	return &rds.ModifyDBSubnetGroupOutput{
+		DBSubnetGroup: copyOf(group).(*rds.DBSubnetGroup),
+	}, nil
+}
+
+func (f *RDS) DeleteDBSubnetGroup(input *rds.DeleteDBSubnetGroupInput) (*rds.DeleteDBSubnetGroupOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := aws.StringValue(input.DBSubnetGroupName)
+	if _, ok := f.subnetGroups[name]; !ok {
+		return nil, f.notFound(
+			rds.ErrCodeDBSubnetGroupNotFoundFault, "DB Subnet Group %s not found", name,
+		)
+	}
+
+	for _, c := range f.clusters {
+		if aws.StringValue(c.DBSubnetGroup) == name {
+			return nil, f.badRequest(
+				rds.ErrCodeInvalidDBSubnetGroupStateFault,
+				"Cannot delete the subnet group '%s' because at least one database cluster: %s is still using it.",
+				name, aws.StringValue(c.DBClusterIdentifier),
+			)
+		}
+	}
+
+	delete(f.tags, f.arn("subgrp", name))
+	delete(f.subnetGroups, name)
+	return &rds.DeleteDBSubnetGroupOutput{}, nil
+}
@@ -0,0 +1,143 @@
+package fake_rds
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+// checkResource returns an error unless arn names an existing resource
+func (f *RDS) checkResource(arn string) error {
+	parts := strings.SplitN(arn, ":", 7)
+	if len(parts) != 7 || parts[0] != "arn" || parts[2] != rds.ServiceName {
+		return f.badRequest(errCodeInvalidParameterValue, "Invalid resource name: %s", arn)
+	}
+
+	id := parts[6]
+	switch parts[5] {
+	case "cluster":
+		if _, ok := f.clusters[id]; !ok {
+			return f.notFound(rds.ErrCodeDBClusterNotFoundFault, "DBCluster %s not found.", id)
+		}
+	case "db":
+		if _, ok := f.instances[id]; !ok {
+			return f.notFound(rds.ErrCodeDBInstanceNotFoundFault, "DBInstance %s not found.", id)
+		}
+	case "subgrp":
+		if _, ok := f.subnetGroups[id]; !ok {
+			return f.notFound(rds.ErrCodeDBSubnetGroupNotFoundFault, "DB Subnet Group %s not found", id)
+		}
+	case "pg":
+		if _, ok := f.parameterGroups[id]; !ok {
+			return f.notFound(rds.ErrCodeDBParameterGroupNotFoundFault, "DBParameterGroup not found: %s", id)
+		}
+	case "cluster-pg":
+		if _, ok := f.clusterParameterGroups[id]; !ok {
+			return f.notFound(
+				rds.ErrCodeDBParameterGroupNotFoundFault, "DBClusterParameterGroup not found: %s", id,
+			)
+		}
+	case "cluster-snapshot":
+		if _, ok := f.clusterSnapshots[id]; !ok {
+			return f.notFound(rds.ErrCodeDBClusterSnapshotNotFoundFault, "DBClusterSnapshot %s not found.", id)
+		}
+	default:
+		return f.badRequest(errCodeInvalidParameterValue, "Unsupported resource type: %s", parts[5])
+	}
+
+	return nil
+}
+
+// addTags sets tags on the resource, replacing the values of existing keys
+func (f *RDS) addTags(arn string, tags []*rds.Tag) {
+	for _, tag := range tags {
+		key := aws.StringValue(tag.Key)
+
+		replaced := false
+		for _, t := range f.tags[arn] {
+			if aws.StringValue(t.Key) == key {
+				t.Value = aws.String(aws.StringValue(tag.Value))
+				replaced = true
+			}
+		}
+
+		if !replaced {
+			f.tags[arn] = append(f.tags[arn], &rds.Tag{
+				Key:   aws.String(key),
+				Value: aws.String(aws.StringValue(tag.Value)),
+			})
+		}
+	}
+}
+
+func (f *RDS) ListTagsForResource(input *rds.ListTagsForResourceInput) (*rds.ListTagsForResourceOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	arn := aws.StringValue(input.ResourceName)
+	if err := f.checkResource(arn); err != nil {
+		return nil, err
+	}
+
+	output := &rds.ListTagsForResourceOutput{TagList: make([]*rds.Tag, 0)}
+	for _, t := range f.tags[arn] {
+		output.TagList = append(output.TagList, copyOf(t).(*rds.Tag))
+	}
+
+	return output, nil
+}
+
+func (f *RDS) AddTagsToResource(input *rds.AddTagsToResourceInput) (*rds.AddTagsToResourceOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	arn := aws.StringValue(input.ResourceName)
+	if err := f.checkResource(arn); err != nil {
+		return nil, err
+	}
+
+	f.addTags(arn, input.Tags)
+	return &rds.AddTagsToResourceOutput{}, nil
+}
+
+func (f *RDS) RemoveTagsFromResource(input *rds.RemoveTagsFromResourceInput) (
+	*rds.RemoveTagsFromResourceOutput, error,
+) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	arn := aws.StringValue(input.ResourceName)
+	if err := f.checkResource(arn); err != nil {
+		return nil, err
+	}
+
+	remove := stringValues(input.TagKeys)
+	tags := make([]*rds.Tag, 0)
+	for _, t := range f.tags[arn] {
+		keep := true
+		for _, key := range remove {
+			if aws.StringValue(t.Key) == key {
+				keep = false
+			}
+		}
+		if keep {
+			tags = append(tags, t)
+		}
+	}
+	f.tags[arn] = tags
+
+	return &rds.RemoveTagsFromResourceOutput{}, nil
+}
@@ -0,0 +1,112 @@
+package fake_rds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const iso8601 = "2006-01-02T15:04:05Z"
+
+// encodeXML writes the output struct v as the children of the current element
+// using the element names the SDK's XML unmarshaler expects
+func encodeXML(e *xml.Encoder, v reflect.Value) error {
+	return encodeFields(e, v.Elem())
+}
+
+func encodeFields(e *xml.Encoder, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("ignore") != "" {
+			continue
+		}
+
+		name := field.Name
+		if locName := field.Tag.Get("locationName"); locName != "" {
+			name = locName
+		}
+
+		if err := encodeValue(e, v.Field(i), name, field.Tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func encodeValue(e *xml.Encoder, v reflect.Value, name string, tag reflect.StructTag) error {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Slice) && v.IsNil() {
+		return nil
+	}
+
+	if v.Kind() == reflect.Slice {
+		return encodeList(e, v, name, tag)
+	}
+
+	v = v.Elem()
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	if v.Kind() == reflect.Struct && v.Type() != reflect.TypeOf(time.Time{}) {
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		if err := encodeFields(e, v); err != nil {
+			return err
+		}
+		return e.EncodeToken(start.End())
+	}
+
+	text, err := scalarText(v)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	return e.EncodeElement(text, start)
+}
+
+func encodeList(e *xml.Encoder, v reflect.Value, name string, tag reflect.StructTag) error {
+	member := tag.Get("locationNameList")
+	if member == "" {
+		member = "member"
+	}
+
+	flattened := tag.Get("flattened") != ""
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if flattened {
+		member = name
+	} else if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		if err := encodeValue(e, v.Index(i), member, ""); err != nil {
+			return err
+		}
+	}
+
+	if flattened {
+		return nil
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+func scalarText(v reflect.Value) (string, error) {
+	switch value := v.Interface().(type) {
+	case string:
+		return value, nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case time.Time:
+		return value.UTC().Format(iso8601), nil
+	}
+
+	return "", fmt.Errorf("unsupported value type %s", v.Type())
+}
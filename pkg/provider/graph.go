@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultConcurrency is the number of nodes a Graph runs at once when no
+	// limit is given
+	DefaultConcurrency = 4
+)
+
+// Node is a single resource operation in a Graph
+type Node struct {
+	Kind string
+	Id   string
+	Run  func() error
+	// Settle blocks until the outcome of Run can be relied on, e.g. until a
+	// deleted resource no longer exists (optional). Nodes waiting on the node
+	// start once it has returned.
+	Settle func() error
+
+	deps []string
+}
+
+func (n *Node) key() string {
+	return nodeKey(n.Kind, n.Id)
+}
+
+func nodeKey(kind, id string) string {
+	return fmt.Sprintf("%s/%s", kind, id)
+}
+
+// Graph is a set of resource operations and the dependencies between them
+type Graph struct {
+	nodes map[string]*Node
+	order []string
+}
+
+func NewGraph() *Graph {
+	return &Graph{
+		nodes: make(map[string]*Node),
+		order: make([]string, 0),
+	}
+}
+
+// AddNode adds an operation on the resource identified by kind and id
+func (g *Graph) AddNode(kind, id string, run func() error) {
+	n := &Node{Kind: kind, Id: id, Run: run, deps: make([]string, 0)}
+	if _, ok := g.nodes[n.key()]; !ok {
+		g.order = append(g.order, n.key())
+	}
+	g.nodes[n.key()] = n
+}
+
+// SetSettle sets the Settle function of the operation on the resource
+// identified by kind and id
+func (g *Graph) SetSettle(kind, id string, settle func() error) {
+	if n, ok := g.nodes[nodeKey(kind, id)]; ok {
+		n.Settle = settle
+	}
+}
+
+// HasNode reports whether the graph contains an operation on the resource
+func (g *Graph) HasNode(kind, id string) bool {
+	_, ok := g.nodes[nodeKey(kind, id)]
+	return ok
+}
+
+// AddDependency records that the resource identified by kind and id must be
+// operated on after the resource identified by depKind and depId. Dependencies
+// on resources which are not in the graph are ignored.
+func (g *Graph) AddDependency(kind, id, depKind, depId string) {
+	n, ok := g.nodes[nodeKey(kind, id)]
+	if !ok || !g.HasNode(depKind, depId) {
+		return
+	}
+
+	n.deps = append(n.deps, nodeKey(depKind, depId))
+}
+
+// Execute runs every node after the nodes it depends on. Independent nodes run
+// in parallel, at most concurrency at a time. Once a node fails no new nodes
+// are started and the first error is returned.
+func (g *Graph) Execute(concurrency int) error {
+	return g.execute(concurrency, g.dependencies())
+}
+
+// ExecuteReverse runs every node before the nodes it depends on, which is the
+// order needed when deleting resources. A node starts once the nodes depending
+// on it have run and settled, e.g. once the resources they deleted are gone.
+func (g *Graph) ExecuteReverse(concurrency int) error {
+	return g.execute(concurrency, g.dependents())
+}
+
+func (g *Graph) dependencies() map[string][]string {
+	edges := make(map[string][]string)
+	for key, n := range g.nodes {
+		edges[key] = n.deps
+	}
+
+	return edges
+}
+
+func (g *Graph) dependents() map[string][]string {
+	edges := make(map[string][]string)
+	for key, n := range g.nodes {
+		for _, dep := range n.deps {
+			edges[dep] = append(edges[dep], key)
+		}
+	}
+
+	return edges
+}
+
+// execute runs each node once every node in its entry of waitsOn has finished.
+// Nodes which others wait on are settled before they count as finished, the
+// node keeps its place among the running nodes while it settles.
+func (g *Graph) execute(concurrency int, waitsOn map[string][]string) error {
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
+	type outcome struct {
+		key string
+		err error
+	}
+
+	pending := make(map[string]int)
+	unblocks := make(map[string][]string)
+	for _, key := range g.order {
+		pending[key] = len(waitsOn[key])
+		for _, w := range waitsOn[key] {
+			unblocks[w] = append(unblocks[w], key)
+		}
+	}
+
+	ready := make([]string, 0)
+	for _, key := range g.order {
+		if pending[key] == 0 {
+			ready = append(ready, key)
+		}
+	}
+
+	done := make(chan outcome)
+	var wg sync.WaitGroup
+	var firstErr error
+	running := 0
+	finished := 0
+
+	for finished < len(g.order) {
+		for firstErr == nil && running < concurrency && len(ready) > 0 {
+			n := g.nodes[ready[0]]
+			ready = ready[1:]
+			running++
+
+			wg.Add(1)
+			go func(n *Node, settle bool) {
+				defer wg.Done()
+				log.Debugf("running %s", n.key())
+				err := n.Run()
+				if err == nil && settle {
+					log.Debugf("settling %s", n.key())
+					err = n.Settle()
+				}
+				done <- outcome{key: n.key(), err: err}
+			}(n, n.Settle != nil && len(unblocks[n.key()]) > 0)
+		}
+
+		if running == 0 {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("dependency cycle between %d resources", len(g.order)-finished)
+			}
+			break
+		}
+
+		o := <-done
+		running--
+		finished++
+
+		if o.err != nil {
+			log.Warnf("%s failed: %s", o.key, o.err)
+			if firstErr == nil {
+				firstErr = o.err
+			}
+			continue
+		}
+
+		for _, key := range unblocks[o.key] {
+			pending[key]--
+			if pending[key] == 0 {
+				ready = append(ready, key)
+			}
+		}
+	}
+
+	wg.Wait()
+	return firstErr
+}
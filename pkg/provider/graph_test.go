@@ -0,0 +1,270 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// events records what the nodes of a graph did, in order
+type events struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (e *events) add(format string, args ...interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, fmt.Sprintf(format, args...))
+}
+
+func (e *events) index(event string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, ev := range e.events {
+		if ev == event {
+			return i
+		}
+	}
+	return -1
+}
+
+// testGraph builds a graph of nodes named by id, of kind "node", with
+// dependencies given as [node, dependency] pairs. Each node records running
+// and, when settle is set, settling, taking a little time to do both so that
+// ordering mistakes show.
+func testGraph(ids []string, deps [][2]string, failing string, settle bool) (*Graph, *events) {
+	g := NewGraph()
+	ev := &events{}
+	for _, id := range ids {
+		id := id
+		g.AddNode("node", id, func() error {
+			ev.add("start %s", id)
+			time.Sleep(2 * time.Millisecond)
+			if id == failing {
+				return errors.New("failed " + id)
+			}
+			ev.add("run %s", id)
+			return nil
+		})
+		if settle {
+			g.SetSettle("node", id, func() error {
+				time.Sleep(2 * time.Millisecond)
+				ev.add("settle %s", id)
+				return nil
+			})
+		}
+	}
+	for _, d := range deps {
+		g.AddDependency("node", d[0], "node", d[1])
+	}
+
+	return g, ev
+}
+
+// stackIds and stackDeps mirror the resources of a stack and their dependencies
+var (
+	stackIds  = []string{"subnets", "cluster-params", "params", "cluster", "i1", "i2"}
+	stackDeps = [][2]string{
+		{"cluster", "subnets"},
+		{"cluster", "cluster-params"},
+		{"i1", "cluster"},
+		{"i1", "params"},
+		{"i2", "cluster"},
+		{"i2", "params"},
+	}
+)
+
+func TestGraphExecute(t *testing.T) {
+	cases := []struct {
+		name        string
+		ids         []string
+		deps        [][2]string
+		concurrency int
+	}{
+		{name: "stack", ids: stackIds, deps: stackDeps},
+		{name: "stack one at a time", ids: stackIds, deps: stackDeps, concurrency: 1},
+		{name: "chain", ids: []string{"c", "b", "a"}, deps: [][2]string{{"b", "a"}, {"c", "b"}}},
+		{name: "independent", ids: []string{"a", "b", "c"}, concurrency: 2},
+		{
+			name: "dependency outside the graph is ignored",
+			ids:  []string{"a", "b"},
+			deps: [][2]string{{"b", "a"}, {"a", "missing"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g, ev := testGraph(c.ids, c.deps, "", false)
+			err := g.Execute(c.concurrency)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, id := range c.ids {
+				if ev.index("run "+id) < 0 {
+					t.Errorf("%s did not run", id)
+				}
+			}
+			for _, d := range c.deps {
+				if !g.HasNode("node", d[1]) {
+					continue
+				}
+				if ev.index("start "+d[0]) < ev.index("run "+d[1]) {
+					t.Errorf("%s started before %s ran: %q", d[0], d[1], ev.events)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphExecuteReverse(t *testing.T) {
+	cases := []struct {
+		name        string
+		ids         []string
+		deps        [][2]string
+		concurrency int
+	}{
+		{name: "stack", ids: stackIds, deps: stackDeps},
+		{name: "stack one at a time", ids: stackIds, deps: stackDeps, concurrency: 1},
+		{name: "chain", ids: []string{"c", "b", "a"}, deps: [][2]string{{"b", "a"}, {"c", "b"}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g, ev := testGraph(c.ids, c.deps, "", true)
+			err := g.ExecuteReverse(c.concurrency)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// a node starts once the nodes depending on it are settled
+			for _, d := range c.deps {
+				dependent, dependency := d[0], d[1]
+				settled := ev.index("settle " + dependent)
+				if settled < 0 {
+					t.Errorf("%s was not settled before %s: %q", dependent, dependency, ev.events)
+					continue
+				}
+				if ev.index("start "+dependency) < settled {
+					t.Errorf("%s started before %s settled: %q", dependency, dependent, ev.events)
+				}
+			}
+
+			// nodes no other node waits on are not settled
+			for _, id := range c.ids {
+				waitedOn := false
+				for _, d := range c.deps {
+					waitedOn = waitedOn || d[0] == id
+				}
+				if !waitedOn && ev.index("settle "+id) >= 0 {
+					t.Errorf("%s was settled though nothing waits on it", id)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphExecuteFailures(t *testing.T) {
+	cases := []struct {
+		name    string
+		ids     []string
+		deps    [][2]string
+		failing string
+		reverse bool
+		wantErr string
+		// nodes which must not have started
+		notStarted []string
+	}{
+		{
+			name:       "failure stops dependents",
+			ids:        stackIds,
+			deps:       stackDeps,
+			failing:    "cluster",
+			wantErr:    "failed cluster",
+			notStarted: []string{"i1", "i2"},
+		},
+		{
+			name:       "failure stops dependencies in reverse",
+			ids:        stackIds,
+			deps:       stackDeps,
+			failing:    "i1",
+			reverse:    true,
+			wantErr:    "failed i1",
+			notStarted: []string{"cluster", "params", "subnets", "cluster-params"},
+		},
+		{
+			name:       "cycle",
+			ids:        []string{"a", "b", "c", "d"},
+			deps:       [][2]string{{"b", "a"}, {"c", "b"}, {"b", "c"}, {"d", "c"}},
+			wantErr:    "dependency cycle between 3 resources",
+			notStarted: []string{"b", "c", "d"},
+		},
+		{
+			name:    "cycle in reverse",
+			ids:     []string{"a", "b"},
+			deps:    [][2]string{{"a", "b"}, {"b", "a"}},
+			reverse: true,
+			wantErr: "dependency cycle between 2 resources",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g, ev := testGraph(c.ids, c.deps, c.failing, c.reverse)
+
+			var err error
+			if c.reverse {
+				err = g.ExecuteReverse(1)
+			} else {
+				err = g.Execute(1)
+			}
+			if err == nil || err.Error() != c.wantErr {
+				t.Fatalf("got %v, want %s", err, c.wantErr)
+			}
+
+			for _, id := range c.notStarted {
+				if ev.index("start "+id) >= 0 {
+					t.Errorf("%s started: %q", id, ev.events)
+				}
+			}
+		})
+	}
+}
+
+func TestGraphExecuteConcurrency(t *testing.T) {
+	for _, concurrency := range []int{1, 2, 4} {
+		t.Run(fmt.Sprint(concurrency), func(t *testing.T) {
+			g := NewGraph()
+			var mu sync.Mutex
+			running, most := 0, 0
+			for i := 0; i < 8; i++ {
+				g.AddNode("node", fmt.Sprint(i), func() error {
+					mu.Lock()
+					running++
+					if running > most {
+						most = running
+					}
+					mu.Unlock()
+
+					time.Sleep(5 * time.Millisecond)
+
+					mu.Lock()
+					running--
+					mu.Unlock()
+					return nil
+				})
+			}
+
+			err := g.Execute(concurrency)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if most > concurrency {
+				t.Errorf("ran %d nodes at once, want at most %d", most, concurrency)
+			}
+		})
+	}
+}
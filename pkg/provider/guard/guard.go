@@ -0,0 +1,274 @@
+// Package guard checks destructive operations before they are made. A Guard
+// refuses to delete resources whose name or tags match protected patterns,
+// clusters with deletion protection, and clusters which still have instances
+// unless the deletion cascades to them. Deletions which pass every check must
+// then be confirmed.
+package guard
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster_parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/snapshot"
+	"github.com/cvgw/rds_provider/pkg/provider/subnet_group"
+	"github.com/cvgw/rds_provider/pkg/provider/tags"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ProtectedErr    error
+	HasMembersErr   error
+	NotConfirmedErr error
+)
+
+func init() {
+	ProtectedErr = errors.New("resource is protected")
+	HasMembersErr = errors.New("cluster still has instances")
+	NotConfirmedErr = errors.New("deletion was not confirmed")
+}
+
+// Guard holds the rules checked before deleting resources. The zero value
+// only refuses clusters with deletion protection or instances, and confirms
+// every deletion.
+type Guard struct {
+	// Patterns matched against resource identifiers, in path.Match syntax,
+	// e.g. "prod-*"
+	ProtectedNames []string
+	// Patterns matched against resource tags, either key=value or key. Both
+	// key and value may use path.Match syntax, e.g. "env=prod*".
+	ProtectedTags []string
+	// Whether deleting a cluster also deletes its instances. Clusters with
+	// instances are refused otherwise.
+	Cascade bool
+	// Confirm asks whether to go ahead with the described deletions.
+	// Deletions are confirmed when nil.
+	Confirm func(resources []string) (bool, error)
+}
+
+// Validate checks that the protected patterns are well formed
+func (g Guard) Validate() error {
+	for _, pattern := range g.ProtectedNames {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid protected name pattern %q: %v", pattern, err)
+		}
+	}
+
+	for _, pattern := range g.ProtectedTags {
+		key, value, _ := splitTagPattern(pattern)
+		if key == "" {
+			return fmt.Errorf("invalid protected tag pattern %q, expected key=value or key", pattern)
+		}
+		if _, err := path.Match(key, ""); err != nil {
+			return fmt.Errorf("invalid protected tag pattern %q: %v", pattern, err)
+		}
+		if _, err := path.Match(value, ""); err != nil {
+			return fmt.Errorf("invalid protected tag pattern %q: %v", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// CheckResource refuses to delete the resource of the given kind when its
+// identifier or tags are protected. Tags are only read when tag patterns are
+// set.
+func (g Guard) CheckResource(svc rds_api.RDSAPI, kind, id, arn string) error {
+	for _, pattern := range g.ProtectedNames {
+		if ok, _ := path.Match(pattern, id); ok {
+			return fmt.Errorf("%s %s: %w, name matches %q", kind, id, ProtectedErr, pattern)
+		}
+	}
+
+	if len(g.ProtectedTags) == 0 {
+		return nil
+	}
+
+	resourceTags, err := tags.ListTags(svc, kind, id, arn)
+	if err != nil {
+		return err
+	}
+
+	for _, pattern := range g.ProtectedTags {
+		if tag := matchTag(pattern, resourceTags); tag != nil {
+			return fmt.Errorf(
+				"%s %s: %w, tag %s=%s matches %q",
+				kind, id, ProtectedErr, aws.StringValue(tag.Key), aws.StringValue(tag.Value), pattern,
+			)
+		}
+	}
+
+	return nil
+}
+
+// CheckCluster refuses to delete a protected cluster, a cluster with deletion
+// protection enabled, or a cluster which has instances other than the ones
+// being deleted along with it, unless the guard cascades
+func (g Guard) CheckCluster(svc rds_api.RDSAPI, clusterId string, deleting []string) (*rds.DBCluster, error) {
+	c, err := cluster.FindDBCluster(svc, clusterId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.CheckResource(svc, rds_errors.KindCluster, clusterId, aws.StringValue(c.DBClusterArn))
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.BoolValue(c.DeletionProtection) {
+		return nil, fmt.Errorf(
+			"cluster %s: %w, deletion protection is enabled and must be turned off first",
+			clusterId, ProtectedErr,
+		)
+	}
+
+	if g.Cascade {
+		return c, nil
+	}
+
+	deleted := make(map[string]bool)
+	for _, id := range deleting {
+		deleted[id] = true
+	}
+
+	remaining := make([]string, 0)
+	for _, m := range c.DBClusterMembers {
+		if id := aws.StringValue(m.DBInstanceIdentifier); !deleted[id] {
+			remaining = append(remaining, id)
+		}
+	}
+
+	if len(remaining) > 0 {
+		return nil, fmt.Errorf(
+			"cluster %s: %w (%s), delete them first or cascade",
+			clusterId, HasMembersErr, strings.Join(remaining, ", "),
+		)
+	}
+
+	return c, nil
+}
+
+// CheckInstance refuses to delete a protected instance or one with deletion
+// protection enabled
+func (g Guard) CheckInstance(svc rds_api.RDSAPI, instanceId string) (*rds.DBInstance, error) {
+	i, err := instance.FindDBClusterInstance(svc, instanceId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.CheckResource(svc, rds_errors.KindInstance, instanceId, aws.StringValue(i.DBInstanceArn))
+	if err != nil {
+		return nil, err
+	}
+
+	if aws.BoolValue(i.DeletionProtection) {
+		return nil, fmt.Errorf(
+			"instance %s: %w, deletion protection is enabled and must be turned off first",
+			instanceId, ProtectedErr,
+		)
+	}
+
+	return i, nil
+}
+
+// CheckSubnetGroup refuses to delete a protected subnet group
+func (g Guard) CheckSubnetGroup(svc rds_api.RDSAPI, groupName string) error {
+	group, err := subnet_group.FindDBSubnetGroup(svc, groupName)
+	if err != nil {
+		return err
+	}
+
+	return g.CheckResource(svc, rds_errors.KindSubnetGroup, groupName, aws.StringValue(group.DBSubnetGroupArn))
+}
+
+// CheckParameterGroup refuses to delete a protected parameter group
+func (g Guard) CheckParameterGroup(svc rds_api.RDSAPI, groupName string) error {
+	group, err := parameter_group.FindDBParameterGroup(svc, groupName)
+	if err != nil {
+		return err
+	}
+
+	return g.CheckResource(
+		svc, rds_errors.KindParameterGroup, groupName, aws.StringValue(group.DBParameterGroupArn),
+	)
+}
+
+// CheckClusterParameterGroup refuses to delete a protected cluster parameter
+// group
+func (g Guard) CheckClusterParameterGroup(svc rds_api.RDSAPI, groupName string) error {
+	group, err := cluster_parameter_group.FindDBClusterParameterGroup(svc, groupName)
+	if err != nil {
+		return err
+	}
+
+	return g.CheckResource(
+		svc, rds_errors.KindClusterParameterGroup, groupName, aws.StringValue(group.DBClusterParameterGroupArn),
+	)
+}
+
+// CheckClusterSnapshot refuses to delete a protected cluster snapshot
+func (g Guard) CheckClusterSnapshot(svc rds_api.RDSAPI, snapshotId string) error {
+	s, err := snapshot.FindDBClusterSnapshot(svc, snapshotId)
+	if err != nil {
+		return err
+	}
+
+	return g.CheckResource(
+		svc, rds_errors.KindClusterSnapshot, snapshotId, aws.StringValue(s.DBClusterSnapshotArn),
+	)
+}
+
+// ConfirmDelete asks Confirm whether to delete the described resources
+func (g Guard) ConfirmDelete(resources []string) error {
+	if g.Confirm == nil || len(resources) == 0 {
+		return nil
+	}
+
+	ok, err := g.Confirm(resources)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NotConfirmedErr
+	}
+
+	log.Debugf("confirmed deleting %s", strings.Join(resources, ", "))
+	return nil
+}
+
+// matchTag returns the first of tags matching the pattern
+func matchTag(pattern string, resourceTags []*rds.Tag) *rds.Tag {
+	key, value, hasValue := splitTagPattern(pattern)
+
+	for _, tag := range resourceTags {
+		if ok, _ := path.Match(key, aws.StringValue(tag.Key)); !ok {
+			continue
+		}
+		if !hasValue {
+			return tag
+		}
+		if ok, _ := path.Match(value, aws.StringValue(tag.Value)); ok {
+			return tag
+		}
+	}
+
+	return nil
+}
+
+func splitTagPattern(pattern string) (string, string, bool) {
+	parts := strings.SplitN(pattern, "=", 2)
+	if len(parts) == 1 {
+		return parts[0], "", false
+	}
+
+	return parts[0], parts[1], true
+}
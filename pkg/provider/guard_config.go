@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+)
+
+// GuardConfig holds the patterns of resources which may not be deleted. It is
+// read from the same config file as the session settings.
+type GuardConfig struct {
+	// Patterns matched against resource identifiers, e.g. "prod-*"
+	ProtectedNames []string `json:"protected_names,omitempty"`
+	// Patterns matched against resource tags, e.g. "env=prod*" or "protected"
+	ProtectedTags []string `json:"protected_tags,omitempty"`
+}
+
+// LoadGuardConfig reads the protected patterns from the config file found by
+// ConfigFilePath. There are no patterns when there is no config file.
+func LoadGuardConfig(configPath string) (GuardConfig, error) {
+	cfg := GuardConfig{}
+
+	configPath = ConfigFilePath(configPath)
+	if configPath == "" {
+		return cfg, nil
+	}
+
+	fileData, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return cfg, err
+	}
+
+	err = yaml.Unmarshal(fileData, &cfg)
+	if err != nil {
+		return cfg, fmt.Errorf("%s: %v", configPath, err)
+	}
+
+	return cfg, nil
+}
@@ -3,42 +3,45 @@ package instance
 import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
-//NewDBInstanceInput contains the data to be used when creating the RDS instance
+// NewDBInstanceInput contains the data to be used when creating the RDS instance
 type NewDBInstanceInput struct {
 	// Whether minor version upgrades should be automatically applied to the instance (optional)
-	AutoMinorVersionUpgrade bool
+	AutoMinorVersionUpgrade bool `json:"auto_minor_version_upgrade,omitempty"`
 	// Identifier of the RDS cluster to add the instance to
-	ClusterIdentifier string
+	ClusterIdentifier string `json:"cluster_identifier,omitempty"`
 	// Whether instance tags should be copied to DB snapshots (optional)
-	CopyTagsToSnapshot bool
+	CopyTagsToSnapshot bool `json:"copy_tags_to_snapshot,omitempty"`
 	// Engine type
-	Engine string
+	Engine string `json:"engine,omitempty"`
 	// Engine version (optional)
-	EngineVersion string
+	EngineVersion string `json:"engine_version,omitempty"`
 	// Instance class (optional)
-	InstanceClass string
+	InstanceClass string `json:"instance_class,omitempty"`
 	// Identifier for the RDS instance
-	InstanceIdentifier string
+	InstanceIdentifier string `json:"instance_identifier,omitempty"`
 	// Whether enhanced monitoring should be enabled on this instance (optional)
-	EnhancedMonitoring bool
+	EnhancedMonitoring bool `json:"enhanced_monitoring,omitempty"`
 	// Interval at which to collecting monitoring data from the instance (optional)
-	MonitoringInterval int64
+	MonitoringInterval int64 `json:"monitoring_interval,omitempty"`
 	// The IAM Role ARN to use for collecting monitoring (optional)
-	MonitoringRoleArn string
+	MonitoringRoleArn string `json:"monitoring_role_arn,omitempty"`
 	// Name of RDS Instance ParameterGroup to use (optional)
-	ParameterGroupName string
+	ParameterGroupName string `json:"parameter_group_name,omitempty"`
 	// Whethere the instance should be assigned a public IP
-	PubliclyAccessible bool
+	PubliclyAccessible bool `json:"publicly_accessible,omitempty"`
 }
 
 // CreateDBClusterInstance create a new RDS instance from the supplied NewDBInstanceInput
-func CreateDBClusterInstance(svc *rds.RDS, input NewDBInstanceInput) (*rds.DBInstance, error) {
+func CreateDBClusterInstance(svc rds_api.RDSAPI, input NewDBInstanceInput) (*rds.DBInstance, error) {
 	instanceInput := NewCreateDBInstanceInput(input)
 	instanceOutput, err := svc.CreateDBInstance(instanceInput)
 	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindInstance, input.InstanceIdentifier)
 		log.Warn(err)
 		return nil, err
 	}
@@ -1,48 +1,113 @@
 package instance
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
-func DeleteDBClusterInstance(svc *rds.RDS, instanceId string) error {
+var (
+	FinalSnapshotRequiredErr error
+)
+
+func init() {
+	FinalSnapshotRequiredErr = errors.New("refusing to delete without a final snapshot unless forced")
+}
+
+// DeleteOptions controls the final snapshot taken when an instance is deleted.
+// The zero value takes a final snapshot with a generated identifier.
+//
+// The data of an instance which belongs to a cluster is kept by the cluster,
+// so RDS takes no final snapshot of it. The snapshot options are ignored for
+// such instances and giving FinalSnapshotId is an error.
+type DeleteOptions struct {
+	// Identifier of the final snapshot, generated from the instance id when
+	// empty (optional)
+	FinalSnapshotId string
+	// Whether to delete the instance without a final snapshot. This is
+	// refused unless Force is also set.
+	SkipFinalSnapshot bool
+	// Whether to allow deleting without a final snapshot
+	Force bool
+	// Whether to keep the automated backups of the instance after it is
+	// deleted
+	RetainAutomatedBackups bool
+}
+
+// FinalSnapshotId returns a final snapshot identifier for the instance unique
+// to the second
+func FinalSnapshotId(instanceId string, now time.Time) string {
+	return fmt.Sprintf("%s-final-%s", instanceId, now.UTC().Format("20060102-150405"))
+}
+
+// NewDeleteDBInstanceInput builds the delete call for the instance. clusterId
+// is the cluster the instance belongs to, if any.
+func NewDeleteDBInstanceInput(instanceId, clusterId string, opts DeleteOptions) (*rds.DeleteDBInstanceInput, error) {
 	input := &rds.DeleteDBInstanceInput{
 		DBInstanceIdentifier: aws.String(instanceId),
-		SkipFinalSnapshot:    aws.Bool(true),
 	}
 
-	_, err := svc.DeleteDBInstance(input)
-	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBInstanceNotFoundFault:
-				log.Warn(rds.ErrCodeDBInstanceNotFoundFault, aerr.Error())
-				return NotFoundErr
-			case rds.ErrCodeInvalidDBInstanceStateFault:
-				log.Warn(rds.ErrCodeInvalidDBInstanceStateFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDBSnapshotAlreadyExistsFault:
-				log.Warn(rds.ErrCodeDBSnapshotAlreadyExistsFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeSnapshotQuotaExceededFault:
-				log.Warn(rds.ErrCodeSnapshotQuotaExceededFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeInvalidDBClusterStateFault:
-				log.Warn(rds.ErrCodeInvalidDBClusterStateFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDBInstanceAutomatedBackupQuotaExceededFault:
-				log.Warn(rds.ErrCodeDBInstanceAutomatedBackupQuotaExceededFault, aerr.Error())
-				return aerr
-			default:
-				log.Warn(aerr.Error())
-				return aerr
-			}
-		} else {
-			log.Warn(err.Error())
-			return err
+	if opts.RetainAutomatedBackups {
+		input.DeleteAutomatedBackups = aws.Bool(false)
+	}
+
+	if clusterId != "" {
+		if opts.FinalSnapshotId != "" {
+			return nil, fmt.Errorf(
+				"instance %s: belongs to cluster %s and can not have a final snapshot, snapshot the cluster instead",
+				instanceId, clusterId,
+			)
+		}
+		return input, nil
+	}
+
+	if opts.SkipFinalSnapshot {
+		if !opts.Force {
+			return nil, fmt.Errorf("instance %s: %w", instanceId, FinalSnapshotRequiredErr)
 		}
+		input.SkipFinalSnapshot = aws.Bool(true)
+		return input, nil
+	}
+
+	snapshotId := opts.FinalSnapshotId
+	if snapshotId == "" {
+		snapshotId = FinalSnapshotId(instanceId, time.Now())
+	}
+	input.SkipFinalSnapshot = aws.Bool(false)
+	input.FinalDBSnapshotIdentifier = aws.String(snapshotId)
+
+	return input, nil
+}
+
+func DeleteDBClusterInstance(svc rds_api.RDSAPI, instanceId string, opts DeleteOptions) error {
+	i, err := FindDBClusterInstance(svc, instanceId)
+	if err != nil {
+		return err
+	}
+
+	input, err := NewDeleteDBInstanceInput(instanceId, aws.StringValue(i.DBClusterIdentifier), opts)
+	if err != nil {
+		log.Warn(err)
+		return err
+	}
+
+	if input.FinalDBSnapshotIdentifier != nil {
+		log.Infof("instance %s: taking final snapshot %s", instanceId, *input.FinalDBSnapshotIdentifier)
+	} else if aws.BoolValue(input.SkipFinalSnapshot) {
+		log.Warnf("instance %s: deleting without a final snapshot", instanceId)
+	}
+
+	_, err = svc.DeleteDBInstance(input)
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindInstance, instanceId)
+		log.Warn(err)
+		return err
 	}
 
 	return nil
@@ -0,0 +1,78 @@
+package instance
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/tags"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListRequest narrows the instances returned by ListDBClusterInstances. Empty
+// fields match every instance.
+type ListRequest struct {
+	// Engine of the instances, e.g. aurora-mysql (optional)
+	Engine string
+	// Identifiers or ARNs of the clusters the instances belong to (optional)
+	ClusterIds []string
+	// Tags the instances must carry (optional)
+	Tags tags.Selector
+}
+
+// Filters returns the RDS filters matching the request
+func (r ListRequest) Filters() []*rds.Filter {
+	filters := make([]*rds.Filter, 0)
+	if len(r.ClusterIds) > 0 {
+		filters = append(filters, &rds.Filter{
+			Name:   aws.String("db-cluster-id"),
+			Values: aws.StringSlice(r.ClusterIds),
+		})
+	}
+	if r.Engine != "" {
+		filters = append(filters, &rds.Filter{
+			Name:   aws.String("engine"),
+			Values: aws.StringSlice([]string{r.Engine}),
+		})
+	}
+
+	return filters
+}
+
+// ListDBClusterInstances returns the instances in the region matching req,
+// following every page of results
+func ListDBClusterInstances(svc rds_api.RDSAPI, req ListRequest) ([]*rds.DBInstance, error) {
+	input := &rds.DescribeDBInstancesInput{}
+	if filters := req.Filters(); len(filters) > 0 {
+		input.Filters = filters
+	}
+
+	instances := make([]*rds.DBInstance, 0)
+	for {
+		result, err := svc.DescribeDBInstances(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindInstance, "")
+			log.Warn(err)
+			return nil, err
+		}
+
+		for _, i := range result.DBInstances {
+			ok, err := req.Tags.Select(
+				svc, rds_errors.KindInstance, aws.StringValue(i.DBInstanceIdentifier), aws.StringValue(i.DBInstanceArn),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				instances = append(instances, i)
+			}
+		}
+
+		if aws.StringValue(result.Marker) == "" {
+			break
+		}
+		input.Marker = result.Marker
+	}
+
+	return instances, nil
+}
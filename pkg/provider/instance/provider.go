@@ -4,8 +4,9 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -14,29 +15,23 @@ var (
 )
 
 func init() {
-	NotFoundErr = errors.New("db instance not found")
+	NotFoundErr = rds_errors.New(rds_errors.NotFound, rds_errors.KindInstance, "")
 }
 
-func FindDBClusterInstance(svc *rds.RDS, instanceId string) (*rds.DBInstance, error) {
+func FindDBClusterInstance(svc rds_api.RDSAPI, instanceId string) (*rds.DBInstance, error) {
 	descInstancesInput := &rds.DescribeDBInstancesInput{
 		DBInstanceIdentifier: aws.String(instanceId),
 	}
 
 	descInstancesOuput, err := svc.DescribeDBInstances(descInstancesInput)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBInstanceNotFoundFault:
-				log.Info(rds.ErrCodeDBInstanceNotFoundFault, aerr.Error())
-				return nil, NotFoundErr
-			default:
-				log.Warn(aerr.Error())
-				return nil, aerr
-			}
+		err = rds_errors.Wrap(err, rds_errors.KindInstance, instanceId)
+		if errors.Is(err, NotFoundErr) {
+			log.Info(err)
 		} else {
-			log.Warn(err.Error())
-			return nil, err
+			log.Warn(err)
 		}
+		return nil, err
 	}
 
 	return descInstancesOuput.DBInstances[0], nil
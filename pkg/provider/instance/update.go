@@ -2,8 +2,9 @@ package instance
 
 import (
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -14,7 +15,45 @@ type UpdateDBInstanceRequest struct {
 	engine             string
 	class              string
 	parameterGroupName string
-	publiclyAccessible bool
+	publiclyAccessible *bool
+}
+
+// NewUpdateDBInstanceRequest builds an UpdateDBInstanceRequest which moves the
+// instance to the desired state. The returned bool is false when the instance
+// already matches the desired state.
+func NewUpdateDBInstanceRequest(i *rds.DBInstance, desired NewDBInstanceInput) (UpdateDBInstanceRequest, bool) {
+	req := UpdateDBInstanceRequest{}
+	req.SetId(aws.StringValue(i.DBInstanceIdentifier)).
+		SetClusterId(aws.StringValue(i.DBClusterIdentifier))
+	changed := false
+
+	if desired.InstanceClass != "" && desired.InstanceClass != aws.StringValue(i.DBInstanceClass) {
+		req.SetClass(desired.InstanceClass)
+		changed = true
+	}
+
+	if desired.ParameterGroupName != "" && !HasParameterGroup(i, desired.ParameterGroupName) {
+		req.SetParameterGroupName(desired.ParameterGroupName)
+		changed = true
+	}
+
+	if desired.PubliclyAccessible != aws.BoolValue(i.PubliclyAccessible) {
+		req.SetPubliclyAccessible(desired.PubliclyAccessible)
+		changed = true
+	}
+
+	return req, changed
+}
+
+// HasParameterGroup reports whether the instance uses the named parameter group
+func HasParameterGroup(i *rds.DBInstance, groupName string) bool {
+	for _, g := range i.DBParameterGroups {
+		if aws.StringValue(g.DBParameterGroupName) == groupName {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (req *UpdateDBInstanceRequest) SetId(v string) *UpdateDBInstanceRequest {
@@ -48,89 +87,38 @@ func (req *UpdateDBInstanceRequest) SetParameterGroupName(v string) *UpdateDBIns
 }
 
 func (req *UpdateDBInstanceRequest) SetPubliclyAccessible(v bool) *UpdateDBInstanceRequest {
-	req.publiclyAccessible = v
+	req.publiclyAccessible = aws.Bool(v)
 	return req
 }
 
-func UpdateDBClusterInstance(svc *rds.RDS, req UpdateDBInstanceRequest) error {
+func UpdateDBClusterInstance(svc rds_api.RDSAPI, req UpdateDBInstanceRequest) error {
 	input := &rds.ModifyDBInstanceInput{
 		ApplyImmediately: aws.Bool(true),
 		//BackupRetentionPeriod:      aws.Int64(1),
-		DBInstanceClass:      aws.String(req.class),
 		DBInstanceIdentifier: aws.String(req.id),
-		DBParameterGroupName: aws.String(req.parameterGroupName),
+		PubliclyAccessible:   req.publiclyAccessible,
 		//MasterUserPassword:         aws.String("mynewpassword"),
 		//PreferredBackupWindow:      aws.String("04:00-04:30"),
 		//PreferredMaintenanceWindow: aws.String("Tue:05:00-Tue:05:30"),
 	}
 
+	if req.class != "" {
+		input.DBInstanceClass = aws.String(req.class)
+	}
+
+	if req.parameterGroupName != "" {
+		input.DBParameterGroupName = aws.String(req.parameterGroupName)
+	}
+
 	if req.clusterId == "" {
 		input.AllocatedStorage = aws.Int64(int64(req.allocatedStorage))
 	}
 
 	result, err := svc.ModifyDBInstance(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeInvalidDBInstanceStateFault:
-				log.Warn(rds.ErrCodeInvalidDBInstanceStateFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeInvalidDBSecurityGroupStateFault:
-				log.Warn(rds.ErrCodeInvalidDBSecurityGroupStateFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDBInstanceAlreadyExistsFault:
-				log.Warn(rds.ErrCodeDBInstanceAlreadyExistsFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDBInstanceNotFoundFault:
-				log.Warn(rds.ErrCodeDBInstanceNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDBSecurityGroupNotFoundFault:
-				log.Warn(rds.ErrCodeDBSecurityGroupNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDBParameterGroupNotFoundFault:
-				log.Warn(rds.ErrCodeDBParameterGroupNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeInsufficientDBInstanceCapacityFault:
-				log.Warn(rds.ErrCodeInsufficientDBInstanceCapacityFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeStorageQuotaExceededFault:
-				log.Warn(rds.ErrCodeStorageQuotaExceededFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeInvalidVPCNetworkStateFault:
-				log.Warn(rds.ErrCodeInvalidVPCNetworkStateFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeProvisionedIopsNotAvailableInAZFault:
-				log.Warn(rds.ErrCodeProvisionedIopsNotAvailableInAZFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeOptionGroupNotFoundFault:
-				log.Warn(rds.ErrCodeOptionGroupNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDBUpgradeDependencyFailureFault:
-				log.Warn(rds.ErrCodeDBUpgradeDependencyFailureFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeStorageTypeNotSupportedFault:
-				log.Warn(rds.ErrCodeStorageTypeNotSupportedFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeAuthorizationNotFoundFault:
-				log.Warn(rds.ErrCodeAuthorizationNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeCertificateNotFoundFault:
-				log.Warn(rds.ErrCodeCertificateNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDomainNotFoundFault:
-				log.Warn(rds.ErrCodeDomainNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeBackupPolicyNotFoundFault:
-				log.Warn(rds.ErrCodeBackupPolicyNotFoundFault, aerr.Error())
-				return aerr
-			default:
-				log.Warn(aerr.Error())
-				return aerr
-			}
-		} else {
-			log.Warn(err.Error())
-			return err
-		}
+		err = rds_errors.Wrap(err, rds_errors.KindInstance, req.id)
+		log.Warn(err)
+		return err
 	}
 
 	log.Debug(result)
@@ -0,0 +1,146 @@
+package instance
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/wait"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	instanceStatusPath = "DBInstances[].DBInstanceStatus"
+
+	statusAvailable = "available"
+)
+
+// failedStatuses are the statuses an instance can not become available from
+var failedStatuses = map[string]bool{
+	"deleted":                 true,
+	"deleting":                true,
+	"failed":                  true,
+	"incompatible-restore":    true,
+	"incompatible-parameters": true,
+}
+
+// WaitUntilDBInstanceAvailable blocks until the instance reports the available
+// status
+func WaitUntilDBInstanceAvailable(svc rds_api.RDSAPI, instanceId string, opts wait.Options) error {
+	ctx, cancel := opts.Context()
+	defer cancel()
+
+	input := &rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(instanceId),
+	}
+
+	return svc.WaitUntilDBInstanceAvailableWithContext(
+		ctx, input, opts.WaiterOptions(instanceId, instanceStatusPath)...,
+	)
+}
+
+// WaitUntilDBInstanceModified blocks until a modification of the instance has
+// been applied. The instance stays available until RDS starts applying the
+// modification, so it is given wait.DefaultStartChecks checks to leave the
+// available status or report pending modifications before the modification
+// is taken to have been applied without either. Otherwise the wait lasts
+// until the instance is available with no pending modifications.
+func WaitUntilDBInstanceModified(svc rds_api.RDSAPI, instanceId string, opts wait.Options) error {
+	started, err := opts.Poll(instanceId, wait.DefaultStartChecks, func() (string, bool, error) {
+		i, err := FindDBClusterInstance(svc, instanceId)
+		if err != nil {
+			return "", false, err
+		}
+
+		status := aws.StringValue(i.DBInstanceStatus)
+		return status, status != statusAvailable || HasPendingModifications(i), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !started {
+		log.Infof("instance %s: modification applied without a status change", instanceId)
+		return nil
+	}
+
+	_, err = opts.Poll(instanceId, 0, func() (string, bool, error) {
+		i, err := FindDBClusterInstance(svc, instanceId)
+		if err != nil {
+			return "", false, err
+		}
+
+		status := aws.StringValue(i.DBInstanceStatus)
+		if failedStatuses[status] {
+			return status, false, fmt.Errorf("instance %s: modification failed, status %s", instanceId, status)
+		}
+		return status, status == statusAvailable && !HasPendingModifications(i), nil
+	})
+
+	return err
+}
+
+// HasPendingModifications reports whether the instance has modifications
+// which are yet to be applied
+func HasPendingModifications(i *rds.DBInstance) bool {
+	return i.PendingModifiedValues != nil &&
+		!reflect.DeepEqual(i.PendingModifiedValues, &rds.PendingModifiedValues{})
+}
+
+// WaitUntilDBInstanceDeleted blocks until the instance no longer exists
+func WaitUntilDBInstanceDeleted(svc rds_api.RDSAPI, instanceId string, opts wait.Options) error {
+	ctx, cancel := opts.Context()
+	defer cancel()
+
+	input := &rds.DescribeDBInstancesInput{
+		DBInstanceIdentifier: aws.String(instanceId),
+	}
+
+	return svc.WaitUntilDBInstanceDeletedWithContext(
+		ctx, input, opts.WaiterOptions(instanceId, instanceStatusPath)...,
+	)
+}
+
+// CreateDBClusterInstanceAndWait creates the instance and blocks until it is
+// available
+func CreateDBClusterInstanceAndWait(svc rds_api.RDSAPI, input NewDBInstanceInput, opts wait.Options) (
+	*rds.DBInstance, error,
+) {
+	_, err := CreateDBClusterInstance(svc, input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = WaitUntilDBInstanceAvailable(svc, input.InstanceIdentifier, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return FindDBClusterInstance(svc, input.InstanceIdentifier)
+}
+
+// UpdateDBClusterInstanceAndWait modifies the instance and blocks until the
+// modification has finished and the instance is available again
+func UpdateDBClusterInstanceAndWait(svc rds_api.RDSAPI, req UpdateDBInstanceRequest, opts wait.Options) error {
+	err := UpdateDBClusterInstance(svc, req)
+	if err != nil {
+		return err
+	}
+
+	return WaitUntilDBInstanceModified(svc, req.id, opts)
+}
+
+// DeleteDBClusterInstanceAndWait deletes the instance and blocks until it no
+// longer exists
+func DeleteDBClusterInstanceAndWait(
+	svc rds_api.RDSAPI, instanceId string, deleteOpts DeleteOptions, opts wait.Options,
+) error {
+	err := DeleteDBClusterInstance(svc, instanceId, deleteOpts)
+	if err != nil {
+		return err
+	}
+
+	return WaitUntilDBInstanceDeleted(svc, instanceId, opts)
+}
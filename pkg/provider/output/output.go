@@ -0,0 +1,164 @@
+// Package output renders command results as tables, JSON or YAML. Results are
+// first converted to their JSON form, so every format shows the same fields
+// and a JMESPath query can select from any of them.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/jmespath/go-jmespath"
+)
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+// Formats lists every supported format
+var Formats = []Format{FormatTable, FormatJSON, FormatYAML}
+
+// Format names an output format
+type Format string
+
+// ParseFormat returns the format named by s
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(s) {
+			return f, nil
+		}
+	}
+
+	names := make([]string, 0, len(Formats))
+	for _, f := range Formats {
+		names = append(names, string(f))
+	}
+	return "", fmt.Errorf("unknown output format %q, expected one of %s", s, strings.Join(names, ", "))
+}
+
+// Printer writes results in a single format
+type Printer struct {
+	Format Format
+	// JMESPath expression selecting the part of the result to print (optional)
+	Query string
+}
+
+// NewPrinter returns a printer for the named format, checking that the query
+// compiles
+func NewPrinter(format, query string) (Printer, error) {
+	f, err := ParseFormat(format)
+	if err != nil {
+		return Printer{}, err
+	}
+
+	if query != "" {
+		_, err = jmespath.Compile(query)
+		if err != nil {
+			return Printer{}, fmt.Errorf("invalid query %q: %v", query, err)
+		}
+	}
+
+	return Printer{Format: f, Query: query}, nil
+}
+
+// Print writes v to w. Without a query, clusters, instances, subnet groups,
+// parameter groups, cluster snapshots and slices of them are shown as tables
+// with a fixed set of columns. Any other value is shown as a generic table.
+func (p Printer) Print(w io.Writer, v interface{}) error {
+	data, err := normalize(v)
+	if err != nil {
+		return err
+	}
+
+	if p.Query != "" {
+		data, err = jmespath.Search(p.Query, data)
+		if err != nil {
+			return fmt.Errorf("query %q: %v", p.Query, err)
+		}
+	}
+
+	switch p.Format {
+	case FormatJSON:
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
+	case FormatYAML:
+		out, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	case FormatTable:
+		if p.Query == "" {
+			if columns, ok := columnsFor(v); ok {
+				return writeTable(w, resourceTable(columns, data))
+			}
+		}
+		return writeTable(w, genericTable(data))
+	default:
+		return fmt.Errorf("unknown output format %q", p.Format)
+	}
+}
+
+// normalize converts v to the maps, slices and scalars of its JSON form,
+// dropping unset fields
+func normalize(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	err = json.Unmarshal(raw, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	data = prune(data)
+	if data == nil {
+		// a nil slice is an empty result
+		data = []interface{}{}
+	}
+
+	return data, nil
+}
+
+// prune removes null values from maps
+func prune(data interface{}) interface{} {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		for k, v := range d {
+			if v == nil {
+				delete(d, k)
+				continue
+			}
+			d[k] = prune(v)
+		}
+	case []interface{}:
+		for i, v := range d {
+			d[i] = prune(v)
+		}
+	}
+
+	return data
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,233 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/snapshot"
+	"github.com/jmespath/go-jmespath"
+)
+
+// missing is shown in place of an unset value
+const missing = "-"
+
+// column is a table column whose value is a JMESPath expression evaluated on
+// each resource
+type column struct {
+	header string
+	expr   *jmespath.JMESPath
+}
+
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+var (
+	clusterColumns = []column{
+		{"CLUSTER", jmespath.MustCompile("DBClusterIdentifier")},
+		{"STATUS", jmespath.MustCompile("Status")},
+		{"ENGINE", jmespath.MustCompile("Engine")},
+		{"VERSION", jmespath.MustCompile("EngineVersion")},
+		{"WRITER", jmespath.MustCompile("DBClusterMembers[?IsClusterWriter].DBInstanceIdentifier | [0]")},
+		{"MEMBERS", jmespath.MustCompile("length(DBClusterMembers || `[]`)")},
+		{"ENDPOINT", jmespath.MustCompile("Endpoint")},
+	}
+
+	instanceColumns = []column{
+		{"INSTANCE", jmespath.MustCompile("DBInstanceIdentifier")},
+		{"CLUSTER", jmespath.MustCompile("DBClusterIdentifier")},
+		{"STATUS", jmespath.MustCompile("DBInstanceStatus")},
+		{"CLASS", jmespath.MustCompile("DBInstanceClass")},
+		{"ENGINE", jmespath.MustCompile("Engine")},
+		{"PARAMETER GROUP", jmespath.MustCompile("DBParameterGroups[0].DBParameterGroupName")},
+		{"ENDPOINT", jmespath.MustCompile("Endpoint.Address")},
+	}
+
+	subnetGroupColumns = []column{
+		{"SUBNET GROUP", jmespath.MustCompile("DBSubnetGroupName")},
+		{"STATUS", jmespath.MustCompile("SubnetGroupStatus")},
+		{"VPC", jmespath.MustCompile("VpcId")},
+		{"SUBNETS", jmespath.MustCompile("join(',', Subnets[].SubnetIdentifier || `[]`)")},
+		{"DESCRIPTION", jmespath.MustCompile("DBSubnetGroupDescription")},
+	}
+
+	parameterGroupColumns = []column{
+		{"PARAMETER GROUP", jmespath.MustCompile("DBParameterGroupName")},
+		{"FAMILY", jmespath.MustCompile("DBParameterGroupFamily")},
+		{"DESCRIPTION", jmespath.MustCompile("Description")},
+	}
+
+	clusterParameterGroupColumns = []column{
+		{"CLUSTER PARAMETER GROUP", jmespath.MustCompile("DBClusterParameterGroupName")},
+		{"FAMILY", jmespath.MustCompile("DBParameterGroupFamily")},
+		{"DESCRIPTION", jmespath.MustCompile("Description")},
+	}
+
+	clusterSnapshotColumns = []column{
+		{"SNAPSHOT", jmespath.MustCompile("DBClusterSnapshotIdentifier")},
+		{"CLUSTER", jmespath.MustCompile("DBClusterIdentifier")},
+		{"STATUS", jmespath.MustCompile("Status")},
+		{"TYPE", jmespath.MustCompile("SnapshotType")},
+		{"ENGINE", jmespath.MustCompile("Engine")},
+		{"CREATED", jmespath.MustCompile("SnapshotCreateTime")},
+		{"ENCRYPTED", jmespath.MustCompile("StorageEncrypted")},
+	}
+
+	// decisions are marshalled to JSON before the expressions are evaluated, so
+	// their fields are named by their JSON tags
+	snapshotDecisionColumns = []column{
+		{"SNAPSHOT", jmespath.MustCompile("snapshot_id")},
+		{"CLUSTER", jmespath.MustCompile("cluster_id")},
+		{"CREATED", jmespath.MustCompile("created")},
+		{"ACTION", jmespath.MustCompile("action")},
+		{"REASONS", jmespath.MustCompile("join(', ', reasons || `[]`)")},
+	}
+
+	resourceColumns = map[reflect.Type][]column{
+		reflect.TypeOf(rds.DBCluster{}):               clusterColumns,
+		reflect.TypeOf(rds.DBInstance{}):              instanceColumns,
+		reflect.TypeOf(rds.DBSubnetGroup{}):           subnetGroupColumns,
+		reflect.TypeOf(rds.DBParameterGroup{}):        parameterGroupColumns,
+		reflect.TypeOf(rds.DBClusterParameterGroup{}): clusterParameterGroupColumns,
+		reflect.TypeOf(rds.DBClusterSnapshot{}):       clusterSnapshotColumns,
+		reflect.TypeOf(snapshot.Decision{}):           snapshotDecisionColumns,
+	}
+)
+
+// columnsFor returns the columns of a known resource type, or of the element
+// type of a slice of them
+func columnsFor(v interface{}) ([]column, bool) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, false
+	}
+
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	columns, ok := resourceColumns[t]
+	return columns, ok
+}
+
+// resourceTable builds a row of columns for each resource in data
+func resourceTable(columns []column, data interface{}) table {
+	t := table{}
+	for _, c := range columns {
+		t.headers = append(t.headers, c.header)
+	}
+
+	items, ok := data.([]interface{})
+	if !ok {
+		items = []interface{}{data}
+	}
+
+	for _, item := range items {
+		row := make([]string, 0, len(columns))
+		for _, c := range columns {
+			value, err := c.expr.Search(item)
+			if err != nil {
+				value = nil
+			}
+			row = append(row, cell(value))
+		}
+		t.rows = append(t.rows, row)
+	}
+
+	return t
+}
+
+// genericTable lays out any normalized value. A list of objects has a column
+// per key, an object has a row per key and scalars have a row each.
+func genericTable(data interface{}) table {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		t := table{headers: []string{"KEY", "VALUE"}}
+		for _, k := range sortedKeys(d) {
+			t.rows = append(t.rows, []string{k, cell(d[k])})
+		}
+		return t
+	case []interface{}:
+		keys := make(map[string]interface{})
+		for _, item := range d {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				keys = nil
+				break
+			}
+			for k := range m {
+				keys[k] = nil
+			}
+		}
+
+		if keys == nil || len(d) == 0 {
+			t := table{}
+			for _, item := range d {
+				t.rows = append(t.rows, []string{cell(item)})
+			}
+			return t
+		}
+
+		t := table{}
+		for _, k := range sortedKeys(keys) {
+			t.headers = append(t.headers, k)
+		}
+		for _, item := range d {
+			m := item.(map[string]interface{})
+			row := make([]string, 0, len(keys))
+			for _, k := range sortedKeys(keys) {
+				row = append(row, cell(m[k]))
+			}
+			t.rows = append(t.rows, row)
+		}
+		return t
+	default:
+		return table{rows: [][]string{{cell(d)}}}
+	}
+}
+
+// cell formats a single value, nested values are shown as compact JSON
+func cell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return missing
+	case string:
+		if v == "" {
+			return missing
+		}
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		out, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(out)
+	}
+}
+
+func writeTable(w io.Writer, t table) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	if len(t.headers) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+	}
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
@@ -1,16 +1,21 @@
 package parameter_group
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
 type CreateRequest struct {
-	Family      string
-	Name        string
-	Description string
+	Family      string `json:"family,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Parameters to set once the group is created (optional)
+	Parameters []Param `json:"parameters,omitempty"`
 }
 
 func (r *CreateRequest) SetFamily(v string) *CreateRequest {
@@ -28,27 +33,46 @@ func (r *CreateRequest) SetDescription(v string) *CreateRequest {
 	return r
 }
 
-func CreateDBParameterGroup(svc *rds.RDS, req CreateRequest) (
+func (r *CreateRequest) SetParameters(v []Param) *CreateRequest {
+	r.Parameters = v
+	return r
+}
+
+// ValidateParameters checks every parameter of the request
+func (r CreateRequest) ValidateParameters() error {
+	for _, p := range r.Parameters {
+		err := p.Validate()
+		if err != nil {
+			return fmt.Errorf("parameter group %s: %w", r.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func CreateDBParameterGroup(svc rds_api.RDSAPI, req CreateRequest) (
 	*rds.DBParameterGroup, error,
 ) {
+	err := req.ValidateParameters()
+	if err != nil {
+		return nil, err
+	}
+
 	input := NewCreateDBParameterGroupInput(req)
 
 	result, err := svc.CreateDBParameterGroup(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBParameterGroupQuotaExceededFault:
-				log.Warn(rds.ErrCodeDBParameterGroupQuotaExceededFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeDBParameterGroupAlreadyExistsFault:
-				log.Warn(rds.ErrCodeDBParameterGroupAlreadyExistsFault, aerr.Error())
-				return nil, aerr
-			default:
-				log.Warn(aerr.Error())
-				return nil, aerr
-			}
-		} else {
-			log.Warn(err.Error())
+		err = rds_errors.Wrap(err, rds_errors.KindParameterGroup, req.Name)
+		log.Warn(err)
+		return nil, err
+	}
+
+	if len(req.Parameters) > 0 {
+		updateReq := UpdateRequest{}
+		updateReq.SetName(req.Name).SetParameters(req.Parameters)
+
+		err = UpdateDBParameterGroup(svc, updateReq)
+		if err != nil {
 			return nil, err
 		}
 	}
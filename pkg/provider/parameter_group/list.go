@@ -0,0 +1,53 @@
+package parameter_group
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/tags"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListRequest narrows the groups returned by ListDBParameterGroups. RDS does not
+// filter parameter groups, so only tags are supported.
+type ListRequest struct {
+	// Tags the groups must carry (optional)
+	Tags tags.Selector
+}
+
+// ListDBParameterGroups returns the DB parameter groups in the region matching req,
+// following every page of results
+func ListDBParameterGroups(svc rds_api.RDSAPI, req ListRequest) ([]*rds.DBParameterGroup, error) {
+	input := &rds.DescribeDBParameterGroupsInput{}
+
+	groups := make([]*rds.DBParameterGroup, 0)
+	for {
+		result, err := svc.DescribeDBParameterGroups(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindParameterGroup, "")
+			log.Warn(err)
+			return nil, err
+		}
+
+		for _, group := range result.DBParameterGroups {
+			ok, err := req.Tags.Select(
+				svc, rds_errors.KindParameterGroup,
+				aws.StringValue(group.DBParameterGroupName), aws.StringValue(group.DBParameterGroupArn),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				groups = append(groups, group)
+			}
+		}
+
+		if aws.StringValue(result.Marker) == "" {
+			break
+		}
+		input.Marker = result.Marker
+	}
+
+	return groups, nil
+}
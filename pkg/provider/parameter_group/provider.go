@@ -4,8 +4,9 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -14,29 +15,53 @@ var (
 )
 
 func init() {
-	NotFoundErr = errors.New("db parameter group not found")
+	NotFoundErr = rds_errors.New(rds_errors.NotFound, rds_errors.KindParameterGroup, "")
 }
 
-func FindDBParameterGroup(svc *rds.RDS, paramGroupName string) (*rds.DBParameterGroup, error) {
+func FindDBParameterGroup(svc rds_api.RDSAPI, paramGroupName string) (*rds.DBParameterGroup, error) {
 	input := &rds.DescribeDBParameterGroupsInput{
 		DBParameterGroupName: aws.String(paramGroupName),
 	}
 
 	result, err := svc.DescribeDBParameterGroups(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBParameterGroupNotFoundFault:
-				log.Info(rds.ErrCodeDBParameterGroupNotFoundFault, aerr.Error())
-				return nil, NotFoundErr
-			default:
-				log.Warn(aerr.Error())
-				return nil, aerr
-			}
+		err = rds_errors.Wrap(err, rds_errors.KindParameterGroup, paramGroupName)
+		if errors.Is(err, NotFoundErr) {
+			log.Info(err)
 		} else {
-			log.Warn(err.Error())
-			return nil, err
+			log.Warn(err)
 		}
+		return nil, err
 	}
 	return result.DBParameterGroups[0], nil
 }
+
+// FindDBParameters returns the user modified parameters of the parameter group
+func FindDBParameters(svc rds_api.RDSAPI, paramGroupName string) ([]*rds.Parameter, error) {
+	input := &rds.DescribeDBParametersInput{
+		DBParameterGroupName: aws.String(paramGroupName),
+		Source:               aws.String("user"),
+	}
+
+	params := make([]*rds.Parameter, 0)
+	for {
+		result, err := svc.DescribeDBParameters(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindParameterGroup, paramGroupName)
+			if errors.Is(err, NotFoundErr) {
+				log.Info(err)
+			} else {
+				log.Warn(err)
+			}
+			return nil, err
+		}
+
+		params = append(params, result.Parameters...)
+		if result.Marker == nil || *result.Marker == "" {
+			break
+		}
+		input.Marker = result.Marker
+	}
+
+	return params, nil
+}
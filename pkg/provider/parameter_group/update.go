@@ -1,30 +1,109 @@
 package parameter_group
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
 const (
-	Immediate applyMethod = "immediate"
-	String    valType     = "string"
+	Immediate     applyMethod = "immediate"
+	PendingReboot applyMethod = "pending-reboot"
+
+	String  valType = "string"
+	Integer valType = "integer"
+	Float   valType = "float"
+	Boolean valType = "boolean"
+	List    valType = "list"
+
+	// maxModifyParameters is the most parameters RDS accepts in one modify call
+	maxModifyParameters = 20
 )
 
 type applyMethod string
 type valType string
 
 type Param struct {
-	Apply     applyMethod
-	Name      string
-	Value     interface{}
-	ValueType valType
+	Apply     applyMethod `json:"apply,omitempty"`
+	Name      string      `json:"name,omitempty"`
+	Value     interface{} `json:"value,omitempty"`
+	ValueType valType     `json:"value_type,omitempty"`
+}
+
+// Validate checks that the parameter is named, is applied with a known method
+// and has a value of its value type
+func (p Param) Validate() error {
+	if p.Name == "" {
+		return errors.New("parameter name is required")
+	}
+
+	switch p.Apply {
+	case Immediate, PendingReboot:
+	default:
+		return fmt.Errorf(
+			"parameter %s: invalid apply %q, expected %s or %s", p.Name, p.Apply, Immediate, PendingReboot,
+		)
+	}
+
+	value, err := p.StringValue()
+	if err != nil {
+		return err
+	}
+
+	switch p.ValueType {
+	case String, List, Boolean:
+	case Integer:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case Float:
+		_, err = strconv.ParseFloat(value, 64)
+	case "":
+		return fmt.Errorf("parameter %s: value_type is required", p.Name)
+	default:
+		return fmt.Errorf(
+			"parameter %s: unknown value_type %q, expected one of %s, %s, %s, %s or %s",
+			p.Name, p.ValueType, String, Integer, Float, Boolean, List,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("parameter %s: %v is not a valid %s", p.Name, p.Value, p.ValueType)
+	}
+
+	return nil
+}
+
+// StringValue returns the value of the parameter in the form RDS takes and
+// reports it. Booleans become 1 or 0 and numbers are written out in full.
+func (p Param) StringValue() (string, error) {
+	switch v := p.Value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case nil:
+		return "", fmt.Errorf("parameter %s: value is required", p.Name)
+	default:
+		return "", fmt.Errorf("parameter %s: unsupported value %v of type %T", p.Name, v, v)
+	}
 }
 
 type UpdateRequest struct {
 	name       string
-	parameters []*rds.Parameter
+	parameters []Param
 }
 
 func (r *UpdateRequest) SetName(v string) *UpdateRequest {
@@ -33,54 +112,84 @@ func (r *UpdateRequest) SetName(v string) *UpdateRequest {
 }
 
 func (r *UpdateRequest) SetParameters(params []Param) *UpdateRequest {
-	awsParams := make([]*rds.Parameter, 0)
-	for _, p := range params {
-		switch p.ValueType {
-		case String:
-			awsParams = append(awsParams, &rds.Parameter{
-				ApplyMethod:    aws.String(string(p.Apply)),
-				ParameterName:  aws.String(p.Name),
-				ParameterValue: aws.String(p.Value.(string)),
-			})
+	r.parameters = params
+	return r
+}
+
+// ChangedParameters returns the desired parameters whose values differ from the
+// current parameters of the group
+func ChangedParameters(current []*rds.Parameter, desired []Param) []Param {
+	currentValues := make(map[string]string)
+	for _, p := range current {
+		if p.ParameterName == nil || p.ParameterValue == nil {
+			continue
 		}
+		currentValues[*p.ParameterName] = *p.ParameterValue
 	}
 
-	r.parameters = awsParams
-	return r
-}
+	changed := make([]Param, 0)
+	for _, p := range desired {
+		value, ok := currentValues[p.Name]
+		desiredValue, err := p.StringValue()
+		if !ok || err != nil || value != desiredValue {
+			changed = append(changed, p)
+		}
+	}
 
-func UpdateDBParameterGroup(svc *rds.RDS, req UpdateRequest) error {
-	input := NewModifyDBParameterGroupInput(req)
+	return changed
+}
 
-	result, err := svc.ModifyDBParameterGroup(input)
+// UpdateDBParameterGroup sets the parameters of the request, at most
+// maxModifyParameters per call
+func UpdateDBParameterGroup(svc rds_api.RDSAPI, req UpdateRequest) error {
+	inputs, err := NewModifyDBParameterGroupInputs(req)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBParameterGroupNotFoundFault:
-				log.Warn(rds.ErrCodeDBParameterGroupNotFoundFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeInvalidDBParameterGroupStateFault:
-				log.Warn(rds.ErrCodeInvalidDBParameterGroupStateFault, aerr.Error())
-				return aerr
-			default:
-				log.Warn(aerr.Error())
-				return aerr
-			}
-		} else {
-			log.Warn(err.Error())
+		return fmt.Errorf("parameter group %s: %w", req.name, err)
+	}
+
+	for _, input := range inputs {
+		result, err := svc.ModifyDBParameterGroup(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindParameterGroup, req.name)
+			log.Warn(err)
 			return err
 		}
+		log.Debug(result)
 	}
-	log.Debug(result)
 
 	return nil
 }
 
-func NewModifyDBParameterGroupInput(req UpdateRequest) *rds.ModifyDBParameterGroupInput {
-	input := &rds.ModifyDBParameterGroupInput{
-		DBParameterGroupName: aws.String(req.name),
-		Parameters:           req.parameters,
+// NewModifyDBParameterGroupInputs splits the parameters of the request into
+// inputs of at most maxModifyParameters
+func NewModifyDBParameterGroupInputs(req UpdateRequest) ([]*rds.ModifyDBParameterGroupInput, error) {
+	inputs := make([]*rds.ModifyDBParameterGroupInput, 0)
+	for start := 0; start < len(req.parameters); start += maxModifyParameters {
+		end := start + maxModifyParameters
+		if end > len(req.parameters) {
+			end = len(req.parameters)
+		}
+
+		awsParams := make([]*rds.Parameter, 0, end-start)
+		for _, p := range req.parameters[start:end] {
+			err := p.Validate()
+			if err != nil {
+				return nil, err
+			}
+
+			value, _ := p.StringValue()
+			awsParams = append(awsParams, &rds.Parameter{
+				ApplyMethod:    aws.String(string(p.Apply)),
+				ParameterName:  aws.String(p.Name),
+				ParameterValue: aws.String(value),
+			})
+		}
+
+		inputs = append(inputs, &rds.ModifyDBParameterGroupInput{
+			DBParameterGroupName: aws.String(req.name),
+			Parameters:           awsParams,
+		})
 	}
 
-	return input
+	return inputs, nil
 }
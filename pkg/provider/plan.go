@@ -0,0 +1,411 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster_parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/subnet_group"
+)
+
+var (
+	BlockedChangeErr error
+)
+
+func init() {
+	BlockedChangeErr = errors.New("changes can not be applied")
+}
+
+// FieldChange describes a single field which differs between the current and
+// desired state of a resource
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+	// Whether the field can only be changed by recreating the resource
+	RequiresReplacement bool
+}
+
+// Change describes what applying a stack would do to a single resource
+type Change struct {
+	Kind   string
+	Id     string
+	Action Action
+	Fields []FieldChange
+	// Why the change can not be applied when Action is ActionBlocked
+	Reason string
+}
+
+// BlockedChanges returns an error wrapping BlockedChangeErr which describes
+// each blocked change, or nil when there are none
+func BlockedChanges(changes []Change) error {
+	blocked := make([]string, 0)
+	for _, c := range changes {
+		if c.Action == ActionBlocked {
+			blocked = append(blocked, fmt.Sprintf("%s %s: %s", c.Kind, c.Id, c.Reason))
+		}
+	}
+
+	if len(blocked) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", BlockedChangeErr, strings.Join(blocked, "; "))
+}
+
+// PlanStack compares the stack to the current state of its resources without
+// modifying anything
+func PlanStack(svc rds_api.RDSAPI, stack Stack) ([]Change, error) {
+	changes := make([]Change, 0)
+
+	change, err := PlanSubnetGroup(svc, stack.SubnetGroup)
+	if err != nil {
+		return changes, err
+	}
+	changes = append(changes, change)
+
+	if stack.ClusterParameterGroup.Name != "" {
+		change, err = PlanClusterParameterGroup(svc, stack.ClusterParameterGroup)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+
+	if stack.ParameterGroup.Name != "" {
+		change, err = PlanParameterGroup(svc, stack.ParameterGroup)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+
+	instanceChanges, err := PlanCluster(svc, stack.Cluster, stack.Instances)
+	if err != nil {
+		return changes, err
+	}
+	changes = append(changes, instanceChanges...)
+
+	return changes, nil
+}
+
+func PlanSubnetGroup(svc rds_api.RDSAPI, req subnet_group.CreateSubnetGroupRequest) (Change, error) {
+	change := Change{Kind: KindSubnetGroup, Id: req.Name}
+
+	group, err := subnet_group.FindDBSubnetGroup(svc, req.Name)
+	if err != nil {
+		if !errors.Is(err, subnet_group.SubnetGroupNotFoundErr) {
+			return change, err
+		}
+		change.Action = ActionCreate
+		return change, nil
+	}
+
+	current := make([]string, 0)
+	for _, s := range group.Subnets {
+		current = append(current, aws.StringValue(s.SubnetIdentifier))
+	}
+
+	fields := fieldChanges{}
+	fields.add("description", aws.StringValue(group.DBSubnetGroupDescription), req.Description, false)
+	if !subnet_group.SameSubnets(group, req.SubnetIds) {
+		fields.add("subnet_ids", joinSorted(current), joinSorted(req.SubnetIds), false)
+	}
+
+	return fields.change(change), nil
+}
+
+func PlanClusterParameterGroup(svc rds_api.RDSAPI, req cluster_parameter_group.CreateRequest) (Change, error) {
+	change := Change{Kind: KindClusterParameterGroup, Id: req.Name}
+
+	err := req.ValidateParameters()
+	if err != nil {
+		return change, err
+	}
+
+	group, err := cluster_parameter_group.FindDBClusterParameterGroup(svc, req.Name)
+	if err != nil {
+		if !errors.Is(err, cluster_parameter_group.NotFoundErr) {
+			return change, err
+		}
+		change.Action = ActionCreate
+		return change, nil
+	}
+
+	fields := fieldChanges{}
+	fields.add("family", aws.StringValue(group.DBParameterGroupFamily), req.Family, true)
+
+	current, err := cluster_parameter_group.FindDBClusterParameters(svc, req.Name)
+	if err != nil {
+		return change, err
+	}
+
+	for _, p := range cluster_parameter_group.ChangedParameters(current, req.Parameters) {
+		value, _ := p.StringValue()
+		fields.add("parameters."+p.Name, parameterValue(current, p.Name), value, false)
+	}
+
+	return fields.change(change), nil
+}
+
+func PlanParameterGroup(svc rds_api.RDSAPI, req parameter_group.CreateRequest) (Change, error) {
+	change := Change{Kind: KindParameterGroup, Id: req.Name}
+
+	err := req.ValidateParameters()
+	if err != nil {
+		return change, err
+	}
+
+	group, err := parameter_group.FindDBParameterGroup(svc, req.Name)
+	if err != nil {
+		if !errors.Is(err, parameter_group.NotFoundErr) {
+			return change, err
+		}
+		change.Action = ActionCreate
+		return change, nil
+	}
+
+	fields := fieldChanges{}
+	fields.add("family", aws.StringValue(group.DBParameterGroupFamily), req.Family, true)
+
+	current, err := parameter_group.FindDBParameters(svc, req.Name)
+	if err != nil {
+		return change, err
+	}
+
+	for _, p := range parameter_group.ChangedParameters(current, req.Parameters) {
+		value, _ := p.StringValue()
+		fields.add("parameters."+p.Name, parameterValue(current, p.Name), value, false)
+	}
+
+	return fields.change(change), nil
+}
+
+// PlanCluster plans the cluster and its instances. Instances which are members
+// of the cluster but are not desired are blocked, as apply does not delete
+// instances.
+func PlanCluster(svc rds_api.RDSAPI, input cluster.NewDBClusterInput, instances []instance.NewDBInstanceInput) (
+	[]Change, error,
+) {
+	changes := make([]Change, 0)
+	change := Change{Kind: KindCluster, Id: input.ClusterId}
+
+	dbCluster, err := cluster.FindDBCluster(svc, input.ClusterId)
+	if err != nil {
+		if !errors.Is(err, cluster.ClusterNotFoundErr) {
+			return changes, err
+		}
+
+		change.Action = ActionCreate
+		changes = append(changes, change)
+		for _, i := range instances {
+			changes = append(changes, Change{
+				Kind: KindInstance, Id: i.InstanceIdentifier, Action: ActionCreate,
+			})
+		}
+		return changes, nil
+	}
+
+	changes = append(changes, planClusterFields(dbCluster, input, change))
+
+	desired := make(map[string]bool)
+	for _, i := range instances {
+		desired[i.InstanceIdentifier] = true
+
+		change, err := PlanInstance(svc, i)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, change)
+	}
+
+	for _, m := range dbCluster.DBClusterMembers {
+		id := aws.StringValue(m.DBInstanceIdentifier)
+		if !desired[id] {
+			changes = append(changes, Change{
+				Kind: KindInstance, Id: id, Action: ActionBlocked,
+				Reason: "member of the cluster missing from the stack, apply does not delete instances",
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+func planClusterFields(c *rds.DBCluster, input cluster.NewDBClusterInput, change Change) Change {
+	current := make([]string, 0)
+	for _, g := range c.VpcSecurityGroups {
+		current = append(current, aws.StringValue(g.VpcSecurityGroupId))
+	}
+
+	fields := fieldChanges{}
+	fields.add("engine", aws.StringValue(c.Engine), input.Engine, true)
+	fields.add("master_username", aws.StringValue(c.MasterUsername), input.MasterUsername, true)
+	fields.add("subnet_group_name", aws.StringValue(c.DBSubnetGroup), input.SubnetGroupName, true)
+	fields.add(
+		"storage_encrypted",
+		fmt.Sprint(aws.BoolValue(c.StorageEncrypted)), fmt.Sprint(input.StorageEncrypted),
+		true,
+	)
+
+	if input.EngineVersion != "" {
+		fields.add("engine_version", aws.StringValue(c.EngineVersion), input.EngineVersion, false)
+	}
+	if len(input.SecurityGroupIds) > 0 && !cluster.SameSecurityGroups(c, input.SecurityGroupIds) {
+		fields.add(
+			"security_group_ids", joinSorted(current), joinSorted(input.SecurityGroupIds), false,
+		)
+	}
+	if input.ParameterGroupName != "" {
+		fields.add(
+			"parameter_group_name",
+			aws.StringValue(c.DBClusterParameterGroup), input.ParameterGroupName,
+			false,
+		)
+	}
+	if input.BackupRetentionPeriod > 0 {
+		fields.add(
+			"backup_retention_period",
+			fmt.Sprint(aws.Int64Value(c.BackupRetentionPeriod)), fmt.Sprint(input.BackupRetentionPeriod),
+			false,
+		)
+	}
+	if input.DeletionProtection != nil {
+		fields.add(
+			"deletion_protection",
+			fmt.Sprint(aws.BoolValue(c.DeletionProtection)), fmt.Sprint(*input.DeletionProtection),
+			false,
+		)
+	}
+
+	return fields.change(change)
+}
+
+func PlanInstance(svc rds_api.RDSAPI, input instance.NewDBInstanceInput) (Change, error) {
+	change := Change{Kind: KindInstance, Id: input.InstanceIdentifier}
+
+	dbInstance, err := instance.FindDBClusterInstance(svc, input.InstanceIdentifier)
+	if err != nil {
+		if !errors.Is(err, instance.NotFoundErr) {
+			return change, err
+		}
+		change.Action = ActionCreate
+		return change, nil
+	}
+
+	current := make([]string, 0)
+	for _, g := range dbInstance.DBParameterGroups {
+		current = append(current, aws.StringValue(g.DBParameterGroupName))
+	}
+
+	fields := fieldChanges{}
+	fields.add(
+		"cluster_identifier",
+		aws.StringValue(dbInstance.DBClusterIdentifier), input.ClusterIdentifier,
+		true,
+	)
+	fields.add("engine", aws.StringValue(dbInstance.Engine), input.Engine, true)
+
+	if input.InstanceClass != "" {
+		fields.add("instance_class", aws.StringValue(dbInstance.DBInstanceClass), input.InstanceClass, false)
+	}
+	if input.ParameterGroupName != "" && !instance.HasParameterGroup(dbInstance, input.ParameterGroupName) {
+		fields.add("parameter_group_name", joinSorted(current), input.ParameterGroupName, false)
+	}
+	fields.add(
+		"publicly_accessible",
+		fmt.Sprint(aws.BoolValue(dbInstance.PubliclyAccessible)), fmt.Sprint(input.PubliclyAccessible),
+		false,
+	)
+
+	return fields.change(change), nil
+}
+
+// WritePlan writes a human readable description of the changes to w
+func WritePlan(w io.Writer, changes []Change) {
+	symbols := map[Action]string{
+		ActionNone:    " ",
+		ActionCreate:  "+",
+		ActionUpdate:  "~",
+		ActionBlocked: "!",
+	}
+
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s %s %s (%s)\n", symbols[c.Action], c.Kind, c.Id, c.Action)
+		for _, f := range c.Fields {
+			note := ""
+			if f.RequiresReplacement {
+				note = " (forces replacement)"
+			}
+			fmt.Fprintf(w, "    %s: %q => %q%s\n", f.Field, f.Old, f.New, note)
+		}
+		if c.Reason != "" {
+			fmt.Fprintf(w, "    blocked: %s\n", c.Reason)
+		}
+	}
+}
+
+type fieldChanges []FieldChange
+
+// add records a change when the current and desired values differ
+func (f *fieldChanges) add(field, current, desired string, requiresReplacement bool) {
+	if current == desired {
+		return
+	}
+
+	*f = append(*f, FieldChange{
+		Field:               field,
+		Old:                 current,
+		New:                 desired,
+		RequiresReplacement: requiresReplacement,
+	})
+}
+
+// change derives the action for the resource from the recorded field changes.
+// Changes to fields which require replacing the resource are blocked.
+func (f fieldChanges) change(c Change) Change {
+	c.Fields = f
+	c.Action = ActionNone
+
+	replaced := make([]string, 0)
+	for _, field := range f {
+		if field.RequiresReplacement {
+			replaced = append(replaced, field.Field)
+		}
+		c.Action = ActionUpdate
+	}
+
+	if len(replaced) > 0 {
+		c.Action = ActionBlocked
+		c.Reason = fmt.Sprintf(
+			"changing %s requires replacing the resource, apply does not replace resources",
+			strings.Join(replaced, ", "),
+		)
+	}
+
+	return c
+}
+
+func parameterValue(params []*rds.Parameter, name string) string {
+	for _, p := range params {
+		if aws.StringValue(p.ParameterName) == name {
+			return aws.StringValue(p.ParameterValue)
+		}
+	}
+
+	return ""
+}
+
+func joinSorted(v []string) string {
+	sorted := append([]string{}, v...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cvgw/rds_provider/pkg/provider/guard"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/snapshot"
+	log "github.com/sirupsen/logrus"
+)
+
+// PruneRequest selects the manual cluster snapshots to prune and the policy
+// deciding which of them are kept
+type PruneRequest struct {
+	// Snapshots to consider. Only manual snapshots are, whatever the
+	// snapshot type of the request.
+	Snapshots snapshot.ListRequest
+	Policy    snapshot.RetentionPolicy
+	// Whether to only report what would be deleted
+	DryRun bool
+	// Checks made before anything is deleted (optional). Protected snapshots
+	// are kept rather than failing the prune.
+	Guard *guard.Guard
+}
+
+// PruneSnapshots deletes the snapshots selected by req which its policy does
+// not keep, and reports the decision made for every snapshot. Deletion stops
+// at the first failure, the decisions made so far are returned along with the
+// error.
+func PruneSnapshots(svc rds_api.RDSAPI, req PruneRequest, now time.Time) ([]snapshot.Decision, error) {
+	err := req.Policy.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	listReq := req.Snapshots
+	listReq.SnapshotType = snapshot.TypeManual
+
+	snapshots, err := snapshot.ListDBClusterSnapshots(svc, listReq)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := req.Policy.Apply(snapshots, now)
+
+	if req.Guard != nil {
+		resources := make([]string, 0)
+		for i, d := range decisions {
+			if d.Action != snapshot.ActionDelete {
+				continue
+			}
+
+			err := req.Guard.CheckClusterSnapshot(svc, d.SnapshotId)
+			if errors.Is(err, guard.ProtectedErr) {
+				log.Info(err)
+				decisions[i].Action = snapshot.ActionKeep
+				decisions[i].Reasons = append(decisions[i].Reasons, "protected")
+				continue
+			}
+			if err != nil {
+				return decisions, err
+			}
+
+			resources = append(resources, fmt.Sprintf("%s %s", KindClusterSnapshot, d.SnapshotId))
+		}
+
+		if !req.DryRun {
+			err = req.Guard.ConfirmDelete(resources)
+			if err != nil {
+				return decisions, err
+			}
+		}
+	}
+
+	if req.DryRun {
+		return decisions, nil
+	}
+
+	for i, d := range decisions {
+		if d.Action != snapshot.ActionDelete {
+			continue
+		}
+
+		err = snapshot.DeleteDBClusterSnapshot(svc, d.SnapshotId)
+		if err != nil {
+			return decisions, err
+		}
+		decisions[i].Action = snapshot.ActionDeleted
+		log.Infof("deleted cluster snapshot %s", d.SnapshotId)
+	}
+
+	return decisions, nil
+}
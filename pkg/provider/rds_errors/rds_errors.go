@@ -0,0 +1,234 @@
+// Package rds_errors classifies the errors returned by the RDS API so callers
+// can branch on what went wrong instead of on AWS error code strings.
+//
+//	_, err := instance.FindDBClusterInstance(svc, id)
+//	if errors.Is(err, rds_errors.ErrNotFound) {
+//		...
+//	}
+//
+//	var rerr *rds_errors.Error
+//	if errors.As(err, &rerr) && rerr.Class == rds_errors.InvalidState {
+//		...
+//	}
+package rds_errors
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+const (
+	KindSubnetGroup           = "subnet_group"
+	KindClusterParameterGroup = "cluster_parameter_group"
+	KindParameterGroup        = "parameter_group"
+	KindCluster               = "cluster"
+	KindInstance              = "instance"
+	KindClusterSnapshot       = "cluster_snapshot"
+	KindSnapshot              = "snapshot"
+	KindSecurityGroup         = "security_group"
+	KindOptionGroup           = "option_group"
+
+	NotFound          Class = "not found"
+	AlreadyExists     Class = "already exists"
+	InvalidState      Class = "invalid state"
+	InvalidRequest    Class = "invalid request"
+	QuotaExceeded     Class = "quota exceeded"
+	DependencyMissing Class = "dependency missing"
+	Throttled         Class = "throttled"
+)
+
+// Class is the broad category of an error
+type Class string
+
+var (
+	ErrNotFound          = New(NotFound, "", "")
+	ErrAlreadyExists     = New(AlreadyExists, "", "")
+	ErrInvalidState      = New(InvalidState, "", "")
+	ErrInvalidRequest    = New(InvalidRequest, "", "")
+	ErrQuotaExceeded     = New(QuotaExceeded, "", "")
+	ErrDependencyMissing = New(DependencyMissing, "", "")
+	ErrThrottled         = New(Throttled, "", "")
+)
+
+// code describes what an AWS error code means. For not found codes kind is
+// the kind of resource which could not be found.
+type code struct {
+	class Class
+	kind  string
+}
+
+var codes = map[string]code{
+	rds.ErrCodeDBClusterNotFoundFault:               {NotFound, KindCluster},
+	rds.ErrCodeDBInstanceNotFoundFault:              {NotFound, KindInstance},
+	rds.ErrCodeDBSubnetGroupNotFoundFault:           {NotFound, KindSubnetGroup},
+	rds.ErrCodeDBParameterGroupNotFoundFault:        {NotFound, KindParameterGroup},
+	rds.ErrCodeDBClusterParameterGroupNotFoundFault: {NotFound, KindClusterParameterGroup},
+	rds.ErrCodeDBClusterSnapshotNotFoundFault:       {NotFound, KindClusterSnapshot},
+	rds.ErrCodeDBSnapshotNotFoundFault:              {NotFound, KindSnapshot},
+	rds.ErrCodeDBSecurityGroupNotFoundFault:         {NotFound, KindSecurityGroup},
+	rds.ErrCodeOptionGroupNotFoundFault:             {NotFound, KindOptionGroup},
+
+	rds.ErrCodeDBClusterAlreadyExistsFault:         {AlreadyExists, KindCluster},
+	rds.ErrCodeDBInstanceAlreadyExistsFault:        {AlreadyExists, KindInstance},
+	rds.ErrCodeDBSubnetGroupAlreadyExistsFault:     {AlreadyExists, KindSubnetGroup},
+	rds.ErrCodeDBParameterGroupAlreadyExistsFault:  {AlreadyExists, KindParameterGroup},
+	rds.ErrCodeDBClusterSnapshotAlreadyExistsFault: {AlreadyExists, KindClusterSnapshot},
+	rds.ErrCodeDBSnapshotAlreadyExistsFault:        {AlreadyExists, KindSnapshot},
+
+	rds.ErrCodeInvalidDBClusterStateFault:         {InvalidState, KindCluster},
+	rds.ErrCodeInvalidDBInstanceStateFault:        {InvalidState, KindInstance},
+	rds.ErrCodeInvalidDBSubnetGroupStateFault:     {InvalidState, KindSubnetGroup},
+	rds.ErrCodeInvalidDBParameterGroupStateFault:  {InvalidState, KindParameterGroup},
+	rds.ErrCodeInvalidDBClusterSnapshotStateFault: {InvalidState, KindClusterSnapshot},
+	rds.ErrCodeInvalidDBSnapshotStateFault:        {InvalidState, KindSnapshot},
+	rds.ErrCodeInvalidDBSecurityGroupStateFault:   {InvalidState, KindSecurityGroup},
+	rds.ErrCodeInvalidDBSubnetStateFault:          {InvalidState, KindSubnetGroup},
+
+	// the subnets or security groups of the request do not fit together, which
+	// waiting does not change
+	rds.ErrCodeInvalidVPCNetworkStateFault: {InvalidRequest, ""},
+
+	rds.ErrCodeDBClusterQuotaExceededFault:        {QuotaExceeded, KindCluster},
+	rds.ErrCodeInstanceQuotaExceededFault:         {QuotaExceeded, KindInstance},
+	rds.ErrCodeDBSubnetGroupQuotaExceededFault:    {QuotaExceeded, KindSubnetGroup},
+	rds.ErrCodeDBSubnetQuotaExceededFault:         {QuotaExceeded, KindSubnetGroup},
+	rds.ErrCodeDBParameterGroupQuotaExceededFault: {QuotaExceeded, KindParameterGroup},
+	rds.ErrCodeSnapshotQuotaExceededFault:         {QuotaExceeded, KindSnapshot},
+	rds.ErrCodeSharedSnapshotQuotaExceededFault:   {QuotaExceeded, KindSnapshot},
+	rds.ErrCodeStorageQuotaExceededFault:          {QuotaExceeded, ""},
+
+	"Throttling":                             {Throttled, ""},
+	"ThrottlingException":                    {Throttled, ""},
+	"ThrottledException":                     {Throttled, ""},
+	"RequestThrottled":                       {Throttled, ""},
+	"RequestThrottledException":              {Throttled, ""},
+	"RequestLimitExceeded":                   {Throttled, ""},
+	"TooManyRequestsException":               {Throttled, ""},
+	"ProvisionedThroughputExceededException": {Throttled, ""},
+}
+
+// Error is a classified error about a single resource. Err holds the
+// underlying AWS error, if any.
+type Error struct {
+	Class Class
+	Kind  string
+	Id    string
+	// Dependency is the kind of the missing resource of a DependencyMissing
+	// error, or of the existing resource of an AlreadyExists error raised for
+	// a different kind, e.g. the final snapshot of a deleted cluster
+	Dependency string
+	Err        error
+}
+
+// New builds an Error which is not caused by an AWS error
+func New(class Class, kind, id string) *Error {
+	return &Error{Class: class, Kind: kind, Id: id}
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Class)
+	if e.Dependency != "" {
+		msg = fmt.Sprintf("%s %s", e.Dependency, msg)
+	}
+
+	if e.Id != "" {
+		msg = fmt.Sprintf("%s %s: %s", e.Kind, e.Id, msg)
+	} else if e.Kind != "" {
+		msg = fmt.Sprintf("%s %s", e.Kind, msg)
+	}
+
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %s", msg, e.Err.Error())
+	}
+
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error of the same class. The kind and id of
+// target are only compared when they are set, so ErrNotFound matches any not
+// found error and instance.NotFoundErr matches any missing instance.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return t.Class == e.Class &&
+		(t.Kind == "" || t.Kind == e.Kind) &&
+		(t.Id == "" || t.Id == e.Id) &&
+		(t.Dependency == "" || t.Dependency == e.Dependency)
+}
+
+// Wrap classifies err, an error returned by an RDS call made for the resource
+// of the given kind and id. Errors which are not AWS errors or whose code is
+// not known are returned unchanged. A not found error for a resource of a
+// different kind is reported as DependencyMissing, and an already exists error
+// for a different kind names that kind.
+func Wrap(err error, kind, id string) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+
+	c, ok := codes[aerr.Code()]
+	if !ok {
+		return err
+	}
+
+	e := &Error{Class: c.class, Kind: kind, Id: id, Err: err}
+	if c.class == NotFound && c.kind != kind && !sameKind(c.kind, kind) {
+		e.Class = DependencyMissing
+		e.Dependency = c.kind
+	}
+
+	if c.class == AlreadyExists && c.kind != kind {
+		e.Dependency = c.kind
+	}
+
+	return e
+}
+
+// sameKind reports whether a not found code for kind a can refer to kind b.
+// Several cluster parameter group calls report a missing group with the DB
+// parameter group code.
+func sameKind(a, b string) bool {
+	return a == KindParameterGroup && b == KindClusterParameterGroup
+}
+
+// ClassOf returns the class of err, or an empty Class if err has not been
+// classified
+func ClassOf(err error) Class {
+	for err != nil {
+		if e, ok := err.(*Error); ok {
+			return e.Class
+		}
+
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return ""
+		}
+		err = u.Unwrap()
+	}
+
+	return ""
+}
+
+// ClassifyCode returns the class of an AWS error code, or an empty Class if
+// the code is not known
+func ClassifyCode(awsCode string) Class {
+	return codes[awsCode].class
+}
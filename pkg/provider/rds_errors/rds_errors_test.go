@@ -0,0 +1,154 @@
+package rds_errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+func TestWrap(t *testing.T) {
+	cases := []struct {
+		name           string
+		err            error
+		kind           string
+		wantClass      Class
+		wantDependency string
+		wantIs         []error
+	}{
+		{
+			name:      "not found",
+			err:       awserr.New(rds.ErrCodeDBClusterNotFoundFault, "not found", nil),
+			kind:      KindCluster,
+			wantClass: NotFound,
+			wantIs:    []error{ErrNotFound, New(NotFound, KindCluster, "c1")},
+		},
+		{
+			name:           "not found for another kind",
+			err:            awserr.New(rds.ErrCodeDBSubnetGroupNotFoundFault, "not found", nil),
+			kind:           KindCluster,
+			wantClass:      DependencyMissing,
+			wantDependency: KindSubnetGroup,
+			wantIs:         []error{ErrDependencyMissing},
+		},
+		{
+			name:      "parameter group code for a cluster parameter group",
+			err:       awserr.New(rds.ErrCodeDBParameterGroupNotFoundFault, "not found", nil),
+			kind:      KindClusterParameterGroup,
+			wantClass: NotFound,
+			wantIs:    []error{ErrNotFound},
+		},
+		{
+			name:      "already exists",
+			err:       awserr.New(rds.ErrCodeDBInstanceAlreadyExistsFault, "exists", nil),
+			kind:      KindInstance,
+			wantClass: AlreadyExists,
+			wantIs:    []error{ErrAlreadyExists},
+		},
+		{
+			name:           "already exists for another kind",
+			err:            awserr.New(rds.ErrCodeDBClusterSnapshotAlreadyExistsFault, "exists", nil),
+			kind:           KindCluster,
+			wantClass:      AlreadyExists,
+			wantDependency: KindClusterSnapshot,
+			wantIs:         []error{ErrAlreadyExists},
+		},
+		{
+			name:      "invalid state",
+			err:       awserr.New(rds.ErrCodeInvalidDBInstanceStateFault, "modifying", nil),
+			kind:      KindInstance,
+			wantClass: InvalidState,
+			wantIs:    []error{ErrInvalidState},
+		},
+		{
+			name:      "invalid VPC network",
+			err:       awserr.New(rds.ErrCodeInvalidVPCNetworkStateFault, "subnets in one availability zone", nil),
+			kind:      KindCluster,
+			wantClass: InvalidRequest,
+			wantIs:    []error{ErrInvalidRequest},
+		},
+		{
+			name:      "quota exceeded",
+			err:       awserr.New(rds.ErrCodeStorageQuotaExceededFault, "quota", nil),
+			kind:      KindCluster,
+			wantClass: QuotaExceeded,
+			wantIs:    []error{ErrQuotaExceeded},
+		},
+		{
+			name:      "throttled",
+			err:       awserr.New("Throttling", "rate exceeded", nil),
+			kind:      KindCluster,
+			wantClass: Throttled,
+			wantIs:    []error{ErrThrottled},
+		},
+		{
+			name: "unknown code",
+			err:  awserr.New("InvalidParameterValue", "bad", nil),
+			kind: KindCluster,
+		},
+		{
+			name: "not an AWS error",
+			err:  errors.New("connection refused"),
+			kind: KindCluster,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := Wrap(c.err, c.kind, "c1")
+
+			if c.wantClass == "" {
+				if err != c.err {
+					t.Errorf("got %v, want the error unchanged", err)
+				}
+				return
+			}
+
+			var rerr *Error
+			if !errors.As(err, &rerr) {
+				t.Fatalf("got %v, want an *Error", err)
+			}
+			if rerr.Class != c.wantClass || rerr.Dependency != c.wantDependency {
+				t.Errorf(
+					"got class %q dependency %q, want class %q dependency %q",
+					rerr.Class, rerr.Dependency, c.wantClass, c.wantDependency,
+				)
+			}
+			if rerr.Kind != c.kind || rerr.Id != "c1" || rerr.Err != c.err {
+				t.Errorf("got %+v, want kind %s, id c1 and the AWS error", rerr, c.kind)
+			}
+			for _, target := range c.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("%v is not %v", err, target)
+				}
+			}
+			if ClassOf(fmt.Errorf("wrapped: %w", err)) != c.wantClass {
+				t.Errorf("class of the wrapped error is not %q", c.wantClass)
+			}
+		})
+	}
+}
+
+func TestIs(t *testing.T) {
+	err := Wrap(awserr.New(rds.ErrCodeDBInstanceNotFoundFault, "not found", nil), KindInstance, "i1")
+
+	cases := []struct {
+		target error
+		want   bool
+	}{
+		{target: ErrNotFound, want: true},
+		{target: New(NotFound, KindInstance, ""), want: true},
+		{target: New(NotFound, KindInstance, "i1"), want: true},
+		{target: New(NotFound, KindInstance, "i2"), want: false},
+		{target: New(NotFound, KindCluster, ""), want: false},
+		{target: ErrInvalidState, want: false},
+	}
+
+	for _, c := range cases {
+		if got := errors.Is(err, c.target); got != c.want {
+			t.Errorf("errors.Is(%v, %v) = %t, want %t", err, c.target, got, c.want)
+		}
+	}
+}
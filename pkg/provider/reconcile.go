@@ -0,0 +1,296 @@
+package provider
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster_parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/retry"
+	"github.com/cvgw/rds_provider/pkg/provider/subnet_group"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	KindSubnetGroup           = rds_errors.KindSubnetGroup
+	KindClusterParameterGroup = rds_errors.KindClusterParameterGroup
+	KindParameterGroup        = rds_errors.KindParameterGroup
+	KindCluster               = rds_errors.KindCluster
+	KindInstance              = rds_errors.KindInstance
+	KindClusterSnapshot       = rds_errors.KindClusterSnapshot
+
+	ActionNone   Action = "none"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	// ActionBlocked marks changes which apply can not make, such as those
+	// which require replacing or deleting a resource
+	ActionBlocked Action = "blocked"
+)
+
+// Action describes what is done to a resource to reach its desired state
+type Action string
+
+// Result records the outcome of reconciling a single resource
+type Result struct {
+	Kind   string
+	Id     string
+	Action Action
+	// Retries holds the failed calls for the resource which were retried
+	Retries []retry.Attempt
+}
+
+// ReconcileStack brings every resource in the stack to its desired state,
+// creating missing resources and updating drifted ones. Independent resources
+// are reconciled in parallel. Nothing is modified when the stack needs changes
+// which can not be made in place, see BlockedChanges.
+func ReconcileStack(svc rds_api.RDSAPI, stack Stack, opts StackOptions) ([]Result, error) {
+	changes, err := PlanStack(svc, stack)
+	if err != nil {
+		return nil, err
+	}
+	err = BlockedChanges(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]Result)
+	record := func(result Result, err error) error {
+		if err != nil {
+			return err
+		}
+
+		result.Retries = retriesFor(svc, result.Kind, result.Id)
+
+		mu.Lock()
+		defer mu.Unlock()
+		results[nodeKey(result.Kind, result.Id)] = result
+		return nil
+	}
+
+	g := newStackGraph(stack, stackOperations{
+		subnetGroup: func(req subnet_group.CreateSubnetGroupRequest) error {
+			return record(ReconcileSubnetGroup(svc, req))
+		},
+		clusterParameterGroup: func(req cluster_parameter_group.CreateRequest) error {
+			return record(ReconcileClusterParameterGroup(svc, req))
+		},
+		parameterGroup: func(req parameter_group.CreateRequest) error {
+			return record(ReconcileParameterGroup(svc, req))
+		},
+		cluster: func(input cluster.NewDBClusterInput) error {
+			result, err := ReconcileCluster(svc, input)
+			if err == nil && opts.Wait {
+				switch result.Action {
+				case ActionCreate:
+					err = cluster.WaitUntilDBClusterAvailable(svc, input.ClusterId, opts.WaitOptions)
+				case ActionUpdate:
+					err = cluster.WaitUntilDBClusterModified(svc, input.ClusterId, opts.WaitOptions)
+				}
+			}
+			return record(result, err)
+		},
+		instance: func(input instance.NewDBInstanceInput) error {
+			result, err := ReconcileInstance(svc, input)
+			if err == nil && opts.Wait {
+				switch result.Action {
+				case ActionCreate:
+					err = instance.WaitUntilDBInstanceAvailable(svc, input.InstanceIdentifier, opts.WaitOptions)
+				case ActionUpdate:
+					err = instance.WaitUntilDBInstanceModified(svc, input.InstanceIdentifier, opts.WaitOptions)
+				}
+			}
+			return record(result, err)
+		},
+	})
+
+	err = g.Execute(opts.Concurrency)
+
+	ordered := make([]Result, 0)
+	for _, key := range g.order {
+		if result, ok := results[key]; ok {
+			ordered = append(ordered, result)
+		}
+	}
+
+	return ordered, err
+}
+
+func ReconcileSubnetGroup(svc rds_api.RDSAPI, req subnet_group.CreateSubnetGroupRequest) (Result, error) {
+	result := Result{Kind: KindSubnetGroup, Id: req.Name, Action: ActionNone}
+
+	group, err := subnet_group.FindDBSubnetGroup(svc, req.Name)
+	if err != nil {
+		if !errors.Is(err, subnet_group.SubnetGroupNotFoundErr) {
+			return result, err
+		}
+
+		_, err = subnet_group.CreateSubnetGroup(svc, req)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionCreate
+		return logResult(result), nil
+	}
+
+	if subnet_group.NeedsUpdate(group, req) {
+		_, err = subnet_group.UpdateSubnetGroup(svc, req)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionUpdate
+	}
+
+	return logResult(result), nil
+}
+
+func ReconcileClusterParameterGroup(svc rds_api.RDSAPI, req cluster_parameter_group.CreateRequest) (Result, error) {
+	result := Result{Kind: KindClusterParameterGroup, Id: req.Name, Action: ActionNone}
+
+	_, err := cluster_parameter_group.FindDBClusterParameterGroup(svc, req.Name)
+	if err != nil {
+		if !errors.Is(err, cluster_parameter_group.NotFoundErr) {
+			return result, err
+		}
+
+		_, err = cluster_parameter_group.CreateDBClusterParameterGroup(svc, req)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionCreate
+		return logResult(result), nil
+	}
+
+	current, err := cluster_parameter_group.FindDBClusterParameters(svc, req.Name)
+	if err != nil {
+		return result, err
+	}
+
+	changed := cluster_parameter_group.ChangedParameters(current, req.Parameters)
+	if len(changed) > 0 {
+		updateReq := cluster_parameter_group.UpdateRequest{}
+		updateReq.SetName(req.Name).SetClusterParameters(changed)
+
+		err = cluster_parameter_group.UpdateDBClusterParameterGroup(svc, updateReq)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionUpdate
+	}
+
+	return logResult(result), nil
+}
+
+func ReconcileParameterGroup(svc rds_api.RDSAPI, req parameter_group.CreateRequest) (Result, error) {
+	result := Result{Kind: KindParameterGroup, Id: req.Name, Action: ActionNone}
+
+	_, err := parameter_group.FindDBParameterGroup(svc, req.Name)
+	if err != nil {
+		if !errors.Is(err, parameter_group.NotFoundErr) {
+			return result, err
+		}
+
+		_, err = parameter_group.CreateDBParameterGroup(svc, req)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionCreate
+		return logResult(result), nil
+	}
+
+	current, err := parameter_group.FindDBParameters(svc, req.Name)
+	if err != nil {
+		return result, err
+	}
+
+	changed := parameter_group.ChangedParameters(current, req.Parameters)
+	if len(changed) > 0 {
+		updateReq := parameter_group.UpdateRequest{}
+		updateReq.SetName(req.Name).SetParameters(changed)
+
+		err = parameter_group.UpdateDBParameterGroup(svc, updateReq)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionUpdate
+	}
+
+	return logResult(result), nil
+}
+
+func ReconcileCluster(svc rds_api.RDSAPI, input cluster.NewDBClusterInput) (Result, error) {
+	result := Result{Kind: KindCluster, Id: input.ClusterId, Action: ActionNone}
+
+	dbCluster, err := cluster.FindDBCluster(svc, input.ClusterId)
+	if err != nil {
+		if !errors.Is(err, cluster.ClusterNotFoundErr) {
+			return result, err
+		}
+
+		_, err = cluster.CreateDBCluster(svc, input)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionCreate
+		return logResult(result), nil
+	}
+
+	updateReq, changed := cluster.NewUpdateDBClusterRequest(dbCluster, input)
+	if changed {
+		_, err = cluster.UpdateDBCluster(svc, updateReq)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionUpdate
+	}
+
+	return logResult(result), nil
+}
+
+func ReconcileInstance(svc rds_api.RDSAPI, input instance.NewDBInstanceInput) (Result, error) {
+	result := Result{Kind: KindInstance, Id: input.InstanceIdentifier, Action: ActionNone}
+
+	dbInstance, err := instance.FindDBClusterInstance(svc, input.InstanceIdentifier)
+	if err != nil {
+		if !errors.Is(err, instance.NotFoundErr) {
+			return result, err
+		}
+
+		_, err = instance.CreateDBClusterInstance(svc, input)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionCreate
+		return logResult(result), nil
+	}
+
+	updateReq, changed := instance.NewUpdateDBInstanceRequest(dbInstance, input)
+	if changed {
+		err = instance.UpdateDBClusterInstance(svc, updateReq)
+		if err != nil {
+			return result, err
+		}
+		result.Action = ActionUpdate
+	}
+
+	return logResult(result), nil
+}
+
+// retriesFor returns the retried calls made for a resource when svc retries
+// failed calls
+func retriesFor(svc rds_api.RDSAPI, kind, id string) []retry.Attempt {
+	if r, ok := svc.(*retry.RDS); ok {
+		return r.Attempts(kind, id)
+	}
+
+	return nil
+}
+
+func logResult(result Result) Result {
+	log.Infof("%s %s: %s", result.Kind, result.Id, result.Action)
+	return result
+}
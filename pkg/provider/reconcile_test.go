@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cvgw/rds_provider/pkg/provider/fake_rds"
+	"github.com/cvgw/rds_provider/pkg/provider/wait"
+)
+
+const sampleStack = "../../samples/stack.yaml"
+
+func loadSampleStack(t *testing.T) Stack {
+	t.Helper()
+
+	stack, err := LoadStack(sampleStack)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return stack
+}
+
+// testStackOptions waits on the fake without delay
+func testStackOptions(w bool) StackOptions {
+	return StackOptions{
+		Wait: w,
+		WaitOptions: wait.Options{
+			Delay:    time.Millisecond,
+			Progress: func(id, status string) {},
+		},
+	}
+}
+
+func actions(results []Result) map[string]Action {
+	a := make(map[string]Action)
+	for _, r := range results {
+		a[nodeKey(r.Kind, r.Id)] = r.Action
+	}
+	return a
+}
+
+func sampleStackActions(action Action) map[string]Action {
+	return map[string]Action{
+		"subnet_group/test-subnet-group":              action,
+		"cluster_parameter_group/test-cluster-params": action,
+		"parameter_group/test-instance-params":        action,
+		"cluster/test-cluster":                        action,
+		"instance/test-cluster-1":                     action,
+		"instance/test-cluster-2":                     action,
+	}
+}
+
+func checkActions(t *testing.T, run string, results []Result, want map[string]Action) {
+	t.Helper()
+
+	got := actions(results)
+	if len(got) != len(want) {
+		t.Errorf("%s: got %d results, want %d: %v", run, len(got), len(want), got)
+	}
+	for key, action := range want {
+		if got[key] != action {
+			t.Errorf("%s: %s: got %q, want %q", run, key, got[key], action)
+		}
+	}
+}
+
+func TestReconcileStackIsIdempotent(t *testing.T) {
+	for _, w := range []bool{false, true} {
+		name := "without waiting"
+		if w {
+			name = "waiting"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			svc := fake_rds.New()
+			stack := loadSampleStack(t)
+			opts := testStackOptions(w)
+
+			results, err := ReconcileStack(svc, stack, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkActions(t, "first run", results, sampleStackActions(ActionCreate))
+
+			results, err = ReconcileStack(svc, stack, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkActions(t, "second run", results, sampleStackActions(ActionNone))
+		})
+	}
+}
+
+func TestReconcileStackUpdates(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(*Stack)
+		want   string
+	}{
+		{
+			name:   "instance class",
+			change: func(s *Stack) { s.Instances[1].InstanceClass = "db.r4.large" },
+			want:   "instance/test-cluster-2",
+		},
+		{
+			name:   "backup retention period",
+			change: func(s *Stack) { s.Cluster.BackupRetentionPeriod = 7 },
+			want:   "cluster/test-cluster",
+		},
+		{
+			name:   "subnets",
+			change: func(s *Stack) { s.SubnetGroup.SubnetIds = append(s.SubnetGroup.SubnetIds, "meow-3") },
+			want:   "subnet_group/test-subnet-group",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := fake_rds.New()
+			stack := loadSampleStack(t)
+			opts := testStackOptions(true)
+
+			_, err := ReconcileStack(svc, stack, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c.change(&stack)
+			want := sampleStackActions(ActionNone)
+			want[c.want] = ActionUpdate
+			results, err := ReconcileStack(svc, stack, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkActions(t, "update", results, want)
+
+			results, err = ReconcileStack(svc, stack, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkActions(t, "after the update", results, sampleStackActions(ActionNone))
+		})
+	}
+}
+
+func TestReconcileStackBlockedChanges(t *testing.T) {
+	cases := []struct {
+		name   string
+		change func(*Stack)
+	}{
+		{
+			name:   "cluster engine",
+			change: func(s *Stack) { s.Cluster.Engine = "aurora-postgresql" },
+		},
+		{
+			name:   "instance removed from the stack",
+			change: func(s *Stack) { s.Instances = s.Instances[:1] },
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			svc := fake_rds.New()
+			stack := loadSampleStack(t)
+			opts := testStackOptions(true)
+
+			_, err := ReconcileStack(svc, stack, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			changed := loadSampleStack(t)
+			c.change(&changed)
+			changed.Instances[0].InstanceClass = "db.r4.large"
+			_, err = ReconcileStack(svc, changed, opts)
+			if !errors.Is(err, BlockedChangeErr) {
+				t.Fatalf("got %v, want %v", err, BlockedChangeErr)
+			}
+
+			// nothing was modified, not even the changes which could be made
+			results, err := ReconcileStack(svc, stack, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkActions(t, "after the blocked run", results, sampleStackActions(ActionNone))
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	log "github.com/sirupsen/logrus"
+)
+
+// RestoreSource selects what a cluster is restored from. Exactly one of
+// SnapshotId and SourceClusterId is set.
+type RestoreSource struct {
+	// Cluster snapshot to restore, by id or, when shared from another account,
+	// by ARN
+	SnapshotId string
+	// Cluster to restore as it was at RestoreTime
+	SourceClusterId string
+	// Time to restore SourceClusterId to, its latest restorable time when zero
+	RestoreTime time.Time
+}
+
+// Validate checks that the source names a single snapshot or cluster
+func (s RestoreSource) Validate() error {
+	switch {
+	case s.SnapshotId == "" && s.SourceClusterId == "":
+		return errors.New("a snapshot or a source cluster to restore from is required")
+	case s.SnapshotId != "" && s.SourceClusterId != "":
+		return errors.New("a cluster can not be restored from both a snapshot and a source cluster")
+	case s.SnapshotId != "" && !s.RestoreTime.IsZero():
+		return errors.New("a restore time can only be given when restoring a source cluster")
+	}
+
+	return nil
+}
+
+// RestoreCluster restores the cluster of the stack from source and then
+// creates the instances of the stack in it. The subnet group and parameter
+// groups the stack refers to must already exist. With opts.Wait the cluster
+// is waited on before its instances are created and the instances are waited
+// on in turn.
+func RestoreCluster(svc rds_api.RDSAPI, source RestoreSource, stack Stack, opts StackOptions) (*rds.DBCluster, error) {
+	err := source.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	input := stack.Cluster
+
+	var c *rds.DBCluster
+	if source.SnapshotId != "" {
+		c, err = cluster.RestoreFromSnapshot(svc, source.SnapshotId, input)
+	} else {
+		c, err = cluster.RestoreToPointInTime(svc, source.SourceClusterId, source.RestoreTime, input)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Wait {
+		err = cluster.WaitUntilDBClusterAvailable(svc, input.ClusterId, opts.WaitOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+	log.Infof("restored cluster %s", input.ClusterId)
+
+	g := NewGraph()
+	for _, instanceInput := range stack.Instances {
+		i := instanceInput
+		i.ClusterIdentifier = input.ClusterId
+		// the engine of a cluster restored from a snapshot may come from the
+		// snapshot rather than the stack
+		i.Engine = aws.StringValue(c.Engine)
+
+		g.AddNode(KindInstance, i.InstanceIdentifier, func() error {
+			var err error
+			if opts.Wait {
+				_, err = instance.CreateDBClusterInstanceAndWait(svc, i, opts.WaitOptions)
+			} else {
+				_, err = instance.CreateDBClusterInstance(svc, i)
+			}
+			if err != nil {
+				return err
+			}
+			log.Infof("created instance %s", i.InstanceIdentifier)
+			return nil
+		})
+	}
+
+	err = g.Execute(opts.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return cluster.FindDBCluster(svc, input.ClusterId)
+}
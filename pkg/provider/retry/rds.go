@@ -0,0 +1,308 @@
+package retry
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+)
+
+var _ rds_api.RDSAPI = (*RDS)(nil)
+
+// RDS wraps an rds_api.RDSAPI, retrying failed calls according to Policy and
+// recording the attempts which were retried. Waiters and request builders are
+// passed through unchanged.
+type RDS struct {
+	rds_api.RDSAPI
+	Policy Policy
+
+	mu       sync.Mutex
+	attempts []Attempt
+}
+
+func New(svc rds_api.RDSAPI, policy Policy) *RDS {
+	return &RDS{RDSAPI: svc, Policy: policy}
+}
+
+// Attempts returns the retried attempts made for a resource
+func (r *RDS) Attempts(kind, id string) []Attempt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := make([]Attempt, 0)
+	for _, a := range r.attempts {
+		if a.Kind == kind && a.Id == id {
+			attempts = append(attempts, a)
+		}
+	}
+
+	return attempts
+}
+
+// AllAttempts returns every retried attempt in the order they were made
+func (r *RDS) AllAttempts() []Attempt {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Attempt{}, r.attempts...)
+}
+
+func (r *RDS) do(operation, kind, id string, fn func() error) error {
+	attempts, err := r.Policy.Do(operation, kind, id, fn)
+
+	r.mu.Lock()
+	r.attempts = append(r.attempts, attempts...)
+	r.mu.Unlock()
+
+	return err
+}
+
+func (r *RDS) CreateDBCluster(input *rds.CreateDBClusterInput) (output *rds.CreateDBClusterOutput, err error) {
+	err = r.do("CreateDBCluster", rds_errors.KindCluster, aws.StringValue(input.DBClusterIdentifier), func() error {
+		output, err = r.RDSAPI.CreateDBCluster(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DescribeDBClusters(input *rds.DescribeDBClustersInput) (output *rds.DescribeDBClustersOutput, err error) {
+	err = r.do("DescribeDBClusters", rds_errors.KindCluster, aws.StringValue(input.DBClusterIdentifier), func() error {
+		output, err = r.RDSAPI.DescribeDBClusters(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) ModifyDBCluster(input *rds.ModifyDBClusterInput) (output *rds.ModifyDBClusterOutput, err error) {
+	err = r.do("ModifyDBCluster", rds_errors.KindCluster, aws.StringValue(input.DBClusterIdentifier), func() error {
+		output, err = r.RDSAPI.ModifyDBCluster(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DeleteDBCluster(input *rds.DeleteDBClusterInput) (output *rds.DeleteDBClusterOutput, err error) {
+	err = r.do("DeleteDBCluster", rds_errors.KindCluster, aws.StringValue(input.DBClusterIdentifier), func() error {
+		output, err = r.RDSAPI.DeleteDBCluster(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) RestoreDBClusterFromSnapshot(input *rds.RestoreDBClusterFromSnapshotInput) (output *rds.RestoreDBClusterFromSnapshotOutput, err error) {
+	err = r.do("RestoreDBClusterFromSnapshot", rds_errors.KindCluster, aws.StringValue(input.DBClusterIdentifier), func() error {
+		output, err = r.RDSAPI.RestoreDBClusterFromSnapshot(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) RestoreDBClusterToPointInTime(input *rds.RestoreDBClusterToPointInTimeInput) (output *rds.RestoreDBClusterToPointInTimeOutput, err error) {
+	err = r.do("RestoreDBClusterToPointInTime", rds_errors.KindCluster, aws.StringValue(input.DBClusterIdentifier), func() error {
+		output, err = r.RDSAPI.RestoreDBClusterToPointInTime(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) CreateDBInstance(input *rds.CreateDBInstanceInput) (output *rds.CreateDBInstanceOutput, err error) {
+	err = r.do("CreateDBInstance", rds_errors.KindInstance, aws.StringValue(input.DBInstanceIdentifier), func() error {
+		output, err = r.RDSAPI.CreateDBInstance(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DescribeDBInstances(input *rds.DescribeDBInstancesInput) (output *rds.DescribeDBInstancesOutput, err error) {
+	err = r.do("DescribeDBInstances", rds_errors.KindInstance, aws.StringValue(input.DBInstanceIdentifier), func() error {
+		output, err = r.RDSAPI.DescribeDBInstances(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) ModifyDBInstance(input *rds.ModifyDBInstanceInput) (output *rds.ModifyDBInstanceOutput, err error) {
+	err = r.do("ModifyDBInstance", rds_errors.KindInstance, aws.StringValue(input.DBInstanceIdentifier), func() error {
+		output, err = r.RDSAPI.ModifyDBInstance(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DeleteDBInstance(input *rds.DeleteDBInstanceInput) (output *rds.DeleteDBInstanceOutput, err error) {
+	err = r.do("DeleteDBInstance", rds_errors.KindInstance, aws.StringValue(input.DBInstanceIdentifier), func() error {
+		output, err = r.RDSAPI.DeleteDBInstance(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) CreateDBSubnetGroup(input *rds.CreateDBSubnetGroupInput) (output *rds.CreateDBSubnetGroupOutput, err error) {
+	err = r.do("CreateDBSubnetGroup", rds_errors.KindSubnetGroup, aws.StringValue(input.DBSubnetGroupName), func() error {
+		output, err = r.RDSAPI.CreateDBSubnetGroup(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DescribeDBSubnetGroups(input *rds.DescribeDBSubnetGroupsInput) (output *rds.DescribeDBSubnetGroupsOutput, err error) {
+	err = r.do("DescribeDBSubnetGroups", rds_errors.KindSubnetGroup, aws.StringValue(input.DBSubnetGroupName), func() error {
+		output, err = r.RDSAPI.DescribeDBSubnetGroups(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) ModifyDBSubnetGroup(input *rds.ModifyDBSubnetGroupInput) (output *rds.ModifyDBSubnetGroupOutput, err error) {
+	err = r.do("ModifyDBSubnetGroup", rds_errors.KindSubnetGroup, aws.StringValue(input.DBSubnetGroupName), func() error {
+		output, err = r.RDSAPI.ModifyDBSubnetGroup(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DeleteDBSubnetGroup(input *rds.DeleteDBSubnetGroupInput) (output *rds.DeleteDBSubnetGroupOutput, err error) {
+	err = r.do("DeleteDBSubnetGroup", rds_errors.KindSubnetGroup, aws.StringValue(input.DBSubnetGroupName), func() error {
+		output, err = r.RDSAPI.DeleteDBSubnetGroup(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) CreateDBParameterGroup(input *rds.CreateDBParameterGroupInput) (output *rds.CreateDBParameterGroupOutput, err error) {
+	err = r.do("CreateDBParameterGroup", rds_errors.KindParameterGroup, aws.StringValue(input.DBParameterGroupName), func() error {
+		output, err = r.RDSAPI.CreateDBParameterGroup(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DescribeDBParameterGroups(input *rds.DescribeDBParameterGroupsInput) (output *rds.DescribeDBParameterGroupsOutput, err error) {
+	err = r.do("DescribeDBParameterGroups", rds_errors.KindParameterGroup, aws.StringValue(input.DBParameterGroupName), func() error {
+		output, err = r.RDSAPI.DescribeDBParameterGroups(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DescribeDBParameters(input *rds.DescribeDBParametersInput) (output *rds.DescribeDBParametersOutput, err error) {
+	err = r.do("DescribeDBParameters", rds_errors.KindParameterGroup, aws.StringValue(input.DBParameterGroupName), func() error {
+		output, err = r.RDSAPI.DescribeDBParameters(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) ModifyDBParameterGroup(input *rds.ModifyDBParameterGroupInput) (output *rds.DBParameterGroupNameMessage, err error) {
+	err = r.do("ModifyDBParameterGroup", rds_errors.KindParameterGroup, aws.StringValue(input.DBParameterGroupName), func() error {
+		output, err = r.RDSAPI.ModifyDBParameterGroup(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DeleteDBParameterGroup(input *rds.DeleteDBParameterGroupInput) (output *rds.DeleteDBParameterGroupOutput, err error) {
+	err = r.do("DeleteDBParameterGroup", rds_errors.KindParameterGroup, aws.StringValue(input.DBParameterGroupName), func() error {
+		output, err = r.RDSAPI.DeleteDBParameterGroup(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) CreateDBClusterParameterGroup(input *rds.CreateDBClusterParameterGroupInput) (output *rds.CreateDBClusterParameterGroupOutput, err error) {
+	err = r.do("CreateDBClusterParameterGroup", rds_errors.KindClusterParameterGroup, aws.StringValue(input.DBClusterParameterGroupName), func() error {
+		output, err = r.RDSAPI.CreateDBClusterParameterGroup(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DescribeDBClusterParameterGroups(input *rds.DescribeDBClusterParameterGroupsInput) (output *rds.DescribeDBClusterParameterGroupsOutput, err error) {
+	err = r.do("DescribeDBClusterParameterGroups", rds_errors.KindClusterParameterGroup, aws.StringValue(input.DBClusterParameterGroupName), func() error {
+		output, err = r.RDSAPI.DescribeDBClusterParameterGroups(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DescribeDBClusterParameters(input *rds.DescribeDBClusterParametersInput) (output *rds.DescribeDBClusterParametersOutput, err error) {
+	err = r.do("DescribeDBClusterParameters", rds_errors.KindClusterParameterGroup, aws.StringValue(input.DBClusterParameterGroupName), func() error {
+		output, err = r.RDSAPI.DescribeDBClusterParameters(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) ModifyDBClusterParameterGroup(input *rds.ModifyDBClusterParameterGroupInput) (output *rds.DBClusterParameterGroupNameMessage, err error) {
+	err = r.do("ModifyDBClusterParameterGroup", rds_errors.KindClusterParameterGroup, aws.StringValue(input.DBClusterParameterGroupName), func() error {
+		output, err = r.RDSAPI.ModifyDBClusterParameterGroup(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DeleteDBClusterParameterGroup(input *rds.DeleteDBClusterParameterGroupInput) (output *rds.DeleteDBClusterParameterGroupOutput, err error) {
+	err = r.do("DeleteDBClusterParameterGroup", rds_errors.KindClusterParameterGroup, aws.StringValue(input.DBClusterParameterGroupName), func() error {
+		output, err = r.RDSAPI.DeleteDBClusterParameterGroup(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) CreateDBClusterSnapshot(input *rds.CreateDBClusterSnapshotInput) (output *rds.CreateDBClusterSnapshotOutput, err error) {
+	err = r.do("CreateDBClusterSnapshot", rds_errors.KindClusterSnapshot, aws.StringValue(input.DBClusterSnapshotIdentifier), func() error {
+		output, err = r.RDSAPI.CreateDBClusterSnapshot(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DescribeDBClusterSnapshots(input *rds.DescribeDBClusterSnapshotsInput) (output *rds.DescribeDBClusterSnapshotsOutput, err error) {
+	err = r.do("DescribeDBClusterSnapshots", rds_errors.KindClusterSnapshot, aws.StringValue(input.DBClusterSnapshotIdentifier), func() error {
+		output, err = r.RDSAPI.DescribeDBClusterSnapshots(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) CopyDBClusterSnapshot(input *rds.CopyDBClusterSnapshotInput) (output *rds.CopyDBClusterSnapshotOutput, err error) {
+	err = r.do("CopyDBClusterSnapshot", rds_errors.KindClusterSnapshot, aws.StringValue(input.TargetDBClusterSnapshotIdentifier), func() error {
+		output, err = r.RDSAPI.CopyDBClusterSnapshot(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DescribeDBClusterSnapshotAttributes(input *rds.DescribeDBClusterSnapshotAttributesInput) (output *rds.DescribeDBClusterSnapshotAttributesOutput, err error) {
+	err = r.do("DescribeDBClusterSnapshotAttributes", rds_errors.KindClusterSnapshot, aws.StringValue(input.DBClusterSnapshotIdentifier), func() error {
+		output, err = r.RDSAPI.DescribeDBClusterSnapshotAttributes(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) ModifyDBClusterSnapshotAttribute(input *rds.ModifyDBClusterSnapshotAttributeInput) (output *rds.ModifyDBClusterSnapshotAttributeOutput, err error) {
+	err = r.do("ModifyDBClusterSnapshotAttribute", rds_errors.KindClusterSnapshot, aws.StringValue(input.DBClusterSnapshotIdentifier), func() error {
+		output, err = r.RDSAPI.ModifyDBClusterSnapshotAttribute(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) DeleteDBClusterSnapshot(input *rds.DeleteDBClusterSnapshotInput) (output *rds.DeleteDBClusterSnapshotOutput, err error) {
+	err = r.do("DeleteDBClusterSnapshot", rds_errors.KindClusterSnapshot, aws.StringValue(input.DBClusterSnapshotIdentifier), func() error {
+		output, err = r.RDSAPI.DeleteDBClusterSnapshot(input)
+		return err
+	})
+	return output, err
+}
+
+func (r *RDS) ListTagsForResource(input *rds.ListTagsForResourceInput) (output *rds.ListTagsForResourceOutput, err error) {
+	err = r.do("ListTagsForResource", "", aws.StringValue(input.ResourceName), func() error {
+		output, err = r.RDSAPI.ListTagsForResource(input)
+		return err
+	})
+	return output, err
+}
@@ -0,0 +1,150 @@
+// Package retry retries RDS calls which fail for transient reasons, such as
+// throttling or a resource which is still being modified, with exponential
+// backoff and jitter.
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// DefaultPolicy retries throttled calls until MaxElapsed and calls failing
+	// on a resource in the wrong state a handful of times
+	DefaultPolicy = Policy{
+		InitialDelay: 2 * time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   2,
+		Jitter:       0.5,
+		MaxElapsed:   10 * time.Minute,
+		Rules: map[rds_errors.Class]Rule{
+			rds_errors.Throttled:    {},
+			rds_errors.InvalidState: {MaxAttempts: 8},
+		},
+	}
+
+	// NoRetry makes every call exactly once
+	NoRetry = Policy{}
+
+	sleep = time.Sleep
+)
+
+// Rule controls retrying of a single class of error
+type Rule struct {
+	// MaxAttempts is the most calls made, including the first. Zero retries
+	// until the policy's MaxElapsed is reached.
+	MaxAttempts int
+}
+
+// Policy decides which failed calls are retried and how long to wait before
+// each retry. The n-th retry waits InitialDelay * Multiplier^(n-1), capped at
+// MaxDelay, of which the Jitter fraction is randomized.
+type Policy struct {
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	Jitter       float64
+	// MaxElapsed bounds the total time spent on a call including retries
+	MaxElapsed time.Duration
+	// Rules holds the error classes which are retried. Errors of any other
+	// class are returned immediately.
+	Rules map[rds_errors.Class]Rule
+}
+
+// Attempt records a failed call which was retried
+type Attempt struct {
+	Operation string
+	Kind      string
+	Id        string
+	// Number is the attempt which failed, starting at 1
+	Number int
+	Err    error
+	// Delay is the time waited before the next attempt
+	Delay time.Duration
+}
+
+// WithMaxElapsed returns a copy of p with MaxElapsed set to d
+func (p Policy) WithMaxElapsed(d time.Duration) Policy {
+	p.MaxElapsed = d
+	return p
+}
+
+// Delay returns the time to wait after the given failed attempt
+func (p Policy) Delay(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay = delay*(1-jitter) + delay*jitter*rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+// Do calls fn until it succeeds, fails with an error the policy does not
+// retry, or the policy gives up. The failed attempts which were retried are
+// returned along with the final error.
+func (p Policy) Do(operation, kind, id string, fn func() error) ([]Attempt, error) {
+	attempts := make([]Attempt, 0)
+	start := time.Now()
+
+	for n := 1; ; n++ {
+		err := fn()
+		if err == nil {
+			return attempts, nil
+		}
+
+		rule, ok := p.Rules[classOf(err)]
+		if !ok {
+			return attempts, err
+		}
+
+		if rule.MaxAttempts > 0 && n >= rule.MaxAttempts {
+			log.Warnf("%s %s %s: giving up after %d attempts", operation, kind, id, n)
+			return attempts, err
+		}
+
+		delay := p.Delay(n)
+		if time.Since(start)+delay > p.MaxElapsed {
+			log.Warnf("%s %s %s: giving up after %s", operation, kind, id, time.Since(start))
+			return attempts, err
+		}
+
+		log.Warnf("%s %s %s: attempt %d failed, retrying in %s: %v", operation, kind, id, n, delay, err)
+		attempts = append(attempts, Attempt{
+			Operation: operation,
+			Kind:      kind,
+			Id:        id,
+			Number:    n,
+			Err:       err,
+			Delay:     delay,
+		})
+		sleep(delay)
+	}
+}
+
+// classOf classifies both wrapped errors and raw AWS errors
+func classOf(err error) rds_errors.Class {
+	if class := rds_errors.ClassOf(err); class != "" {
+		return class
+	}
+
+	if aerr, ok := err.(awserr.Error); ok {
+		return rds_errors.ClassifyCode(aerr.Code())
+	}
+
+	return ""
+}
@@ -0,0 +1,176 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+)
+
+// noSleep replaces sleep, recording the delays instead, until the returned
+// function is called
+func noSleep() (*[]time.Duration, func()) {
+	delays := make([]time.Duration, 0)
+	sleep = func(d time.Duration) {
+		delays = append(delays, d)
+	}
+
+	return &delays, func() { sleep = time.Sleep }
+}
+
+// failing returns a function failing with errs in turn, then succeeding, and
+// the number of times it was called
+func failing(errs ...error) (func() error, *int) {
+	calls := 0
+	return func() error {
+		calls++
+		if calls <= len(errs) {
+			return errs[calls-1]
+		}
+		return nil
+	}, &calls
+}
+
+func repeat(err error, n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+func TestPolicyDo(t *testing.T) {
+	throttled := awserr.New("Throttling", "rate exceeded", nil)
+	invalidState := awserr.New(rds.ErrCodeInvalidDBClusterStateFault, "cluster is modifying", nil)
+	notFound := awserr.New(rds.ErrCodeDBClusterNotFoundFault, "not found", nil)
+	invalidVPC := awserr.New(rds.ErrCodeInvalidVPCNetworkStateFault, "subnets in one availability zone", nil)
+	policy := Policy{
+		InitialDelay: time.Second,
+		MaxDelay:     4 * time.Second,
+		Multiplier:   2,
+		MaxElapsed:   time.Hour,
+		Rules: map[rds_errors.Class]Rule{
+			rds_errors.Throttled:    {},
+			rds_errors.InvalidState: {MaxAttempts: 3},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		policy     Policy
+		errs       []error
+		wantCalls  int
+		wantErr    error
+		wantDelays []time.Duration
+	}{
+		{
+			name:      "success",
+			policy:    policy,
+			wantCalls: 1,
+		},
+		{
+			name:       "throttled then success",
+			policy:     policy,
+			errs:       repeat(throttled, 4),
+			wantCalls:  5,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second},
+		},
+		{
+			name:       "classified throttling",
+			policy:     policy,
+			errs:       []error{rds_errors.Wrap(throttled, rds_errors.KindCluster, "c1")},
+			wantCalls:  2,
+			wantDelays: []time.Duration{time.Second},
+		},
+		{
+			name:       "invalid state until the rule gives up",
+			policy:     policy,
+			errs:       repeat(invalidState, 5),
+			wantCalls:  3,
+			wantErr:    invalidState,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:      "not found is not retried",
+			policy:    policy,
+			errs:      []error{notFound},
+			wantCalls: 1,
+			wantErr:   notFound,
+		},
+		{
+			name:      "invalid VPC network is not retried by default",
+			policy:    DefaultPolicy,
+			errs:      []error{invalidVPC},
+			wantCalls: 1,
+			wantErr:   invalidVPC,
+		},
+		{
+			name:      "unclassified errors are not retried",
+			policy:    policy,
+			errs:      []error{errors.New("connection refused")},
+			wantCalls: 1,
+			wantErr:   errors.New("connection refused"),
+		},
+		{
+			name:       "max elapsed",
+			policy:     policy.WithMaxElapsed(3 * time.Second),
+			errs:       repeat(throttled, 5),
+			wantCalls:  3,
+			wantErr:    throttled,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:      "no retry",
+			policy:    NoRetry,
+			errs:      []error{throttled},
+			wantCalls: 1,
+			wantErr:   throttled,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			delays, restore := noSleep()
+			defer restore()
+			fn, calls := failing(c.errs...)
+
+			attempts, err := c.policy.Do("ModifyDBCluster", rds_errors.KindCluster, "c1", fn)
+			if (err == nil) != (c.wantErr == nil) || (err != nil && err.Error() != c.wantErr.Error()) {
+				t.Errorf("got error %v, want %v", err, c.wantErr)
+			}
+			if *calls != c.wantCalls {
+				t.Errorf("made %d calls, want %d", *calls, c.wantCalls)
+			}
+			if len(attempts) != len(c.wantDelays) {
+				t.Fatalf("got %d retried attempts, want %d", len(attempts), len(c.wantDelays))
+			}
+			for i, a := range attempts {
+				if a.Number != i+1 || a.Delay != c.wantDelays[i] || a.Id != "c1" {
+					t.Errorf("attempt %d: got %+v, want number %d and delay %s", i, a, i+1, c.wantDelays[i])
+				}
+				if (*delays)[i] != a.Delay {
+					t.Errorf("attempt %d: slept %s, want %s", i, (*delays)[i], a.Delay)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyDelayJitter(t *testing.T) {
+	p := Policy{InitialDelay: 10 * time.Second, Multiplier: 2, Jitter: 0.5}
+	noJitter := p
+	noJitter.Jitter = 0
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		max := noJitter.Delay(attempt)
+		for i := 0; i < 100; i++ {
+			delay := p.Delay(attempt)
+			if delay < max/2 || delay > max {
+				t.Fatalf("attempt %d: delay %s is not between %s and %s", attempt, delay, max/2, max)
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+const (
+	// ConfigEnv names the config file to read in place of DefaultConfigFile
+	ConfigEnv = "RDS_PROVIDER_CONFIG"
+	// DefaultConfigFile is read from the home directory when it exists
+	DefaultConfigFile = ".rds_provider.yaml"
+)
+
+// SessionConfig holds the settings used to build an AWS session. Empty and nil
+// fields are left to the next source in ResolveSessionConfig.
+type SessionConfig struct {
+	// Shared config profile to read credentials and settings from
+	Profile string `json:"profile,omitempty"`
+	// AWS region
+	Region string `json:"region,omitempty"`
+	// Endpoint URL overriding the RDS endpoint of the region (optional)
+	Endpoint string `json:"endpoint,omitempty"`
+	// ARN of a role to assume (optional)
+	RoleArn string `json:"role_arn,omitempty"`
+	// External ID passed when assuming RoleArn (optional)
+	ExternalId string `json:"external_id,omitempty"`
+	// Serial number or ARN of the MFA device used when assuming RoleArn. The
+	// token code is read from stdin. (optional)
+	MfaSerial string `json:"mfa_serial,omitempty"`
+	// Lifetime of the assumed role credentials (optional)
+	SessionDuration time.Duration `json:"-"`
+	// Whether to use the keys in AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+	// in place of the profile
+	KeySession *bool `json:"key_session,omitempty"`
+	// Whether to send requests unsigned, e.g. to a local fake_rds server
+	NoSign *bool `json:"no_sign,omitempty"`
+}
+
+// sessionConfigFile is the on disk form of SessionConfig
+type sessionConfigFile struct {
+	SessionConfig
+	SessionDuration string `json:"session_duration,omitempty"`
+}
+
+// Merge returns c with every non empty field of other copied over it. Bool
+// fields set to false in other override true in c.
+func (c SessionConfig) Merge(other SessionConfig) SessionConfig {
+	if other.Profile != "" {
+		c.Profile = other.Profile
+	}
+	if other.Region != "" {
+		c.Region = other.Region
+	}
+	if other.Endpoint != "" {
+		c.Endpoint = other.Endpoint
+	}
+	if other.RoleArn != "" {
+		c.RoleArn = other.RoleArn
+	}
+	if other.ExternalId != "" {
+		c.ExternalId = other.ExternalId
+	}
+	if other.MfaSerial != "" {
+		c.MfaSerial = other.MfaSerial
+	}
+	if other.SessionDuration != 0 {
+		c.SessionDuration = other.SessionDuration
+	}
+	if other.KeySession != nil {
+		c.KeySession = other.KeySession
+	}
+	if other.NoSign != nil {
+		c.NoSign = other.NoSign
+	}
+
+	return c
+}
+
+// LoadSessionConfig reads a YAML or JSON config file
+func LoadSessionConfig(path string) (SessionConfig, error) {
+	fileData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return SessionConfig{}, err
+	}
+
+	file := sessionConfigFile{}
+	err = yaml.Unmarshal(fileData, &file)
+	if err != nil {
+		return SessionConfig{}, fmt.Errorf("%s: %v", path, err)
+	}
+
+	cfg := file.SessionConfig
+	if file.SessionDuration != "" {
+		cfg.SessionDuration, err = time.ParseDuration(file.SessionDuration)
+		if err != nil {
+			return SessionConfig{}, fmt.Errorf("%s: session_duration: %v", path, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// SessionConfigFromEnv reads AWS_PROFILE, AWS_REGION, AWS_ROLE_ARN,
+// AWS_KEY_SESSION and the RDS_PROVIDER_ENDPOINT, RDS_PROVIDER_EXTERNAL_ID,
+// RDS_PROVIDER_MFA_SERIAL, RDS_PROVIDER_SESSION_DURATION and
+// RDS_PROVIDER_NO_SIGN variables
+func SessionConfigFromEnv() (SessionConfig, error) {
+	cfg := SessionConfig{
+		Profile:    os.Getenv("AWS_PROFILE"),
+		Region:     os.Getenv("AWS_REGION"),
+		Endpoint:   os.Getenv(EndpointEnv),
+		RoleArn:    os.Getenv("AWS_ROLE_ARN"),
+		ExternalId: os.Getenv("RDS_PROVIDER_EXTERNAL_ID"),
+		MfaSerial:  os.Getenv("RDS_PROVIDER_MFA_SERIAL"),
+	}
+
+	var err error
+	cfg.KeySession, err = envBool("AWS_KEY_SESSION")
+	if err != nil {
+		return cfg, err
+	}
+	cfg.NoSign, err = envBool("RDS_PROVIDER_NO_SIGN")
+	if err != nil {
+		return cfg, err
+	}
+
+	if v := os.Getenv("RDS_PROVIDER_SESSION_DURATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("RDS_PROVIDER_SESSION_DURATION: %v", err)
+		}
+		cfg.SessionDuration = d
+	}
+
+	return cfg, nil
+}
+
+// envBool parses the bool in the environment variable name, which is nil when
+// the variable is not set
+func envBool(name string) (*bool, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return &b, nil
+}
+
+// ConfigFilePath returns the config file to read: configPath if given, else
+// $RDS_PROVIDER_CONFIG, else ~/.rds_provider.yaml when it exists. An empty path
+// means there is no config file.
+func ConfigFilePath(configPath string) string {
+	if configPath == "" {
+		configPath = os.Getenv(ConfigEnv)
+	}
+
+	if configPath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			path := filepath.Join(home, DefaultConfigFile)
+			if _, err := os.Stat(path); err == nil {
+				configPath = path
+			}
+		}
+	}
+
+	return configPath
+}
+
+// ResolveSessionConfig combines every source of session settings. From
+// highest to lowest precedence these are: flags, environment variables and the
+// config file. The config file is configPath if given, else
+// $RDS_PROVIDER_CONFIG, else ~/.rds_provider.yaml when it exists. Settings
+// left empty are up to the AWS SDK, see NewSessionFromConfig.
+func ResolveSessionConfig(flags SessionConfig, configPath string) (SessionConfig, error) {
+	cfg := SessionConfig{}
+
+	configPath = ConfigFilePath(configPath)
+	if configPath != "" {
+		file, err := LoadSessionConfig(configPath)
+		if err != nil {
+			return cfg, err
+		}
+		cfg = cfg.Merge(file)
+	}
+
+	env, err := SessionConfigFromEnv()
+	if err != nil {
+		return cfg, err
+	}
+
+	return cfg.Merge(env).Merge(flags), nil
+}
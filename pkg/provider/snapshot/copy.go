@@ -0,0 +1,70 @@
+package snapshot
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// CopyRequest describes a copy of a cluster snapshot
+type CopyRequest struct {
+	// Identifier of the snapshot to copy. Snapshots shared from another
+	// account or copied from another region are given by ARN.
+	SourceSnapshotId string `json:"source_snapshot_id,omitempty"`
+	// Identifier for the copy
+	TargetSnapshotId string `json:"target_snapshot_id,omitempty"`
+	// KMS key to encrypt the copy with (optional). Encrypted snapshots are
+	// re-encrypted under this key, which is required when copying a snapshot
+	// shared from another account or from another region. Unencrypted
+	// snapshots can not be encrypted by copying them.
+	KmsKeyId string `json:"kms_key_id,omitempty"`
+	// Whether to copy the tags of the source snapshot (optional)
+	CopyTags bool `json:"copy_tags,omitempty"`
+	// Region the source snapshot is in when copying across regions (optional)
+	SourceRegion string `json:"source_region,omitempty"`
+}
+
+func NewCopyDBClusterSnapshotInput(req CopyRequest) *rds.CopyDBClusterSnapshotInput {
+	input := &rds.CopyDBClusterSnapshotInput{
+		SourceDBClusterSnapshotIdentifier: aws.String(req.SourceSnapshotId),
+		TargetDBClusterSnapshotIdentifier: aws.String(req.TargetSnapshotId),
+	}
+
+	if req.KmsKeyId != "" {
+		input.KmsKeyId = aws.String(req.KmsKeyId)
+	}
+
+	if req.CopyTags {
+		input.CopyTags = aws.Bool(true)
+	}
+
+	if req.SourceRegion != "" {
+		// the SDK signs the pre-signed URL RDS needs for cross region copies
+		input.SourceRegion = aws.String(req.SourceRegion)
+	}
+
+	return input
+}
+
+// CopyDBClusterSnapshot starts copying the snapshot. The copy is usable once
+// it becomes available.
+func CopyDBClusterSnapshot(svc rds_api.RDSAPI, req CopyRequest) (*rds.DBClusterSnapshot, error) {
+	if req.KmsKeyId != "" {
+		log.Infof(
+			"cluster_snapshot %s: copying %s under KMS key %s", req.TargetSnapshotId, req.SourceSnapshotId, req.KmsKeyId,
+		)
+	} else {
+		log.Infof("cluster_snapshot %s: copying %s", req.TargetSnapshotId, req.SourceSnapshotId)
+	}
+
+	result, err := svc.CopyDBClusterSnapshot(NewCopyDBClusterSnapshotInput(req))
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindClusterSnapshot, req.TargetSnapshotId)
+		log.Warn(err)
+		return nil, err
+	}
+
+	return result.DBClusterSnapshot, nil
+}
@@ -0,0 +1,48 @@
+package snapshot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// CreateRequest describes a manual snapshot of a cluster
+type CreateRequest struct {
+	// Identifier for the snapshot
+	SnapshotId string `json:"snapshot_id,omitempty"`
+	// Identifier of the cluster to snapshot
+	ClusterId string `json:"cluster_id,omitempty"`
+}
+
+// SnapshotId returns a snapshot identifier for the cluster unique to the
+// second
+func SnapshotId(clusterId string, now time.Time) string {
+	return fmt.Sprintf("%s-%s", clusterId, now.UTC().Format("20060102-150405"))
+}
+
+func NewCreateDBClusterSnapshotInput(req CreateRequest) *rds.CreateDBClusterSnapshotInput {
+	return &rds.CreateDBClusterSnapshotInput{
+		DBClusterIdentifier:         aws.String(req.ClusterId),
+		DBClusterSnapshotIdentifier: aws.String(req.SnapshotId),
+	}
+}
+
+// CreateDBClusterSnapshot starts a snapshot of the cluster. The snapshot is
+// usable once it becomes available.
+func CreateDBClusterSnapshot(svc rds_api.RDSAPI, req CreateRequest) (*rds.DBClusterSnapshot, error) {
+	log.Infof("cluster_snapshot %s: snapshotting cluster %s", req.SnapshotId, req.ClusterId)
+
+	result, err := svc.CreateDBClusterSnapshot(NewCreateDBClusterSnapshotInput(req))
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindClusterSnapshot, req.SnapshotId)
+		log.Warn(err)
+		return nil, err
+	}
+
+	return result.DBClusterSnapshot, nil
+}
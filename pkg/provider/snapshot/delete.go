@@ -0,0 +1,26 @@
+package snapshot
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// DeleteDBClusterSnapshot deletes a manual snapshot. Automated snapshots are
+// removed by RDS once they fall out of the cluster's backup retention period.
+func DeleteDBClusterSnapshot(svc rds_api.RDSAPI, snapshotId string) error {
+	input := &rds.DeleteDBClusterSnapshotInput{
+		DBClusterSnapshotIdentifier: aws.String(snapshotId),
+	}
+
+	_, err := svc.DeleteDBClusterSnapshot(input)
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindClusterSnapshot, snapshotId)
+		log.Warn(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package snapshot
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/tags"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	TypeManual    = "manual"
+	TypeAutomated = "automated"
+	TypeShared    = "shared"
+	TypePublic    = "public"
+)
+
+// ListRequest narrows the snapshots returned by ListDBClusterSnapshots. Empty
+// fields match every snapshot owned by the account.
+type ListRequest struct {
+	// Engine of the snapshots, e.g. aurora-mysql (optional)
+	Engine string
+	// Identifiers or ARNs of the clusters the snapshots were taken of
+	// (optional)
+	ClusterIds []string
+	// One of TypeManual, TypeAutomated, TypeShared or TypePublic (optional).
+	// Shared and public snapshots are only listed when asked for.
+	SnapshotType string
+	// Tags the snapshots must carry (optional)
+	Tags tags.Selector
+}
+
+// Filters returns the RDS filters matching the request
+func (r ListRequest) Filters() []*rds.Filter {
+	filters := make([]*rds.Filter, 0)
+	if len(r.ClusterIds) > 0 {
+		filters = append(filters, &rds.Filter{
+			Name:   aws.String("db-cluster-id"),
+			Values: aws.StringSlice(r.ClusterIds),
+		})
+	}
+	if r.Engine != "" {
+		filters = append(filters, &rds.Filter{
+			Name:   aws.String("engine"),
+			Values: aws.StringSlice([]string{r.Engine}),
+		})
+	}
+
+	return filters
+}
+
+// NewDescribeDBClusterSnapshotsInput builds the first describe call for req
+func NewDescribeDBClusterSnapshotsInput(req ListRequest) *rds.DescribeDBClusterSnapshotsInput {
+	input := &rds.DescribeDBClusterSnapshotsInput{}
+	if filters := req.Filters(); len(filters) > 0 {
+		input.Filters = filters
+	}
+
+	if req.SnapshotType != "" {
+		input.SnapshotType = aws.String(req.SnapshotType)
+	}
+
+	switch req.SnapshotType {
+	case TypeShared:
+		input.IncludeShared = aws.Bool(true)
+	case TypePublic:
+		input.IncludePublic = aws.Bool(true)
+	}
+
+	return input
+}
+
+// ListDBClusterSnapshots returns the cluster snapshots in the region matching
+// req, following every page of results
+func ListDBClusterSnapshots(svc rds_api.RDSAPI, req ListRequest) ([]*rds.DBClusterSnapshot, error) {
+	input := NewDescribeDBClusterSnapshotsInput(req)
+
+	snapshots := make([]*rds.DBClusterSnapshot, 0)
+	for {
+		result, err := svc.DescribeDBClusterSnapshots(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindClusterSnapshot, "")
+			log.Warn(err)
+			return nil, err
+		}
+
+		for _, s := range result.DBClusterSnapshots {
+			ok, err := req.Tags.Select(
+				svc, rds_errors.KindClusterSnapshot,
+				aws.StringValue(s.DBClusterSnapshotIdentifier), aws.StringValue(s.DBClusterSnapshotArn),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				snapshots = append(snapshots, s)
+			}
+		}
+
+		if aws.StringValue(result.Marker) == "" {
+			break
+		}
+		input.Marker = result.Marker
+	}
+
+	return snapshots, nil
+}
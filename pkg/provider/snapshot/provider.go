@@ -0,0 +1,49 @@
+// Package snapshot manages manual snapshots of RDS clusters: taking them,
+// copying them, optionally under a different KMS key, sharing them with other
+// accounts and deleting them.
+package snapshot
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	SnapshotNotFoundErr error
+)
+
+func init() {
+	SnapshotNotFoundErr = rds_errors.New(rds_errors.NotFound, rds_errors.KindClusterSnapshot, "")
+}
+
+// FindDBClusterSnapshot returns the snapshot identified by an id, or an ARN
+// for snapshots shared from other accounts
+func FindDBClusterSnapshot(svc rds_api.RDSAPI, snapshotId string) (*rds.DBClusterSnapshot, error) {
+	input := &rds.DescribeDBClusterSnapshotsInput{
+		DBClusterSnapshotIdentifier: aws.String(snapshotId),
+	}
+
+	// snapshots shared with the account are only described when asked for
+	if strings.HasPrefix(snapshotId, "arn:") {
+		input.IncludeShared = aws.Bool(true)
+	}
+
+	result, err := svc.DescribeDBClusterSnapshots(input)
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindClusterSnapshot, snapshotId)
+		if errors.Is(err, SnapshotNotFoundErr) {
+			log.Info(err)
+		} else {
+			log.Warn(err)
+		}
+		return nil, err
+	}
+
+	return result.DBClusterSnapshots[0], nil
+}
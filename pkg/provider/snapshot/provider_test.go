@@ -0,0 +1,50 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+)
+
+// describeRecorder records the describe calls made to it
+type describeRecorder struct {
+	rds_api.RDSAPI
+	inputs []*rds.DescribeDBClusterSnapshotsInput
+}
+
+func (r *describeRecorder) DescribeDBClusterSnapshots(input *rds.DescribeDBClusterSnapshotsInput) (
+	*rds.DescribeDBClusterSnapshotsOutput, error,
+) {
+	r.inputs = append(r.inputs, input)
+	return &rds.DescribeDBClusterSnapshotsOutput{
+		DBClusterSnapshots: []*rds.DBClusterSnapshot{
+			{DBClusterSnapshotIdentifier: input.DBClusterSnapshotIdentifier},
+		},
+	}, nil
+}
+
+func TestFindDBClusterSnapshotIncludesShared(t *testing.T) {
+	cases := []struct {
+		snapshotId string
+		wantShared bool
+	}{
+		{snapshotId: "s1"},
+		{snapshotId: "arn:aws:rds:us-west-2:123456789012:cluster-snapshot:s1", wantShared: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.snapshotId, func(t *testing.T) {
+			svc := &describeRecorder{}
+
+			_, err := FindDBClusterSnapshot(svc, c.snapshotId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if shared := aws.BoolValue(svc.inputs[0].IncludeShared); shared != c.wantShared {
+				t.Errorf("got IncludeShared %t, want %t", shared, c.wantShared)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+const (
+	ActionKeep    = "keep"
+	ActionDelete  = "delete"
+	ActionDeleted = "deleted"
+
+	statusAvailable = "available"
+)
+
+// RetentionPolicy selects the manual snapshots of a cluster to keep. A
+// snapshot is kept when any rule keeps it. Days and weeks are calendar days
+// and ISO weeks in UTC, counted back from and including the current one.
+type RetentionPolicy struct {
+	// Number of most recent snapshots to keep (optional)
+	KeepLast int `json:"keep_last,omitempty"`
+	// Number of days for which the newest snapshot of each day is kept
+	// (optional)
+	KeepDaily int `json:"keep_daily,omitempty"`
+	// Number of weeks for which the newest snapshot of each week is kept
+	// (optional)
+	KeepWeekly int `json:"keep_weekly,omitempty"`
+}
+
+// Decision records whether a snapshot is kept by a policy and why
+type Decision struct {
+	SnapshotId string    `json:"snapshot_id"`
+	ClusterId  string    `json:"cluster_id"`
+	Created    time.Time `json:"created"`
+	// One of ActionKeep, ActionDelete or, once deleted, ActionDeleted
+	Action string `json:"action"`
+	// Rules keeping the snapshot
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Validate refuses negative counts and policies keeping nothing, which would
+// delete every snapshot
+func (p RetentionPolicy) Validate() error {
+	if p.KeepLast < 0 || p.KeepDaily < 0 || p.KeepWeekly < 0 {
+		return errors.New("retention counts can not be negative")
+	}
+
+	if p.KeepLast == 0 && p.KeepDaily == 0 && p.KeepWeekly == 0 {
+		return errors.New("a retention policy must keep the last, daily or weekly snapshots")
+	}
+
+	return nil
+}
+
+// Apply decides which snapshots to keep. The policy applies to the snapshots
+// of each cluster separately. Snapshots which are not available, such as
+// those still being created, are always kept. Decisions are ordered by
+// cluster, newest snapshot first.
+func (p RetentionPolicy) Apply(snapshots []*rds.DBClusterSnapshot, now time.Time) []Decision {
+	byCluster := make(map[string][]*rds.DBClusterSnapshot)
+	for _, s := range snapshots {
+		clusterId := aws.StringValue(s.DBClusterIdentifier)
+		byCluster[clusterId] = append(byCluster[clusterId], s)
+	}
+
+	clusterIds := make([]string, 0, len(byCluster))
+	for clusterId := range byCluster {
+		clusterIds = append(clusterIds, clusterId)
+	}
+	sort.Strings(clusterIds)
+
+	decisions := make([]Decision, 0, len(snapshots))
+	for _, clusterId := range clusterIds {
+		decisions = append(decisions, p.applyCluster(byCluster[clusterId], now)...)
+	}
+
+	return decisions
+}
+
+func (p RetentionPolicy) applyCluster(snapshots []*rds.DBClusterSnapshot, now time.Time) []Decision {
+	sort.Slice(snapshots, func(i, j int) bool {
+		ti, tj := aws.TimeValue(snapshots[i].SnapshotCreateTime), aws.TimeValue(snapshots[j].SnapshotCreateTime)
+		if ti.Equal(tj) {
+			return aws.StringValue(snapshots[i].DBClusterSnapshotIdentifier) >
+				aws.StringValue(snapshots[j].DBClusterSnapshotIdentifier)
+		}
+		return ti.After(tj)
+	})
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	dailyStart := today.AddDate(0, 0, -(p.KeepDaily - 1))
+	weeklyStart := startOfWeek(today).AddDate(0, 0, -7*(p.KeepWeekly-1))
+
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	kept := 0
+
+	decisions := make([]Decision, 0, len(snapshots))
+	for _, s := range snapshots {
+		created := aws.TimeValue(s.SnapshotCreateTime).UTC()
+		d := Decision{
+			SnapshotId: aws.StringValue(s.DBClusterSnapshotIdentifier),
+			ClusterId:  aws.StringValue(s.DBClusterIdentifier),
+			Created:    created,
+			Action:     ActionDelete,
+		}
+
+		if status := aws.StringValue(s.Status); status != statusAvailable {
+			d.Action = ActionKeep
+			d.Reasons = append(d.Reasons, status)
+			decisions = append(decisions, d)
+			continue
+		}
+
+		if kept < p.KeepLast {
+			d.Reasons = append(d.Reasons, fmt.Sprintf("last %d", p.KeepLast))
+		}
+		kept++
+
+		day := created.Format("2006-01-02")
+		if p.KeepDaily > 0 && !created.Before(dailyStart) && !days[day] {
+			days[day] = true
+			d.Reasons = append(d.Reasons, "daily "+day)
+		}
+
+		year, w := created.ISOWeek()
+		week := fmt.Sprintf("%d-W%02d", year, w)
+		if p.KeepWeekly > 0 && !created.Before(weeklyStart) && !weeks[week] {
+			weeks[week] = true
+			d.Reasons = append(d.Reasons, "weekly "+week)
+		}
+
+		if len(d.Reasons) > 0 {
+			d.Action = ActionKeep
+		}
+		decisions = append(decisions, d)
+	}
+
+	return decisions
+}
+
+// startOfWeek returns the Monday starting the ISO week of day
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,150 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+// now is a Thursday, ISO week 2019-W11 started on Monday 2019-03-11
+var now = time.Date(2019, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+func clusterSnapshot(clusterId, id, created, status string) *rds.DBClusterSnapshot {
+	t, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		panic(err)
+	}
+
+	return &rds.DBClusterSnapshot{
+		DBClusterIdentifier:         aws.String(clusterId),
+		DBClusterSnapshotIdentifier: aws.String(id),
+		SnapshotCreateTime:          aws.Time(t),
+		Status:                      aws.String(status),
+	}
+}
+
+func testSnapshots() []*rds.DBClusterSnapshot {
+	return []*rds.DBClusterSnapshot{
+		clusterSnapshot("c1", "c1-mar14-b", "2019-03-14T10:00:00Z", "available"),
+		clusterSnapshot("c1", "c1-mar14-a", "2019-03-14T02:00:00Z", "available"),
+		clusterSnapshot("c1", "c1-mar13", "2019-03-13T02:00:00Z", "available"),
+		clusterSnapshot("c1", "c1-mar11", "2019-03-11T02:00:00Z", "available"),
+		clusterSnapshot("c1", "c1-mar10", "2019-03-10T23:00:00Z", "available"),
+		clusterSnapshot("c1", "c1-mar04", "2019-03-04T02:00:00Z", "available"),
+		clusterSnapshot("c1", "c1-feb20", "2019-02-20T02:00:00Z", "available"),
+		clusterSnapshot("c1", "c1-creating", "2019-03-14T11:00:00Z", "creating"),
+		clusterSnapshot("c2", "c2-mar01", "2019-03-01T02:00:00Z", "available"),
+		clusterSnapshot("c2", "c2-feb01", "2019-02-01T02:00:00Z", "available"),
+	}
+}
+
+func TestRetentionPolicyApply(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy RetentionPolicy
+		// snapshots kept, newest first by cluster
+		wantKept []string
+	}{
+		{
+			name:     "keep last",
+			policy:   RetentionPolicy{KeepLast: 2},
+			wantKept: []string{"c1-creating", "c1-mar14-b", "c1-mar14-a", "c2-mar01", "c2-feb01"},
+		},
+		{
+			name:     "keep daily",
+			policy:   RetentionPolicy{KeepDaily: 4},
+			wantKept: []string{"c1-creating", "c1-mar14-b", "c1-mar13", "c1-mar11"},
+		},
+		{
+			name:     "keep daily counts from today",
+			policy:   RetentionPolicy{KeepDaily: 1},
+			wantKept: []string{"c1-creating", "c1-mar14-b"},
+		},
+		{
+			name:     "keep weekly",
+			policy:   RetentionPolicy{KeepWeekly: 2},
+			wantKept: []string{"c1-creating", "c1-mar14-b", "c1-mar10"},
+		},
+		{
+			// c1-mar04 is in the week of c1-mar10, no c1 snapshot is in the
+			// week of c2-mar01
+			name:     "keep weekly spanning more weeks",
+			policy:   RetentionPolicy{KeepWeekly: 4},
+			wantKept: []string{"c1-creating", "c1-mar14-b", "c1-mar10", "c1-feb20", "c2-mar01"},
+		},
+		{
+			name:   "rules combine",
+			policy: RetentionPolicy{KeepLast: 1, KeepDaily: 2, KeepWeekly: 2},
+			wantKept: []string{
+				"c1-creating", "c1-mar14-b", "c1-mar13", "c1-mar10", "c2-mar01",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			decisions := c.policy.Apply(testSnapshots(), now)
+			if len(decisions) != len(testSnapshots()) {
+				t.Fatalf("got %d decisions, want %d", len(decisions), len(testSnapshots()))
+			}
+
+			kept := make([]string, 0)
+			for _, d := range decisions {
+				switch d.Action {
+				case ActionKeep:
+					if len(d.Reasons) == 0 {
+						t.Errorf("%s is kept without a reason", d.SnapshotId)
+					}
+					kept = append(kept, d.SnapshotId)
+				case ActionDelete:
+					if len(d.Reasons) > 0 {
+						t.Errorf("%s is deleted with reasons %q", d.SnapshotId, d.Reasons)
+					}
+				default:
+					t.Errorf("%s: unexpected action %q", d.SnapshotId, d.Action)
+				}
+			}
+			if !reflect.DeepEqual(kept, c.wantKept) {
+				t.Errorf("kept %q, want %q", kept, c.wantKept)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicyReasons(t *testing.T) {
+	policy := RetentionPolicy{KeepLast: 1, KeepDaily: 1, KeepWeekly: 1}
+	decisions := policy.Apply(testSnapshots(), now)
+
+	want := map[string][]string{
+		"c1-creating": {"creating"},
+		"c1-mar14-b":  {"last 1", "daily 2019-03-14", "weekly 2019-W11"},
+		"c2-mar01":    {"last 1"},
+	}
+	for _, d := range decisions {
+		if !reflect.DeepEqual(d.Reasons, want[d.SnapshotId]) {
+			t.Errorf("%s: got reasons %q, want %q", d.SnapshotId, d.Reasons, want[d.SnapshotId])
+		}
+	}
+}
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	cases := []struct {
+		policy  RetentionPolicy
+		wantErr bool
+	}{
+		{policy: RetentionPolicy{KeepLast: 1}},
+		{policy: RetentionPolicy{KeepDaily: 7, KeepWeekly: 4}},
+		{policy: RetentionPolicy{}, wantErr: true},
+		{policy: RetentionPolicy{KeepLast: -1, KeepDaily: 1}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		err := c.policy.Validate()
+		if (err != nil) != c.wantErr {
+			t.Errorf("%+v: got %v, want an error %t", c.policy, err, c.wantErr)
+		}
+	}
+}
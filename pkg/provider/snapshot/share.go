@@ -0,0 +1,117 @@
+package snapshot
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// AllAccounts shares a snapshot publicly. Encrypted snapshots can not be
+	// shared publicly.
+	AllAccounts = "all"
+
+	// attributeRestore is the snapshot attribute listing the accounts allowed
+	// to copy and restore it
+	attributeRestore = "restore"
+)
+
+var accountIdPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+// ShareRequest changes the accounts a manual snapshot is shared with
+type ShareRequest struct {
+	// Identifier of the snapshot
+	SnapshotId string `json:"snapshot_id,omitempty"`
+	// AWS account ids to share the snapshot with, or AllAccounts (optional)
+	AddAccounts []string `json:"add_accounts,omitempty"`
+	// AWS account ids to stop sharing the snapshot with, or AllAccounts
+	// (optional)
+	RemoveAccounts []string `json:"remove_accounts,omitempty"`
+}
+
+// Validate checks that every account is an account id or AllAccounts
+func (r ShareRequest) Validate() error {
+	for _, account := range append(append([]string{}, r.AddAccounts...), r.RemoveAccounts...) {
+		if account != AllAccounts && !accountIdPattern.MatchString(account) {
+			return fmt.Errorf("invalid account %q, expected a 12 digit account id or %q", account, AllAccounts)
+		}
+	}
+
+	return nil
+}
+
+func NewModifyDBClusterSnapshotAttributeInput(req ShareRequest) *rds.ModifyDBClusterSnapshotAttributeInput {
+	input := &rds.ModifyDBClusterSnapshotAttributeInput{
+		AttributeName:               aws.String(attributeRestore),
+		DBClusterSnapshotIdentifier: aws.String(req.SnapshotId),
+	}
+
+	if len(req.AddAccounts) > 0 {
+		input.ValuesToAdd = aws.StringSlice(req.AddAccounts)
+	}
+
+	if len(req.RemoveAccounts) > 0 {
+		input.ValuesToRemove = aws.StringSlice(req.RemoveAccounts)
+	}
+
+	return input
+}
+
+// ShareDBClusterSnapshot adds and removes accounts allowed to copy and restore
+// the snapshot and returns the accounts it is then shared with. Sharing an
+// encrypted snapshot also requires granting the accounts use of its KMS key,
+// which can not be the default RDS key.
+func ShareDBClusterSnapshot(svc rds_api.RDSAPI, req ShareRequest) ([]string, error) {
+	err := req.Validate()
+	if err != nil {
+		log.Warn(err)
+		return nil, err
+	}
+
+	if len(req.AddAccounts) == 0 && len(req.RemoveAccounts) == 0 {
+		return SharedAccounts(svc, req.SnapshotId)
+	}
+
+	result, err := svc.ModifyDBClusterSnapshotAttribute(NewModifyDBClusterSnapshotAttributeInput(req))
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindClusterSnapshot, req.SnapshotId)
+		log.Warn(err)
+		return nil, err
+	}
+
+	return restoreAccounts(result.DBClusterSnapshotAttributesResult), nil
+}
+
+// SharedAccounts returns the accounts the snapshot is shared with
+func SharedAccounts(svc rds_api.RDSAPI, snapshotId string) ([]string, error) {
+	result, err := svc.DescribeDBClusterSnapshotAttributes(&rds.DescribeDBClusterSnapshotAttributesInput{
+		DBClusterSnapshotIdentifier: aws.String(snapshotId),
+	})
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindClusterSnapshot, snapshotId)
+		log.Warn(err)
+		return nil, err
+	}
+
+	return restoreAccounts(result.DBClusterSnapshotAttributesResult), nil
+}
+
+func restoreAccounts(result *rds.DBClusterSnapshotAttributesResult) []string {
+	accounts := make([]string, 0)
+	if result == nil {
+		return accounts
+	}
+
+	for _, attr := range result.DBClusterSnapshotAttributes {
+		if aws.StringValue(attr.AttributeName) == attributeRestore {
+			accounts = append(accounts, aws.StringValueSlice(attr.AttributeValues)...)
+		}
+	}
+
+	return accounts
+}
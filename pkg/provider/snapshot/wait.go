@@ -0,0 +1,136 @@
+package snapshot
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/wait"
+)
+
+const (
+	snapshotStatusPath = "DBClusterSnapshots[].Status"
+)
+
+// WaitUntilDBClusterSnapshotAvailable blocks until the snapshot reports the
+// available status
+func WaitUntilDBClusterSnapshotAvailable(svc rds_api.RDSAPI, snapshotId string, opts wait.Options) error {
+	ctx, cancel := opts.Context()
+	defer cancel()
+
+	w := newSnapshotWaiter(ctx, svc, "WaitUntilDBClusterSnapshotAvailable", snapshotId, []request.WaiterAcceptor{
+		{
+			State:   request.SuccessWaiterState,
+			Matcher: request.PathAllWaiterMatch, Argument: snapshotStatusPath,
+			Expected: "available",
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: snapshotStatusPath,
+			Expected: "deleting",
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: snapshotStatusPath,
+			Expected: "failed",
+		},
+		{
+			State:    request.FailureWaiterState,
+			Matcher:  request.ErrorWaiterMatch,
+			Expected: rds.ErrCodeDBClusterSnapshotNotFoundFault,
+		},
+	})
+	w.ApplyOptions(opts.WaiterOptions(snapshotId, snapshotStatusPath)...)
+
+	return w.WaitWithContext(ctx)
+}
+
+// WaitUntilDBClusterSnapshotDeleted blocks until the snapshot no longer exists
+func WaitUntilDBClusterSnapshotDeleted(svc rds_api.RDSAPI, snapshotId string, opts wait.Options) error {
+	ctx, cancel := opts.Context()
+	defer cancel()
+
+	w := newSnapshotWaiter(ctx, svc, "WaitUntilDBClusterSnapshotDeleted", snapshotId, []request.WaiterAcceptor{
+		{
+			State:    request.SuccessWaiterState,
+			Matcher:  request.ErrorWaiterMatch,
+			Expected: rds.ErrCodeDBClusterSnapshotNotFoundFault,
+		},
+		{
+			State:   request.FailureWaiterState,
+			Matcher: request.PathAnyWaiterMatch, Argument: snapshotStatusPath,
+			Expected: "creating",
+		},
+	})
+	w.ApplyOptions(opts.WaiterOptions(snapshotId, snapshotStatusPath)...)
+
+	return w.WaitWithContext(ctx)
+}
+
+func newSnapshotWaiter(
+	ctx aws.Context, svc rds_api.RDSAPI, name, snapshotId string, acceptors []request.WaiterAcceptor,
+) request.Waiter {
+	return request.Waiter{
+		Name:        name,
+		MaxAttempts: 60,
+		Delay:       request.ConstantWaiterDelay(30 * time.Second),
+		Acceptors:   acceptors,
+		NewRequest: func(opts []request.Option) (*request.Request, error) {
+			req, _ := svc.DescribeDBClusterSnapshotsRequest(&rds.DescribeDBClusterSnapshotsInput{
+				DBClusterSnapshotIdentifier: aws.String(snapshotId),
+			})
+			req.SetContext(ctx)
+			req.ApplyOptions(opts...)
+			return req, nil
+		},
+	}
+}
+
+// CreateDBClusterSnapshotAndWait snapshots the cluster and blocks until the
+// snapshot is available
+func CreateDBClusterSnapshotAndWait(svc rds_api.RDSAPI, req CreateRequest, opts wait.Options) (
+	*rds.DBClusterSnapshot, error,
+) {
+	_, err := CreateDBClusterSnapshot(svc, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = WaitUntilDBClusterSnapshotAvailable(svc, req.SnapshotId, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return FindDBClusterSnapshot(svc, req.SnapshotId)
+}
+
+// CopyDBClusterSnapshotAndWait copies the snapshot and blocks until the copy
+// is available
+func CopyDBClusterSnapshotAndWait(svc rds_api.RDSAPI, req CopyRequest, opts wait.Options) (
+	*rds.DBClusterSnapshot, error,
+) {
+	_, err := CopyDBClusterSnapshot(svc, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = WaitUntilDBClusterSnapshotAvailable(svc, req.TargetSnapshotId, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return FindDBClusterSnapshot(svc, req.TargetSnapshotId)
+}
+
+// DeleteDBClusterSnapshotAndWait deletes the snapshot and blocks until it no
+// longer exists
+func DeleteDBClusterSnapshotAndWait(svc rds_api.RDSAPI, snapshotId string, opts wait.Options) error {
+	err := DeleteDBClusterSnapshot(svc, snapshotId)
+	if err != nil {
+		return err
+	}
+
+	return WaitUntilDBClusterSnapshotDeleted(svc, snapshotId, opts)
+}
@@ -0,0 +1,372 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/cluster_parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/guard"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/subnet_group"
+	"github.com/cvgw/rds_provider/pkg/provider/wait"
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+)
+
+// Stack describes every resource needed to stand up an RDS cluster
+type Stack struct {
+	// Subnet Group the cluster is deployed into
+	SubnetGroup subnet_group.CreateSubnetGroupRequest `json:"subnet_group,omitempty"`
+	// Cluster Parameter Group to attach to the cluster (optional)
+	ClusterParameterGroup cluster_parameter_group.CreateRequest `json:"cluster_parameter_group,omitempty"`
+	// Instance Parameter Group to attach to each instance (optional)
+	ParameterGroup parameter_group.CreateRequest `json:"parameter_group,omitempty"`
+	// The RDS cluster
+	Cluster cluster.NewDBClusterInput `json:"cluster,omitempty"`
+	// Instances to add to the RDS cluster
+	Instances []instance.NewDBInstanceInput `json:"instances,omitempty"`
+}
+
+// LoadStack reads a YAML or JSON stack document from path
+func LoadStack(path string) (Stack, error) {
+	stack := Stack{}
+
+	fileData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return stack, err
+	}
+
+	err = yaml.Unmarshal(fileData, &stack)
+	if err != nil {
+		return stack, err
+	}
+
+	err = stack.validate()
+	if err != nil {
+		return stack, fmt.Errorf("%s: %w", path, err)
+	}
+
+	stack.resolveReferences()
+	return stack, nil
+}
+
+// validate checks the parts of the stack document which RDS would otherwise
+// reject halfway through applying it
+func (s Stack) validate() error {
+	err := s.ClusterParameterGroup.ValidateParameters()
+	if err != nil {
+		return err
+	}
+
+	return s.ParameterGroup.ValidateParameters()
+}
+
+// resolveReferences fills in references between resources which were left
+// empty in the stack document
+func (s *Stack) resolveReferences() {
+	if s.Cluster.SubnetGroupName == "" {
+		s.Cluster.SubnetGroupName = s.SubnetGroup.Name
+	}
+
+	if s.Cluster.ParameterGroupName == "" {
+		s.Cluster.ParameterGroupName = s.ClusterParameterGroup.Name
+	}
+
+	for i := range s.Instances {
+		if s.Instances[i].ClusterIdentifier == "" {
+			s.Instances[i].ClusterIdentifier = s.Cluster.ClusterId
+		}
+
+		if s.Instances[i].Engine == "" {
+			s.Instances[i].Engine = s.Cluster.Engine
+		}
+
+		if s.Instances[i].ParameterGroupName == "" {
+			s.Instances[i].ParameterGroupName = s.ParameterGroup.Name
+		}
+	}
+}
+
+// StackOptions controls how operations on a Stack are run
+type StackOptions struct {
+	// Maximum number of resources operated on at once (optional)
+	Concurrency int
+	// Whether to block until each cluster and instance reaches its target status
+	Wait bool
+	// How long and how often to check the status of each cluster and instance
+	WaitOptions wait.Options
+	// Final snapshot settings of the cluster used by DeleteStack. The
+	// instances of the cluster have no final snapshot of their own.
+	ClusterDeleteOptions cluster.DeleteOptions
+	// Whether DeleteStack keeps the automated backups of the instances
+	RetainAutomatedBackups bool
+	// Checks made by DeleteStack before anything is deleted (optional)
+	Guard *guard.Guard
+}
+
+// stackOperations holds the operation run for each kind of resource in a Stack
+type stackOperations struct {
+	subnetGroup           func(subnet_group.CreateSubnetGroupRequest) error
+	clusterParameterGroup func(cluster_parameter_group.CreateRequest) error
+	parameterGroup        func(parameter_group.CreateRequest) error
+	cluster               func(cluster.NewDBClusterInput) error
+	instance              func(instance.NewDBInstanceInput) error
+	// settle is set as the Settle function of every node when given
+	settle func(kind, id string) error
+}
+
+// newStackGraph builds a Graph running ops against every resource in the
+// stack. Dependencies are derived from the references between resources.
+func newStackGraph(stack Stack, ops stackOperations) *Graph {
+	g := NewGraph()
+
+	subnetGroup := stack.SubnetGroup
+	g.AddNode(KindSubnetGroup, subnetGroup.Name, func() error {
+		return ops.subnetGroup(subnetGroup)
+	})
+
+	if stack.ClusterParameterGroup.Name != "" {
+		paramGroup := stack.ClusterParameterGroup
+		g.AddNode(KindClusterParameterGroup, paramGroup.Name, func() error {
+			return ops.clusterParameterGroup(paramGroup)
+		})
+	}
+
+	if stack.ParameterGroup.Name != "" {
+		paramGroup := stack.ParameterGroup
+		g.AddNode(KindParameterGroup, paramGroup.Name, func() error {
+			return ops.parameterGroup(paramGroup)
+		})
+	}
+
+	dbCluster := stack.Cluster
+	g.AddNode(KindCluster, dbCluster.ClusterId, func() error {
+		return ops.cluster(dbCluster)
+	})
+	g.AddDependency(KindCluster, dbCluster.ClusterId, KindSubnetGroup, dbCluster.SubnetGroupName)
+	g.AddDependency(
+		KindCluster, dbCluster.ClusterId, KindClusterParameterGroup, dbCluster.ParameterGroupName,
+	)
+
+	for _, i := range stack.Instances {
+		dbInstance := i
+		g.AddNode(KindInstance, dbInstance.InstanceIdentifier, func() error {
+			return ops.instance(dbInstance)
+		})
+		g.AddDependency(
+			KindInstance, dbInstance.InstanceIdentifier, KindCluster, dbInstance.ClusterIdentifier,
+		)
+		g.AddDependency(
+			KindInstance, dbInstance.InstanceIdentifier, KindParameterGroup, dbInstance.ParameterGroupName,
+		)
+	}
+
+	if ops.settle != nil {
+		for _, n := range g.nodes {
+			kind, id := n.Kind, n.Id
+			n.Settle = func() error {
+				return ops.settle(kind, id)
+			}
+		}
+	}
+
+	return g
+}
+
+// CreateStack creates every resource in the stack in dependency order. Resources
+// which do not depend on each other are created in parallel.
+func CreateStack(svc rds_api.RDSAPI, stack Stack, opts StackOptions) error {
+	g := newStackGraph(stack, stackOperations{
+		subnetGroup: func(req subnet_group.CreateSubnetGroupRequest) error {
+			group, err := subnet_group.CreateSubnetGroup(svc, req)
+			if err != nil {
+				return err
+			}
+			log.Infof("created subnet group %s", *group.DBSubnetGroupName)
+			return nil
+		},
+		clusterParameterGroup: func(req cluster_parameter_group.CreateRequest) error {
+			group, err := cluster_parameter_group.CreateDBClusterParameterGroup(svc, req)
+			if err != nil {
+				return err
+			}
+			log.Infof("created cluster parameter group %s", *group.DBClusterParameterGroupName)
+			return nil
+		},
+		parameterGroup: func(req parameter_group.CreateRequest) error {
+			group, err := parameter_group.CreateDBParameterGroup(svc, req)
+			if err != nil {
+				return err
+			}
+			log.Infof("created parameter group %s", *group.DBParameterGroupName)
+			return nil
+		},
+		cluster: func(input cluster.NewDBClusterInput) error {
+			var dbCluster *rds.DBCluster
+			var err error
+			if opts.Wait {
+				dbCluster, err = cluster.CreateDBClusterAndWait(svc, input, opts.WaitOptions)
+			} else {
+				dbCluster, err = cluster.CreateDBCluster(svc, input)
+			}
+			if err != nil {
+				return err
+			}
+			log.Infof("created cluster %s", *dbCluster.DBClusterIdentifier)
+			return nil
+		},
+		instance: func(input instance.NewDBInstanceInput) error {
+			var dbInstance *rds.DBInstance
+			var err error
+			if opts.Wait {
+				dbInstance, err = instance.CreateDBClusterInstanceAndWait(svc, input, opts.WaitOptions)
+			} else {
+				dbInstance, err = instance.CreateDBClusterInstance(svc, input)
+			}
+			if err != nil {
+				return err
+			}
+			log.Infof("created instance %s", *dbInstance.DBInstanceIdentifier)
+			return nil
+		},
+	})
+
+	return g.Execute(opts.Concurrency)
+}
+
+// DeleteStack deletes every resource in the stack, removing resources before
+// the resources they depend on. A resource is only deleted once the resources
+// depending on it are gone, opts.Wait only decides whether to also wait for
+// the last ones. Resources which do not exist are skipped. A final snapshot of
+// the cluster is taken unless opts.ClusterDeleteOptions says otherwise. When
+// opts.Guard is set nothing is deleted unless every resource passes its checks.
+func DeleteStack(svc rds_api.RDSAPI, stack Stack, opts StackOptions) error {
+	if opts.Guard != nil {
+		err := checkStackDeletion(svc, stack, *opts.Guard)
+		if err != nil {
+			return err
+		}
+	}
+
+	instanceOpts := instance.DeleteOptions{RetainAutomatedBackups: opts.RetainAutomatedBackups}
+	g := newStackGraph(stack, stackOperations{
+		subnetGroup: func(req subnet_group.CreateSubnetGroupRequest) error {
+			return skipNotFound(KindSubnetGroup, req.Name, subnet_group.DeleteDBSubnetGroup(svc, req.Name))
+		},
+		clusterParameterGroup: func(req cluster_parameter_group.CreateRequest) error {
+			return skipNotFound(
+				KindClusterParameterGroup, req.Name,
+				cluster_parameter_group.DeleteDBClusterParameterGroup(svc, req.Name),
+			)
+		},
+		parameterGroup: func(req parameter_group.CreateRequest) error {
+			return skipNotFound(KindParameterGroup, req.Name, parameter_group.DeleteDBParameterGroup(svc, req.Name))
+		},
+		cluster: func(input cluster.NewDBClusterInput) error {
+			err := skipNotFound(
+				KindCluster, input.ClusterId,
+				cluster.DeleteDBCluster(svc, input.ClusterId, opts.ClusterDeleteOptions),
+			)
+			if err == nil && opts.Wait {
+				err = cluster.WaitUntilDBClusterDeleted(svc, input.ClusterId, opts.WaitOptions)
+			}
+			return err
+		},
+		instance: func(input instance.NewDBInstanceInput) error {
+			err := skipNotFound(
+				KindInstance, input.InstanceIdentifier,
+				instance.DeleteDBClusterInstance(svc, input.InstanceIdentifier, instanceOpts),
+			)
+			if err == nil && opts.Wait {
+				err = instance.WaitUntilDBInstanceDeleted(svc, input.InstanceIdentifier, opts.WaitOptions)
+			}
+			return err
+		},
+		settle: func(kind, id string) error {
+			switch kind {
+			case KindCluster:
+				return cluster.WaitUntilDBClusterDeleted(svc, id, opts.WaitOptions)
+			case KindInstance:
+				return instance.WaitUntilDBInstanceDeleted(svc, id, opts.WaitOptions)
+			default:
+				return nil
+			}
+		},
+	})
+
+	return g.ExecuteReverse(opts.Concurrency)
+}
+
+// skipNotFound treats deleting a resource which does not exist as done
+func skipNotFound(kind, id string, err error) error {
+	if errors.Is(err, rds_errors.ErrNotFound) {
+		log.Infof("%s %s: not found, skipping", kind, id)
+		return nil
+	}
+
+	return err
+}
+
+// checkStackDeletion runs the guard's checks on every resource of the stack
+// which exists and asks for confirmation of their deletion. Instances of the
+// stack do not keep its cluster from being deleted.
+func checkStackDeletion(svc rds_api.RDSAPI, stack Stack, g guard.Guard) error {
+	resources := make([]string, 0)
+	check := func(kind, id string, err error) error {
+		if errors.Is(err, rds_errors.ErrNotFound) {
+			log.Infof("%s %s: not found, skipping", kind, id)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resources = append(resources, fmt.Sprintf("%s %s", kind, id))
+		return nil
+	}
+
+	instanceIds := make([]string, 0)
+	for _, i := range stack.Instances {
+		_, err := g.CheckInstance(svc, i.InstanceIdentifier)
+		if err := check(KindInstance, i.InstanceIdentifier, err); err != nil {
+			return err
+		}
+		instanceIds = append(instanceIds, i.InstanceIdentifier)
+	}
+
+	if stack.Cluster.ClusterId != "" {
+		_, err := g.CheckCluster(svc, stack.Cluster.ClusterId, instanceIds)
+		if err := check(KindCluster, stack.Cluster.ClusterId, err); err != nil {
+			return err
+		}
+	}
+
+	if stack.ParameterGroup.Name != "" {
+		err := g.CheckParameterGroup(svc, stack.ParameterGroup.Name)
+		if err := check(KindParameterGroup, stack.ParameterGroup.Name, err); err != nil {
+			return err
+		}
+	}
+
+	if stack.ClusterParameterGroup.Name != "" {
+		err := g.CheckClusterParameterGroup(svc, stack.ClusterParameterGroup.Name)
+		if err := check(KindClusterParameterGroup, stack.ClusterParameterGroup.Name, err); err != nil {
+			return err
+		}
+	}
+
+	if stack.SubnetGroup.Name != "" {
+		err := g.CheckSubnetGroup(svc, stack.SubnetGroup.Name)
+		if err := check(KindSubnetGroup, stack.SubnetGroup.Name, err); err != nil {
+			return err
+		}
+	}
+
+	return g.ConfirmDelete(resources)
+}
@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cvgw/rds_provider/pkg/provider/cluster"
+	"github.com/cvgw/rds_provider/pkg/provider/fake_rds"
+	"github.com/cvgw/rds_provider/pkg/provider/instance"
+	"github.com/cvgw/rds_provider/pkg/provider/parameter_group"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/snapshot"
+)
+
+func TestDeleteStack(t *testing.T) {
+	for _, w := range []bool{false, true} {
+		name := "without waiting"
+		if w {
+			name = "waiting"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			svc := fake_rds.New()
+			stack := loadSampleStack(t)
+			opts := testStackOptions(w)
+
+			_, err := ReconcileStack(svc, stack, testStackOptions(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts.ClusterDeleteOptions = cluster.DeleteOptions{FinalSnapshotId: "test-cluster-final"}
+			err = DeleteStack(svc, stack, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if w {
+				_, err = cluster.FindDBCluster(svc, stack.Cluster.ClusterId)
+				if !errors.Is(err, rds_errors.ErrNotFound) {
+					t.Errorf("cluster: got %v, want not found", err)
+				}
+			}
+			for _, i := range stack.Instances {
+				_, err = instance.FindDBClusterInstance(svc, i.InstanceIdentifier)
+				if !errors.Is(err, rds_errors.ErrNotFound) {
+					t.Errorf("instance %s: got %v, want not found", i.InstanceIdentifier, err)
+				}
+			}
+			_, err = snapshot.FindDBClusterSnapshot(svc, "test-cluster-final")
+			if err != nil {
+				t.Errorf("final snapshot: %v", err)
+			}
+
+			// resources which are gone are skipped
+			err = DeleteStack(svc, stack, testStackOptions(true))
+			if err != nil {
+				t.Errorf("second delete: %v", err)
+			}
+		})
+	}
+}
+
+// writeStack writes the sample stack with the given parameter group
+// parameters, in YAML, and returns its path
+func writeStack(t *testing.T, parameters string) (string, func()) {
+	t.Helper()
+
+	sample, err := ioutil.ReadFile(sampleStack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := strings.Replace(
+		string(sample),
+		"  description: a test instance parameter group\n",
+		"  description: a test instance parameter group\n  parameters:\n"+parameters,
+		1,
+	)
+
+	f, err := ioutil.TempFile("", "stack-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	_, err = f.WriteString(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name(), func() { os.Remove(f.Name()) }
+}
+
+func TestLoadStackParameters(t *testing.T) {
+	cases := []struct {
+		name       string
+		parameters string
+		wantErr    string
+	}{
+		{
+			name: "valid",
+			parameters: "    - {name: max_allowed_packet, value: 134217728, value_type: integer, apply: immediate}\n" +
+				"    - {name: time_zone, value: UTC, value_type: string, apply: pending-reboot}\n",
+		},
+		{
+			name:       "number as a string parameter",
+			parameters: "    - {name: max_connections, value: 1000, value_type: string, apply: immediate}\n",
+		},
+		{
+			name:       "missing value type",
+			parameters: "    - {name: time_zone, value: UTC, apply: immediate}\n",
+			wantErr:    "parameter time_zone: value_type is required",
+		},
+		{
+			name:       "unknown value type",
+			parameters: "    - {name: time_zone, value: UTC, value_type: text, apply: immediate}\n",
+			wantErr:    `parameter time_zone: unknown value_type "text"`,
+		},
+		{
+			name:       "value not of its type",
+			parameters: "    - {name: max_connections, value: many, value_type: integer, apply: immediate}\n",
+			wantErr:    "parameter max_connections: many is not a valid integer",
+		},
+		{
+			name:       "missing apply",
+			parameters: "    - {name: time_zone, value: UTC, value_type: string}\n",
+			wantErr:    `parameter time_zone: invalid apply ""`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path, cleanup := writeStack(t, c.parameters)
+			defer cleanup()
+
+			_, err := LoadStack(path)
+			if c.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("got %v, want %s", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestReconcileStackParameters(t *testing.T) {
+	var parameters strings.Builder
+	for i := 0; i < 25; i++ {
+		fmt.Fprintf(&parameters, "    - {name: p%d, value: %d, value_type: integer, apply: immediate}\n", i, 1<<27+i)
+	}
+	parameters.WriteString("    - {name: read_only, value: true, value_type: boolean, apply: immediate}\n")
+	parameters.WriteString("    - {name: ratio, value: 0.5, value_type: float, apply: immediate}\n")
+	path, cleanup := writeStack(t, parameters.String())
+	defer cleanup()
+
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := fake_rds.New()
+	opts := testStackOptions(true)
+
+	results, err := ReconcileStack(svc, stack, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkActions(t, "first run", results, sampleStackActions(ActionCreate))
+
+	current, err := parameter_group.FindDBParameters(svc, stack.ParameterGroup.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(current) != 27 {
+		t.Errorf("got %d parameters, want 27", len(current))
+	}
+	if v := parameterValue(current, "p0"); v != "134217728" {
+		t.Errorf("p0: got %s, want 134217728", v)
+	}
+	if v := parameterValue(current, "read_only"); v != "1" {
+		t.Errorf("read_only: got %s, want 1", v)
+	}
+
+	results, err = ReconcileStack(svc, stack, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkActions(t, "second run", results, sampleStackActions(ActionNone))
+}
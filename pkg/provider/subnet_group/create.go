@@ -2,8 +2,9 @@ package subnet_group
 
 import (
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -13,38 +14,14 @@ type CreateSubnetGroupRequest struct {
 	SubnetIds   []string `json:"subnet_ids,omitempty"`
 }
 
-func CreateSubnetGroup(svc *rds.RDS, req CreateSubnetGroupRequest) (*rds.DBSubnetGroup, error) {
+func CreateSubnetGroup(svc rds_api.RDSAPI, req CreateSubnetGroupRequest) (*rds.DBSubnetGroup, error) {
 	groupInput := NewCreateDBSubnetGroupInput(req)
 
 	groupOutput, err := svc.CreateDBSubnetGroup(groupInput)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBSubnetGroupAlreadyExistsFault:
-				log.Warn(rds.ErrCodeDBSubnetGroupAlreadyExistsFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeDBSubnetGroupQuotaExceededFault:
-				log.Warn(rds.ErrCodeDBSubnetGroupQuotaExceededFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeDBSubnetQuotaExceededFault:
-				log.Warn(rds.ErrCodeDBSubnetQuotaExceededFault, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeDBSubnetGroupDoesNotCoverEnoughAZs:
-				log.Warn(rds.ErrCodeDBSubnetGroupDoesNotCoverEnoughAZs, aerr.Error())
-				return nil, aerr
-			case rds.ErrCodeInvalidSubnet:
-				log.Warn(rds.ErrCodeInvalidSubnet, aerr.Error())
-				return nil, aerr
-			default:
-				log.Warn(aerr)
-				return nil, aerr
-			}
-		} else {
-			// Print the error, cast err to awserr.Error to get the Code and
-			// Message from an error.
-			log.Warn(err)
-			return nil, aerr
-		}
+		err = rds_errors.Wrap(err, rds_errors.KindSubnetGroup, req.Name)
+		log.Warn(err)
+		return nil, err
 	}
 
 	return groupOutput.DBSubnetGroup, nil
@@ -0,0 +1,53 @@
+package subnet_group
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	"github.com/cvgw/rds_provider/pkg/provider/tags"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListRequest narrows the subnet groups returned by ListDBSubnetGroups. RDS
+// does not filter subnet groups, so only tags are supported.
+type ListRequest struct {
+	// Tags the subnet groups must carry (optional)
+	Tags tags.Selector
+}
+
+// ListDBSubnetGroups returns the subnet groups in the region matching req,
+// following every page of results
+func ListDBSubnetGroups(svc rds_api.RDSAPI, req ListRequest) ([]*rds.DBSubnetGroup, error) {
+	input := &rds.DescribeDBSubnetGroupsInput{}
+
+	groups := make([]*rds.DBSubnetGroup, 0)
+	for {
+		result, err := svc.DescribeDBSubnetGroups(input)
+		if err != nil {
+			err = rds_errors.Wrap(err, rds_errors.KindSubnetGroup, "")
+			log.Warn(err)
+			return nil, err
+		}
+
+		for _, group := range result.DBSubnetGroups {
+			ok, err := req.Tags.Select(
+				svc, rds_errors.KindSubnetGroup,
+				aws.StringValue(group.DBSubnetGroupName), aws.StringValue(group.DBSubnetGroupArn),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				groups = append(groups, group)
+			}
+		}
+
+		if aws.StringValue(result.Marker) == "" {
+			break
+		}
+		input.Marker = result.Marker
+	}
+
+	return groups, nil
+}
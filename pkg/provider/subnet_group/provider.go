@@ -4,8 +4,9 @@ import (
 	"errors"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -14,10 +15,10 @@ var (
 )
 
 func init() {
-	SubnetGroupNotFoundErr = errors.New("subnet group not found")
+	SubnetGroupNotFoundErr = rds_errors.New(rds_errors.NotFound, rds_errors.KindSubnetGroup, "")
 }
 
-func FindDBSubnetGroup(svc *rds.RDS, groupName string) (*rds.DBSubnetGroup, error) {
+func FindDBSubnetGroup(svc rds_api.RDSAPI, groupName string) (*rds.DBSubnetGroup, error) {
 	subnetGroupName := aws.String(groupName)
 	descGroupsInput := &rds.DescribeDBSubnetGroupsInput{
 		DBSubnetGroupName: subnetGroupName,
@@ -25,50 +26,28 @@ func FindDBSubnetGroup(svc *rds.RDS, groupName string) (*rds.DBSubnetGroup, erro
 
 	descGroupsOutput, err := svc.DescribeDBSubnetGroups(descGroupsInput)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeDBSubnetGroupNotFoundFault:
-				log.Debug(rds.ErrCodeDBSubnetGroupNotFoundFault, aerr.Error())
-				return nil, SubnetGroupNotFoundErr
-			default:
-				log.Warn(aerr)
-				return nil, aerr
-			}
+		err = rds_errors.Wrap(err, rds_errors.KindSubnetGroup, groupName)
+		if errors.Is(err, SubnetGroupNotFoundErr) {
+			log.Info(err)
 		} else {
 			log.Warn(err)
-			return nil, err
 		}
+		return nil, err
 	}
 
 	return descGroupsOutput.DBSubnetGroups[0], nil
 }
 
-func DeleteDBSubnetGroup(svc *rds.RDS, groupName string) error {
+func DeleteDBSubnetGroup(svc rds_api.RDSAPI, groupName string) error {
 	input := &rds.DeleteDBSubnetGroupInput{
 		DBSubnetGroupName: aws.String(groupName),
 	}
 
 	result, err := svc.DeleteDBSubnetGroup(input)
 	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case rds.ErrCodeInvalidDBSubnetGroupStateFault:
-				log.Warn(rds.ErrCodeInvalidDBSubnetGroupStateFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeInvalidDBSubnetStateFault:
-				log.Warn(rds.ErrCodeInvalidDBSubnetStateFault, aerr.Error())
-				return aerr
-			case rds.ErrCodeDBSubnetGroupNotFoundFault:
-				log.Warn(rds.ErrCodeDBSubnetGroupNotFoundFault, aerr.Error())
-				return aerr
-			default:
-				log.Warn(aerr.Error())
-				return aerr
-			}
-		} else {
-			log.Warn(err.Error())
-			return err
-		}
+		err = rds_errors.Wrap(err, rds_errors.KindSubnetGroup, groupName)
+		log.Warn(err)
+		return err
 	}
 	log.Debug(result)
 
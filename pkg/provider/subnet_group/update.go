@@ -0,0 +1,71 @@
+package subnet_group
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// NeedsUpdate reports whether the subnet group differs from the request
+func NeedsUpdate(group *rds.DBSubnetGroup, req CreateSubnetGroupRequest) bool {
+	if aws.StringValue(group.DBSubnetGroupDescription) != req.Description {
+		return true
+	}
+
+	return !SameSubnets(group, req.SubnetIds)
+}
+
+// SameSubnets reports whether the subnet group contains exactly the given subnets
+func SameSubnets(group *rds.DBSubnetGroup, subnetIds []string) bool {
+	current := make([]string, 0)
+	for _, s := range group.Subnets {
+		current = append(current, aws.StringValue(s.SubnetIdentifier))
+	}
+
+	desired := append([]string{}, subnetIds...)
+	if len(current) != len(desired) {
+		return false
+	}
+
+	sort.Strings(current)
+	sort.Strings(desired)
+	for i := range current {
+		if current[i] != desired[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func UpdateSubnetGroup(svc rds_api.RDSAPI, req CreateSubnetGroupRequest) (*rds.DBSubnetGroup, error) {
+	input := NewModifyDBSubnetGroupInput(req)
+
+	result, err := svc.ModifyDBSubnetGroup(input)
+	if err != nil {
+		err = rds_errors.Wrap(err, rds_errors.KindSubnetGroup, req.Name)
+		log.Warn(err)
+		return nil, err
+	}
+
+	return result.DBSubnetGroup, nil
+}
+
+func NewModifyDBSubnetGroupInput(req CreateSubnetGroupRequest) *rds.ModifyDBSubnetGroupInput {
+	sIds := make([]*string, 0)
+	for _, i := range req.SubnetIds {
+		sIds = append(sIds, aws.String(i))
+	}
+
+	input := &rds.ModifyDBSubnetGroupInput{
+		DBSubnetGroupName:        aws.String(req.Name),
+		DBSubnetGroupDescription: aws.String(req.Description),
+		SubnetIds:                sIds,
+	}
+
+	return input
+}
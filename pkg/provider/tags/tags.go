@@ -0,0 +1,88 @@
+// Package tags reads the tags of RDS resources and selects resources by tag.
+// RDS has no server side tag filter for Describe calls, so the tags of each
+// resource are listed separately.
+package tags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_api"
+	"github.com/cvgw/rds_provider/pkg/provider/rds_errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Selector matches resources carrying every one of its tags. A tag with a nil
+// Value matches any value of its key.
+type Selector []*rds.Tag
+
+// ParseSelector parses terms of the form key=value, or key to match any value
+func ParseSelector(terms []string) (Selector, error) {
+	s := make(Selector, 0)
+	for _, term := range terms {
+		parts := strings.SplitN(term, "=", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("invalid tag selector %q, expected key=value or key", term)
+		}
+
+		tag := &rds.Tag{Key: aws.String(parts[0])}
+		if len(parts) == 2 {
+			tag.Value = aws.String(parts[1])
+		}
+		s = append(s, tag)
+	}
+
+	return s, nil
+}
+
+// Matches reports whether tags satisfy every term of s
+func (s Selector) Matches(tags []*rds.Tag) bool {
+	for _, want := range s {
+		found := false
+		for _, tag := range tags {
+			if aws.StringValue(tag.Key) != aws.StringValue(want.Key) {
+				continue
+			}
+			if want.Value == nil || aws.StringValue(tag.Value) == aws.StringValue(want.Value) {
+				found = true
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Select reports whether the resource with the given ARN matches s. Tags are
+// only listed when s is not empty. kind and id describe the resource in errors.
+func (s Selector) Select(svc rds_api.RDSAPI, kind, id, arn string) (bool, error) {
+	if len(s) == 0 {
+		return true, nil
+	}
+
+	tags, err := ListTags(svc, kind, id, arn)
+	if err != nil {
+		return false, err
+	}
+
+	return s.Matches(tags), nil
+}
+
+// ListTags returns the tags of the resource with the given ARN
+func ListTags(svc rds_api.RDSAPI, kind, id, arn string) ([]*rds.Tag, error) {
+	input := &rds.ListTagsForResourceInput{ResourceName: aws.String(arn)}
+
+	result, err := svc.ListTagsForResource(input)
+	if err != nil {
+		err = rds_errors.Wrap(err, kind, id)
+		log.Warn(err)
+		return nil, err
+	}
+
+	return result.TagList, nil
+}
@@ -0,0 +1,121 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultTimeout = 60 * time.Minute
+	DefaultDelay   = 30 * time.Second
+	// DefaultStartChecks is the number of status checks made for a
+	// modification to start before it is taken to have been applied without
+	// a status change
+	DefaultStartChecks = 3
+)
+
+// Options controls how an operation blocks until a resource reaches its target
+// status
+type Options struct {
+	// Maximum length of time to wait (optional)
+	Timeout time.Duration
+	// Length of time between status checks (optional)
+	Delay time.Duration
+	// Called with the resource identifier and its status after every status
+	// check (optional)
+	Progress func(id, status string)
+}
+
+func (o Options) timeout() time.Duration {
+	if o.Timeout > 0 {
+		return o.Timeout
+	}
+	return DefaultTimeout
+}
+
+func (o Options) delay() time.Duration {
+	if o.Delay > 0 {
+		return o.Delay
+	}
+	return DefaultDelay
+}
+
+// Context returns a context which expires once the wait timeout has elapsed
+func (o Options) Context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), o.timeout())
+}
+
+func (o Options) progress() func(id, status string) {
+	if o.Progress != nil {
+		return o.Progress
+	}
+
+	return func(id, status string) {
+		log.Infof("waiting on %s: %s", id, status)
+	}
+}
+
+// Poll runs check every Delay until it reports the resource identified by id
+// done, fails, or the timeout has elapsed. check returns the status of the
+// resource, which is reported as progress. When maxChecks is above 0 Poll
+// gives up after that many checks and reports false.
+func (o Options) Poll(id string, maxChecks int, check func() (string, bool, error)) (bool, error) {
+	ctx, cancel := o.Context()
+	defer cancel()
+
+	progress := o.progress()
+	for checks := 1; ; checks++ {
+		status, done, err := check()
+		if err != nil {
+			return false, err
+		}
+		progress(id, status)
+
+		if done {
+			return true, nil
+		}
+		if maxChecks > 0 && checks >= maxChecks {
+			return false, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("waiting on %s, last seen %s: %w", id, status, ctx.Err())
+		case <-time.After(o.delay()):
+		}
+	}
+}
+
+// WaiterOptions converts the options into request.WaiterOptions. statusPath is
+// the path of the status field in the output of the describe call the waiter
+// makes and is used to report progress for the resource identified by id.
+func (o Options) WaiterOptions(id, statusPath string) []request.WaiterOption {
+	delay := o.delay()
+	progress := o.progress()
+
+	return []request.WaiterOption{
+		request.WithWaiterDelay(request.ConstantWaiterDelay(delay)),
+		request.WithWaiterMaxAttempts(int(o.timeout()/delay) + 1),
+		request.WithWaiterRequestOptions(func(r *request.Request) {
+			r.Handlers.Complete.PushBack(func(r *request.Request) {
+				if r.Error != nil {
+					return
+				}
+
+				values, err := awsutil.ValuesAtPath(r.Data, statusPath)
+				if err != nil || len(values) == 0 {
+					return
+				}
+
+				if status, ok := values[0].(*string); ok && status != nil {
+					progress(id, *status)
+				}
+			})
+		}),
+	}
+}